@@ -1,10 +1,10 @@
 module github.com/f4ah6o/direct-go-sdk/daab-go-examples
 
-go 1.25
+go 1.22.0
 
 require (
-	github.com/f4ah6o/direct-go-sdk/daab-go v0.0.0
-	github.com/f4ah6o/direct-go-sdk/direct-go v0.0.0
+	github.com/f4ah6o/direct-go-sdk/daab-go v0.0.0-20261018135439-a0c924b41fff
+	github.com/f4ah6o/direct-go-sdk/direct-go v0.0.0-20261018135017-9669f4b13f00
 )
 
 require (
@@ -12,9 +12,5 @@ require (
 	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	golang.org/x/net v0.17.0 // indirect
-)
-
-replace (
-	github.com/f4ah6o/direct-go-sdk/daab-go => ../daab-go
-	github.com/f4ah6o/direct-go-sdk/direct-go => ../direct-go
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -3,9 +3,9 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
 	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
@@ -36,21 +36,49 @@ func main() {
 		bot.WithName("n8nproxy"),
 	)
 
-	// Create webhook client
-	webhookClient := webhook.NewClient(n8nWebhookURL, "n8nproxy")
+	// Create webhook client and the executor for n8n's actions. Signing and
+	// the dead-letter queue are set with N8N_WEBHOOK_SECRET and
+	// N8N_WEBHOOK_DEAD_LETTER_DIR.
+	cfg := bot.DefaultConfig()
+	if err := cfg.ApplyEnv(); err != nil {
+		log.Fatalf("Invalid environment: %v", err)
+	}
+	webhookClient := webhook.NewClientFromConfig(cfg.Webhook, "n8nproxy")
+	executor := webhook.NewExecutor(robot)
+
+	// Add the talk, domain and sender to the payloads. N8N_ENRICH selects
+	// the fields, e.g. "user.displayName,user.email,talk.name".
+	var fields []string
+	if v := os.Getenv("N8N_ENRICH"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+	enricher, err := webhook.NewEnricher(robot, fields...)
+	if err != nil {
+		log.Fatalf("Invalid N8N_ENRICH: %v", err)
+	}
 
 	// Listen to all messages and forward to n8n
 	robot.Hear(".*", func(ctx context.Context, res bot.Response) {
-		handleMessage(ctx, res, webhookClient)
+		handleMessage(ctx, res, webhookClient, executor, enricher)
 	})
 
+	// Forward talk, note and other events to n8n as well
+	var events []string
+	if v := os.Getenv("N8N_EVENTS"); v != "" {
+		events = strings.Split(v, ",")
+	}
+	forwarder := webhook.NewForwarder(webhookClient, webhook.WithEvents(events...), webhook.WithActions(), webhook.WithEnrichment(fields...))
+	if err := robot.UsePlugins(forwarder); err != nil {
+		log.Fatalf("Failed to use the webhook plugin: %v", err)
+	}
+
 	// Run the bot
 	if err := robot.Run(context.Background()); err != nil {
 		log.Fatalf("Bot error: %v", err)
 	}
 }
 
-func handleMessage(ctx context.Context, res bot.Response, client *webhook.Client) {
+func handleMessage(ctx context.Context, res bot.Response, client *webhook.Client, executor *webhook.Executor, enricher *webhook.Enricher) {
 	msg := res.Message
 
 	// Convert to webhook payload
@@ -66,88 +94,30 @@ func handleMessage(ctx context.Context, res bot.Response, client *webhook.Client
 	}
 
 	payload := webhook.NewPayload("message_created", client.BotName, msgData)
+	enricher.Enrich(ctx, payload)
 
 	log.Printf("[N8N PROXY] Forwarding message: type=%s user=%s talk=%s",
 		msgData.TypeName, msgData.UserID, msgData.TalkID)
 
 	// Send to n8n
-	resp, err := client.Send(payload)
+	resp, err := client.SendContext(ctx, payload)
 	if err != nil {
 		log.Printf("[N8N PROXY] Error sending to n8n: %v", err)
 		return
 	}
 
-	// Validate response
-	if errCode := resp.Validate(); errCode != webhook.ErrorCodeOK {
-		log.Printf("[N8N PROXY] Invalid response from n8n: %s", errCode)
-		return
+	// Execute the actions returned by n8n
+	results := executor.Execute(ctx, msg.TalkID, resp)
+	for _, result := range results {
+		if !result.OK {
+			log.Printf("[N8N PROXY] Action %s failed: %s (%s)", result.Action, result.ErrorCode, result.Error)
+		}
 	}
 
-	// Execute action from n8n
-	if err := executeAction(ctx, res, resp); err != nil {
-		log.Printf("[N8N PROXY] Error executing action: %v", err)
-	}
-}
-
-func executeAction(ctx context.Context, res bot.Response, resp *webhook.WebhookResponse) error {
-	log.Printf("[N8N PROXY] Executing action: %s", resp.Action)
-
-	switch resp.Action {
-	case "none":
-		// Do nothing
-		return nil
-
-	case "reply":
-		return res.Send(resp.Text)
-
-	case "send":
-		return res.Robot.SendText(resp.RoomID, resp.Text)
-
-	case "send_select":
-		// TODO: Implement SendSelect in bot package with Question and Options
-		log.Printf("[N8N PROXY] send_select not fully implemented yet: question=%s options=%v",
-			resp.Question, resp.Options)
-		return nil
-
-	case "send_yesno":
-		// TODO: Implement SendYesNo in bot package
-		log.Printf("[N8N PROXY] send_yesno not fully implemented yet: question=%s", resp.Question)
-		return nil
-
-	case "send_task":
-		// TODO: Implement SendTask in bot package
-		log.Printf("[N8N PROXY] send_task not fully implemented yet: title=%s", resp.Title)
-		return nil
-
-	case "reply_select":
-		// TODO: Implement ReplySelect in bot package
-		log.Printf("[N8N PROXY] reply_select not fully implemented yet: inReplyTo=%s response=%v",
-			resp.InReplyTo, resp.Response)
-		return nil
-
-	case "reply_yesno":
-		// TODO: Implement ReplyYesNo in bot package
-		log.Printf("[N8N PROXY] reply_yesno not fully implemented yet: inReplyTo=%s response=%v",
-			resp.InReplyTo, resp.ResponseBool)
-		return nil
-
-	case "reply_task":
-		// TODO: Implement ReplyTask in bot package
-		log.Printf("[N8N PROXY] reply_task not fully implemented yet: inReplyTo=%s done=%v",
-			resp.InReplyTo, resp.Done)
-		return nil
-
-	case "close_select":
-		// TODO: Implement CloseSelect in bot package
-		log.Printf("[N8N PROXY] close_select not fully implemented yet: messageId=%s", resp.MessageID)
-		return nil
-
-	case "close_yesno":
-		// TODO: Implement CloseYesNo in bot package
-		log.Printf("[N8N PROXY] close_yesno not fully implemented yet: messageId=%s", resp.MessageID)
-		return nil
-
-	default:
-		return fmt.Errorf("unknown action: %s", resp.Action)
+	// Report the results back if n8n asked for them
+	if resp.ReportResults {
+		if _, err := client.SendContext(ctx, webhook.NewResultsPayload(client.BotName, results)); err != nil {
+			log.Printf("[N8N PROXY] Error reporting results to n8n: %v", err)
+		}
 	}
 }
@@ -4,13 +4,13 @@ import (
 	"context"
 	cryptorand "crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
@@ -27,30 +27,6 @@ var menuOptions = []string{
 	"ミラサポplus事例表示",
 }
 
-type menuTracker struct {
-	mu             sync.Mutex
-	lastQuestionID string
-}
-
-func (m *menuTracker) set(id string) {
-	m.mu.Lock()
-	m.lastQuestionID = id
-	m.mu.Unlock()
-}
-
-func (m *menuTracker) matches(id string) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	return id != "" && id == m.lastQuestionID
-}
-
-type selectContent struct {
-	Question  string
-	Options   []string
-	Response  *int
-	InReplyTo string
-}
-
 type caseStudy struct {
 	ID       string `json:"id"`
 	Title    string `json:"title"`
@@ -78,16 +54,11 @@ func main() {
 	robot := bot.New(
 		bot.WithName("selectbot"),
 	)
-	tracker := &menuTracker{}
 
-	// Send the select menu when asked (no @mention required).
+	// Show the select menu when asked (no @mention required) and keep
+	// showing it to the same user until they stop answering.
 	robot.Hear("(menu|メニュー)$", func(ctx context.Context, res bot.Response) {
-		sendMenu(res, tracker)
-	})
-
-	// Echo selection results and trigger features.
-	robot.Hear(".*", func(ctx context.Context, res bot.Response) {
-		handleSelectAction(ctx, res, tracker)
+		runMenu(ctx, res)
 	})
 
 	if err := robot.Run(context.Background()); err != nil {
@@ -95,178 +66,32 @@ func main() {
 	}
 }
 
-// sendMenu sends the select menu and updates the tracker.
-func sendMenu(res bot.Response, tracker *menuTracker) {
-	id, err := res.SendSelect(menuQuestion, menuOptions)
-	if err != nil {
-		log.Printf("Error sending select stamp: %v", err)
-		_ = res.Send("セレクトスタンプの送信に失敗しました。トークIDや権限を確認してください。")
-		return
-	}
-	tracker.set(id)
-}
-
-func handleSelectAction(ctx context.Context, res bot.Response, tracker *menuTracker) {
-	// Debug: show message type
-	log.Printf("[SELECT DEBUG] Message type: %d, Text: %q", res.Message.Type, res.Message.Text)
-
-	content, err := extractSelectContent(res.Message)
-	if err != nil {
-		log.Printf("[SELECT DEBUG] extractSelectContent error: %v", err)
-		return
-	}
-	if content == nil {
-		log.Printf("[SELECT DEBUG] content is nil")
-		return
-	}
-	if content.Response == nil {
-		log.Printf("[SELECT DEBUG] content.Response is nil, Question=%q, Options=%v, InReplyTo=%q", content.Question, content.Options, content.InReplyTo)
-		return
-	}
-
-	log.Printf("[SELECT DEBUG] Got response: idx=%d, Question=%q, Options=%v, InReplyTo=%q", *content.Response, content.Question, content.Options, content.InReplyTo)
-
-	// Ensure this is the menu we sent.
-	if content.Question != menuQuestion && !tracker.matches(content.InReplyTo) {
-		log.Printf("[SELECT DEBUG] Skipping: Question mismatch and tracker doesn't match")
-		return
-	}
-
-	idx := *content.Response
-	choice := optionAt(content.Options, idx)
-	switch choice {
-	case menuOptions[0]:
-		handleUUIDFortune(ctx, res)
-	case menuOptions[1]:
-		handleMirasapoCase(ctx, res)
-	default:
-		_ = res.Send(fmt.Sprintf("選択肢 %d を受信しました。", idx))
-	}
-
-	// Resend the select menu after handling the response
-	sendMenu(res, tracker)
-}
-
-func optionAt(options []string, idx int) string {
-	if idx >= 0 && idx < len(options) {
-		return options[idx]
-	}
-	return ""
-}
-
-func extractSelectContent(msg direct.ReceivedMessage) (*selectContent, error) {
-	// Wire types: 502 = select stamp, 503 = select reply
-	msgType := int(msg.Type)
-	if msgType != direct.WireTypeSelect && msgType != direct.WireTypeSelectReply {
-		return nil, fmt.Errorf("not a select message (type=%d)", msgType)
-	}
-
-	contentMap, err := pullContentMap(msg)
-	if err != nil || contentMap == nil {
-		return nil, err
-	}
-
-	// Debug: show all keys in contentMap
-	log.Printf("[SELECT DEBUG] contentMap keys: %v", getMapKeys(contentMap))
-	log.Printf("[SELECT DEBUG] contentMap full: %+v", contentMap)
-
-	sc := &selectContent{
-		Question: stringValue(contentMap["question"]),
-		Options:  stringSlice(contentMap["options"]),
-	}
-
-	if resp, ok := intValue(contentMap["response"]); ok {
-		sc.Response = &resp
-	}
-	if inReply, ok := contentMap["in_reply_to"]; ok {
-		sc.InReplyTo = fmt.Sprintf("%v", inReply)
-	}
-
-	return sc, nil
-}
-
-func getMapKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
-
-func pullContentMap(msg direct.ReceivedMessage) (map[string]interface{}, error) {
-	if content, ok := msg.Content.(map[string]interface{}); ok {
-		return content, nil
-	}
-
-	if len(msg.Raw) == 0 {
-		return nil, nil
-	}
-
-	var raw map[string]interface{}
-	if err := json.Unmarshal(msg.Raw, &raw); err != nil {
-		return nil, err
-	}
-
-	if content, ok := raw["content"].(map[string]interface{}); ok {
-		return content, nil
-	}
-
-	return nil, nil
-}
-
-func stringSlice(v interface{}) []string {
-	raw, ok := v.([]interface{})
-	if !ok {
-		return nil
-	}
-	out := make([]string, 0, len(raw))
-	for _, item := range raw {
-		out = append(out, stringValue(item))
-	}
-	return out
-}
-
-func stringValue(v interface{}) string {
-	if v == nil {
-		return ""
-	}
-	return fmt.Sprintf("%v", v)
-}
+// runMenu asks the sender to choose from the menu and runs the chosen
+// feature, repeating until the menu is cancelled or times out.
+func runMenu(ctx context.Context, res bot.Response) {
+	conv := res.Conversation(bot.WithConversationTimeout(10 * time.Minute))
+	for {
+		choice, err := conv.AskSelect(ctx, menuQuestion, menuOptions)
+		switch {
+		case errors.Is(err, bot.ErrConversationTimeout), errors.Is(err, bot.ErrConversationCancelled):
+			_ = res.Send("メニューを終了しました。")
+			return
+		case errors.Is(err, bot.ErrConversationBusy):
+			return
+		case err != nil:
+			log.Printf("Error sending select stamp: %v", err)
+			_ = res.Send("セレクトスタンプの送信に失敗しました。トークIDや権限を確認してください。")
+			return
+		}
 
-func intValue(v interface{}) (int, bool) {
-	switch n := v.(type) {
-	case int:
-		return n, true
-	case int8:
-		return int(n), true
-	case int16:
-		return int(n), true
-	case int32:
-		return int(n), true
-	case int64:
-		return int(n), true
-	case uint:
-		return int(n), true
-	case uint8:
-		return int(n), true
-	case uint16:
-		return int(n), true
-	case uint32:
-		return int(n), true
-	case uint64:
-		return int(n), true
-	case float32:
-		return int(n), true
-	case float64:
-		return int(n), true
-	case json.Number:
-		i, err := n.Int64()
-		if err != nil {
-			return 0, false
+		switch choice {
+		case 0:
+			handleUUIDFortune(ctx, res)
+		case 1:
+			handleMirasapoCase(ctx, res)
+		default:
+			_ = res.Send(fmt.Sprintf("選択肢 %d を受信しました。", choice))
 		}
-		return int(i), true
-	default:
-		return 0, false
 	}
 }
 
@@ -10,9 +10,15 @@ import (
 	"os/signal"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/metrics"
 )
 
 // Errors returned by bot operations.
@@ -22,6 +28,9 @@ var (
 
 	// ErrNoToken is returned when no access token is available.
 	ErrNoToken = errors.New("daab: no access token available")
+
+	// ErrNotFound is returned when a looked up user or talk does not exist.
+	ErrNotFound = errors.New("daab: not found")
 )
 
 // EventType represents robot lifecycle events.
@@ -43,14 +52,31 @@ type Handler func(ctx context.Context, res Response)
 type Listener struct {
 	Pattern  *regexp.Regexp
 	Handler  Handler
-	IsDirect bool // If true, only responds when directly addressed
+	IsDirect bool     // If true, only responds when directly addressed
+	Filters  []Filter // All must accept the message for the handler to run
+	Name     string   // Identifies the listener in metrics; see Named
+
+	// match replaces Pattern matching for typed listeners such as OnStamp.
+	match func(msg direct.ReceivedMessage) ([]string, bool)
+}
+
+// matches reports whether the listener matches msg and returns the match groups.
+func (l *Listener) matches(msg direct.ReceivedMessage) ([]string, bool) {
+	if l.match != nil {
+		return l.match(msg)
+	}
+	matches := l.Pattern.FindStringSubmatch(msg.Text)
+	return matches, matches != nil
 }
 
 // Response provides context for responding to a message.
 type Response struct {
-	Message direct.ReceivedMessage
-	Match   []string
-	Robot   *Robot
+	Message  direct.ReceivedMessage
+	Match    []string
+	Robot    *Robot
+	Listener *Listener // Matched listener; nil in receive middleware
+
+	ctx context.Context
 }
 
 // Text returns the text of the message.
@@ -68,39 +94,106 @@ func (r Response) UserID() string {
 	return r.Message.UserID
 }
 
+// Context returns the context the handler was called with. It carries the
+// handler's tracing span, so that messages sent through the Response are
+// traced as its children.
+func (r Response) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
 // Send sends a text message to the same room.
 func (r Response) Send(text string) error {
-	return r.Robot.client.SendText(r.Message.TalkID, text)
+	return r.Robot.sendText(r.Context(), r.Message.TalkID, text)
 }
 
 // SendSelect sends a select action stamp to the same room and returns the created message ID.
 func (r Response) SendSelect(question string, options []string) (string, error) {
-	// Use map format instead of struct to ensure proper msgpack serialization
-	content := map[string]interface{}{
-		"question":     question,
-		"options":      options,
-		"listing":      true,
-		"closing_type": 1, // default to "all must answer" per daab spec
-	}
-	// Use wire type (502) not internal enum value (15) for action stamps
-	return r.Robot.sendActionMessage(r.Message.TalkID, direct.WireTypeSelect, content)
+	return r.Robot.sendSelect(r.Context(), r.Message.TalkID, question, options)
+}
+
+// SendYesNo sends a yes/no action stamp to the same room and returns the created message ID.
+func (r Response) SendYesNo(question string) (string, error) {
+	return r.Robot.sendYesNo(r.Context(), r.Message.TalkID, question)
 }
 
 // Reply sends a reply mentioning the user.
 func (r Response) Reply(text string) error {
-	return r.Robot.client.SendText(r.Message.TalkID, fmt.Sprintf("@%s %s", r.Message.UserID, text))
+	return r.Robot.sendText(r.Context(), r.Message.TalkID, r.SenderMention()+" "+text)
+}
+
+// SenderMention returns a mention of the message sender.
+// The mention uses the sender's display name from the robot's Directory;
+// if the user cannot be looked up, a plain "@<userID>" is returned instead.
+func (r Response) SenderMention() string {
+	user, err := r.Robot.LookupUser(r.Context(), r.Message.DomainID, r.Message.UserID)
+	if err != nil {
+		log.Printf("Warning: could not look up user %s: %v", r.Message.UserID, err)
+		return "@" + r.Message.UserID
+	}
+	if userName(user) == "" {
+		return "@" + r.Message.UserID
+	}
+	return direct.MentionMarkup(r.Message.UserID, userName(user))
+}
+
+// Mentions returns the mention entities in the message text.
+func (r Response) Mentions() []direct.Mention {
+	return r.Message.Mentions()
+}
+
+// MentionsRobot reports whether the message mentions the robot's account.
+func (r Response) MentionsRobot() bool {
+	self := r.Robot.SelfID()
+	if self == "" {
+		return false
+	}
+	for _, m := range r.Mentions() {
+		if m.UserID == self {
+			return true
+		}
+	}
+	return false
 }
 
 // Robot is the main bot instance.
 type Robot struct {
-	Name          string
-	Token         string // Access token (optional, overrides env)
-	client        *direct.Client
-	listeners     []*Listener
-	auth          *direct.Auth
-	endpoint      string
-	proxyURL      string
-	eventHandlers map[EventType][]func()
+	Name           string
+	Token          string // Access token (optional, overrides env)
+	client         *direct.Client
+	listeners      []*Listener
+	auth           *direct.Auth
+	endpoint       string
+	proxyURL       string
+	tokenEnv       string
+	directory      *Directory
+	talkFetch      sync.Mutex
+	middleware     []Middleware
+	receive        []Middleware
+	allowSelf      bool
+	selfMu         sync.RWMutex
+	selfID         string
+	selfName       string
+	conversations  conversations
+	brain          Brain
+	cron           cronScheduler
+	handlers       handlerTracker
+	outbox         *outbox
+	plugins        []Plugin
+	pluginSettings PluginSettings
+	commandsOnce   sync.Once
+	commands       *Commands
+	routersMu      sync.Mutex
+	routers        []*Commands
+	gracePeriod    time.Duration
+	eventHandlers  map[EventType][]func()
+	notifyHandlers map[string][]NotifyHandler
+	metrics        *robotMetrics
+	metricsAddr    string
+	tracer         direct.Tracer
+	messages       Messages
 }
 
 // Option configures Robot behavior.
@@ -120,6 +213,14 @@ func WithToken(token string) Option {
 	}
 }
 
+// WithTokenEnv reads the access token from the named environment variable
+// instead of HUBOT_DIRECT_TOKEN. The .env file is loaded before the lookup.
+func WithTokenEnv(key string) Option {
+	return func(r *Robot) {
+		r.tokenEnv = key
+	}
+}
+
 // WithEndpoint sets custom API endpoint.
 func WithEndpoint(endpoint string) Option {
 	return func(r *Robot) {
@@ -134,6 +235,41 @@ func WithProxy(proxyURL string) Option {
 	}
 }
 
+// WithDirectory sets the user directory cache, allowing it to be shared between robots.
+func WithDirectory(d *Directory) Option {
+	return func(r *Robot) {
+		r.directory = d
+	}
+}
+
+// WithBrain sets the brain used to store the robot's data.
+// The default is an in-memory brain.
+func WithBrain(b Brain) Option {
+	return func(r *Robot) {
+		r.brain = b
+	}
+}
+
+// WithSelfMessages makes the robot deliver messages it sent itself to listeners.
+// By default they are skipped to avoid echo loops.
+func WithSelfMessages() Option {
+	return func(r *Robot) {
+		r.allowSelf = true
+	}
+}
+
+// resolveEndpoint returns endpoint, or the endpoint from the environment or
+// the default if it is empty.
+func resolveEndpoint(endpoint string) string {
+	if endpoint == "" {
+		endpoint = os.Getenv("HUBOT_DIRECT_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = direct.DefaultEndpoint
+	}
+	return endpoint
+}
+
 // New creates a new Robot with the given options.
 func New(opts ...Option) *Robot {
 	r := &Robot{
@@ -141,10 +277,21 @@ func New(opts ...Option) *Robot {
 		listeners:     make([]*Listener, 0),
 		auth:          direct.NewAuth(),
 		eventHandlers: make(map[EventType][]func()),
+		gracePeriod:   DefaultGracePeriod,
+		outbox:        newOutbox(),
 	}
 	for _, opt := range opts {
 		opt(r)
 	}
+	if r.directory == nil {
+		r.directory = NewDirectory(0)
+	}
+	if r.brain == nil {
+		r.brain = NewMemoryBrain()
+	}
+	if r.metricsAddr != "" && r.metrics == nil {
+		r.metrics = newRobotMetrics(metrics.NewRegistry(), r)
+	}
 	return r
 }
 
@@ -160,27 +307,154 @@ func (r *Robot) emit(event EventType) {
 }
 
 // Hear registers a listener that matches any message containing the pattern.
-func (r *Robot) Hear(pattern string, handler Handler) {
+func (r *Robot) Hear(pattern string, handler Handler, opts ...ListenerOption) {
 	re := regexp.MustCompile("(?i)" + pattern)
-	r.listeners = append(r.listeners, &Listener{
+	r.addListener(&Listener{
 		Pattern:  re,
 		Handler:  handler,
 		IsDirect: false,
-	})
+		Name:     "hear:" + pattern,
+	}, opts)
 }
 
 // Respond registers a listener that only matches messages directed at the bot.
-func (r *Robot) Respond(pattern string, handler Handler) {
-	re := regexp.MustCompile(fmt.Sprintf("(?i)^@?%s[,:]?\\s*%s", r.Name, pattern))
-	r.listeners = append(r.listeners, &Listener{
-		Pattern:  re,
+// A message is directed at the bot when it starts with a mention of the bot's
+// account, or with the bot's name or display name (optionally prefixed by "@").
+// The pattern is matched against the rest of the message, and is the
+// listener's Pattern.
+func (r *Robot) Respond(pattern string, handler Handler, opts ...ListenerOption) {
+	body := regexp.MustCompile("(?i)^(?:" + pattern + ")")
+	r.addListener(&Listener{
+		Pattern:  body,
 		Handler:  handler,
 		IsDirect: true,
-	})
+		Name:     "respond:" + pattern,
+		match: func(msg direct.ReceivedMessage) ([]string, bool) {
+			rest, ok := r.addressedText(msg)
+			if !ok {
+				return nil, false
+			}
+			matches := body.FindStringSubmatch(rest)
+			return matches, matches != nil
+		},
+	}, opts)
+}
+
+var addressSeparator = regexp.MustCompile(`^[,:]?\s*`)
+
+// addressedText returns the message text following the address to the robot,
+// or false if the message is not directed at the robot.
+func (r *Robot) addressedText(msg direct.ReceivedMessage) (string, bool) {
+	text := strings.TrimLeftFunc(msg.Text, unicode.IsSpace)
+
+	// A real mention of the robot's account.
+	if mentions := direct.ParseMentions(text); len(mentions) > 0 && mentions[0].Start == 0 {
+		if self := r.SelfID(); self != "" && mentions[0].UserID == self {
+			return addressSeparator.ReplaceAllString(text[mentions[0].End:], ""), true
+		}
+	}
+
+	// The robot's name or display name written as plain text.
+	text = direct.StripMentions(text)
+	for _, name := range []string{r.Name, r.SelfName()} {
+		if name == "" {
+			continue
+		}
+		rest := strings.TrimPrefix(text, "@")
+		if len(rest) >= len(name) && strings.EqualFold(rest[:len(name)], name) && endsName(rest[len(name):]) {
+			return addressSeparator.ReplaceAllString(rest[len(name):], ""), true
+		}
+	}
+	return "", false
+}
+
+// endsName reports whether rest, the text following a name, ends the name:
+// it is empty or starts with whitespace, "," or ":". "botping" does not
+// address a robot named "bot".
+func endsName(rest string) bool {
+	if rest == "" {
+		return true
+	}
+	c, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsSpace(c) || c == ',' || c == ':'
+}
+
+func (r *Robot) addListener(l *Listener, opts []ListenerOption) {
+	for _, opt := range opts {
+		opt(l)
+	}
+	r.listeners = append(r.listeners, l)
+}
+
+// SelfID returns the robot's own user ID, or "" before it is known.
+// It is learned from get_me when the session is created.
+func (r *Robot) SelfID() string {
+	r.selfMu.RLock()
+	defer r.selfMu.RUnlock()
+	return r.selfID
+}
+
+func (r *Robot) setSelfID(id string) {
+	r.selfMu.Lock()
+	r.selfID = id
+	r.selfMu.Unlock()
+}
+
+// SelfName returns the robot's display name on direct, or "" before it is known.
+// It is learned from get_me when the session is created.
+func (r *Robot) SelfName() string {
+	r.selfMu.RLock()
+	defer r.selfMu.RUnlock()
+	return r.selfName
+}
+
+// learnSelf fetches the robot's own user ID, falling back to the user_id
+// returned by create_session.
+func (r *Robot) learnSelf(ctx context.Context, session interface{}) {
+	if m, ok := session.(map[string]interface{}); ok {
+		if id, ok := m["user_id"]; ok && id != nil {
+			r.setSelfID(fmt.Sprintf("%v", id))
+		}
+	}
+
+	me, err := r.client.GetMeWithContext(ctx)
+	if err != nil {
+		log.Printf("Warning: could not get own user info: %v", err)
+		return
+	}
+	if me != nil && me.ID != nil {
+		r.setSelfID(fmt.Sprintf("%v", me.ID))
+		r.selfMu.Lock()
+		r.selfName = userName(me)
+		r.selfMu.Unlock()
+	}
+}
+
+// userName returns the name shown for a user in direct.
+func userName(user *direct.UserInfo) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Name
 }
 
 // Run starts the bot and blocks until the context is cancelled or interrupted.
+//
+// On shutdown Run stops accepting messages, waits for in-flight handlers up
+// to the grace period (see WithGracePeriod), sends messages still queued
+// within another grace period and then disconnects.
+// Run handles SIGINT and SIGTERM itself only when ctx can never be cancelled
+// (such as context.Background()); otherwise stopping is left to the caller.
 func (r *Robot) Run(ctx context.Context) error {
+	ownSignals := ctx.Done() == nil
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Handlers keep running after ctx is cancelled until the grace period ends.
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+
 	// Load environment
 	if err := r.auth.LoadEnv(); err != nil {
 		log.Printf("Warning: could not load .env: %v", err)
@@ -188,21 +462,26 @@ func (r *Robot) Run(ctx context.Context) error {
 
 	// Get token
 	token := r.Token
-	if token == "" {
+	if token == "" && r.tokenEnv != "" {
+		token = os.Getenv(r.tokenEnv)
+	} else if token == "" {
 		token = r.auth.GetToken()
 	}
 	if token == "" {
 		return ErrNoToken
 	}
 
-	// Get configuration from environment (can be overridden by options)
-	endpoint := r.endpoint
-	if endpoint == "" {
-		endpoint = os.Getenv("HUBOT_DIRECT_ENDPOINT")
-	}
-	if endpoint == "" {
-		endpoint = direct.DefaultEndpoint
+	if err := r.brain.Load(); err != nil {
+		return fmt.Errorf("failed to load brain: %w", err)
 	}
+	defer func() {
+		if err := r.brain.Save(); err != nil {
+			log.Printf("Warning: could not save brain: %v", err)
+		}
+	}()
+
+	// Get configuration from environment (can be overridden by options)
+	endpoint := resolveEndpoint(r.endpoint)
 
 	proxyURL := r.proxyURL
 	if proxyURL == "" {
@@ -221,11 +500,14 @@ func (r *Robot) Run(ctx context.Context) error {
 		AccessToken: token,
 		ProxyURL:    proxyURL,
 		Name:        r.Name,
+		Metrics:     r.Metrics(),
+		Tracer:      r.tracer,
 	})
 
 	// Register event handlers
 	r.client.On(direct.EventSessionCreated, func(data interface{}) {
 		fmt.Printf("%s: Session created\n", r.Name)
+		r.learnSelf(ctx, data)
 		r.emit(EventConnected)
 	})
 
@@ -236,8 +518,9 @@ func (r *Robot) Run(ctx context.Context) error {
 
 	// Register message handler
 	r.client.OnMessage(func(msg direct.ReceivedMessage) {
-		r.handleMessage(ctx, msg)
+		r.handleMessage(handlerCtx, msg)
 	})
+	r.subscribeNotify(handlerCtx)
 
 	// Connect
 	fmt.Printf("%s is starting...\n", r.Name)
@@ -249,11 +532,39 @@ func (r *Robot) Run(ctx context.Context) error {
 		r.emit(EventDisconnected)
 	}()
 
-	fmt.Printf("%s is running! Press Ctrl+C to stop.\n", r.Name)
+	// Flush queued messages after cron jobs have stopped sending.
+	r.outbox.start()
+	defer r.outbox.flush(r.gracePeriod)
+
+	// Stop cron jobs before disconnecting so running jobs can still send.
+	waitCron := r.cron.start(ctx)
+	defer waitCron()
+	defer cancel()
+
+	// Stop the plugins that have started, such as HTTP servers, if Run
+	// fails before the robot is running.
+	started, err := r.startPlugins(ctx)
+	if err != nil {
+		r.stopPlugins(started)
+		return err
+	}
+	stopMetrics, err := r.serveMetrics()
+	if err != nil {
+		r.stopPlugins(started)
+		return err
+	}
+	defer stopMetrics()
 
 	// Wait for interrupt or context cancellation
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	var sigCh chan os.Signal
+	if ownSignals {
+		fmt.Printf("%s is running! Press Ctrl+C to stop.\n", r.Name)
+		sigCh = make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigCh)
+	} else {
+		fmt.Printf("%s is running!\n", r.Name)
+	}
 
 	select {
 	case <-ctx.Done():
@@ -264,31 +575,264 @@ func (r *Robot) Run(ctx context.Context) error {
 		fmt.Printf("\n%s: connection closed.\n", r.Name)
 	}
 
+	r.drain(cancelHandlers)
+	r.stopPlugins(started)
 	return nil
 }
 
-// handleMessage processes incoming messages.
+// handleMessage processes incoming messages through the receive middleware.
+// Messages sent by the robot itself are skipped unless WithSelfMessages is set.
 func (r *Robot) handleMessage(ctx context.Context, msg direct.ReceivedMessage) {
+	if !r.allowSelf && msg.UserID != "" && msg.UserID == r.SelfID() {
+		return
+	}
+	if !r.handlers.begin() {
+		log.Printf("[DEBUG] Dropping message %s: shutting down", msg.ID)
+		return
+	}
+	defer r.handlers.done()
+
+	ctx, span := r.startSpan(ctx, "daab/receive", messageAttributes(msg)...)
+	defer span.End(nil)
+
+	receive := chain(r.dispatch, r.receive)
+	receive(ctx, Response{
+		Message: msg,
+		Robot:   r,
+		ctx:     ctx,
+	})
+}
+
+// dispatch runs every matching listener, wrapped in the listener middleware.
+// Answers to a waiting Conversation are not dispatched to listeners.
+func (r *Robot) dispatch(ctx context.Context, res Response) {
+	msg := res.Message
+	if r.conversations.deliver(msg) {
+		return
+	}
 	for _, listener := range r.listeners {
-		matches := listener.Pattern.FindStringSubmatch(msg.Text)
-		if matches != nil {
-			log.Printf("[DEBUG] Matched pattern: %s with text: %s", listener.Pattern.String(), msg.Text)
+		matches, ok := listener.matches(msg)
+		if ok {
+			if listener.Pattern != nil {
+				log.Printf("[DEBUG] Matched pattern: %s with text: %s", listener.Pattern.String(), msg.Text)
+			}
 			response := Response{
-				Message: msg,
-				Match:   matches,
-				Robot:   r,
+				Message:  msg,
+				Match:    matches,
+				Robot:    r,
+				Listener: listener,
+				ctx:      ctx,
 			}
-			go listener.Handler(ctx, response)
+			r.handlers.add()
+			go func(listener *Listener) {
+				defer r.handlers.done()
+				r.runListener(ctx, listener, response)
+			}(listener)
+		}
+	}
+}
+
+// runListener runs the listener's handler if all its filters accept the message.
+func (r *Robot) runListener(ctx context.Context, listener *Listener, res Response) {
+	for _, filter := range listener.Filters {
+		if !filter(ctx, res) {
+			return
 		}
 	}
+	r.metrics.matched(listener)
+	if r.metrics != nil {
+		// Count panics not recovered by middleware such as Recover.
+		defer func() {
+			if v := recover(); v != nil {
+				r.metrics.panicked(listener)
+				panic(v)
+			}
+		}()
+		defer r.metrics.handled(listener, time.Now())
+	}
+	if r.tracer != nil {
+		var span direct.Span
+		ctx, span = r.startSpan(ctx, handlerSpanName(listener),
+			direct.Attribute{Key: AttrListener, Value: listener.Name})
+		res.ctx = ctx
+		defer func() {
+			if v := recover(); v != nil {
+				span.End(fmt.Errorf("daab: handler panicked: %v", v))
+				panic(v)
+			}
+			span.End(nil)
+		}()
+	}
+	chain(listener.Handler, r.middleware)(ctx, res)
 }
 
-// SendText sends a text message to a room.
+// SendText sends a text message to a room and waits until it was sent.
+// The message goes through the outgoing queue; see SendTextAsync.
 func (r *Robot) SendText(roomID, text string) error {
-	if r.client == nil {
-		return ErrNotConnected
+	return r.sendText(context.Background(), roomID, text)
+}
+
+// sendText sends a text message traced as a child of the span in ctx.
+func (r *Robot) sendText(ctx context.Context, roomID, text string) error {
+	_, err := r.EnqueueContext(ctx, roomID, direct.MsgTypeText, text).Wait(context.Background())
+	return err
+}
+
+// SendSelect sends a select action stamp to a room and returns the created message ID.
+func (r *Robot) SendSelect(roomID, question string, options []string) (string, error) {
+	return r.sendSelect(context.Background(), roomID, question, options)
+}
+
+// SendSelectContext is like SendSelect, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) SendSelectContext(ctx context.Context, roomID, question string, options []string) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeSelect, selectContent(question, options)).Wait(ctx))
+}
+
+func (r *Robot) sendSelect(ctx context.Context, roomID, question string, options []string) (string, error) {
+	// Use wire type (502) not internal enum value (15) for action stamps
+	return r.sendActionMessage(ctx, roomID, direct.WireTypeSelect, selectContent(question, options))
+}
+
+func selectContent(question string, options []string) map[string]interface{} {
+	// Use map format instead of struct to ensure proper msgpack serialization
+	return map[string]interface{}{
+		"question":     question,
+		"options":      options,
+		"listing":      true,
+		"closing_type": 1, // default to "all must answer" per daab spec
 	}
-	return r.client.SendText(roomID, text)
+}
+
+// SendYesNo sends a yes/no action stamp to a room and returns the created message ID.
+func (r *Robot) SendYesNo(roomID, question string) (string, error) {
+	return r.sendYesNo(context.Background(), roomID, question)
+}
+
+// SendYesNoContext is like SendYesNo, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) SendYesNoContext(ctx context.Context, roomID, question string) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeYesNo, yesNoContent(question)).Wait(ctx))
+}
+
+func (r *Robot) sendYesNo(ctx context.Context, roomID, question string) (string, error) {
+	return r.sendActionMessage(ctx, roomID, direct.WireTypeYesNo, yesNoContent(question))
+}
+
+func yesNoContent(question string) map[string]interface{} {
+	return map[string]interface{}{
+		"question": question,
+		"listing":  true,
+	}
+}
+
+// SendTask sends a task action stamp to a room and returns the created message ID.
+func (r *Robot) SendTask(roomID, title string) (string, error) {
+	return r.sendActionMessage(context.Background(), roomID, direct.WireTypeTask, taskContent(title))
+}
+
+// SendTaskContext is like SendTask, but stops waiting when ctx is done and
+// traces the message as a child of the span in ctx.
+func (r *Robot) SendTaskContext(ctx context.Context, roomID, title string) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeTask, taskContent(title)).Wait(ctx))
+}
+
+func taskContent(title string) map[string]interface{} {
+	return map[string]interface{}{
+		"title": title,
+	}
+}
+
+// ReplySelect answers the select action stamp inReplyTo in a room with the
+// index of the chosen option, and returns the created message ID.
+func (r *Robot) ReplySelect(roomID, inReplyTo string, response int) (string, error) {
+	return r.sendActionMessage(context.Background(), roomID, direct.WireTypeSelectReply, replyContent(inReplyTo, "response", response))
+}
+
+// ReplySelectContext is like ReplySelect, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) ReplySelectContext(ctx context.Context, roomID, inReplyTo string, response int) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeSelectReply, replyContent(inReplyTo, "response", response)).Wait(ctx))
+}
+
+// ReplyYesNo answers the yes/no action stamp inReplyTo in a room and returns
+// the created message ID.
+func (r *Robot) ReplyYesNo(roomID, inReplyTo string, response bool) (string, error) {
+	return r.sendActionMessage(context.Background(), roomID, direct.WireTypeYesNoReply, replyContent(inReplyTo, "response", response))
+}
+
+// ReplyYesNoContext is like ReplyYesNo, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) ReplyYesNoContext(ctx context.Context, roomID, inReplyTo string, response bool) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeYesNoReply, replyContent(inReplyTo, "response", response)).Wait(ctx))
+}
+
+// ReplyTask marks the task action stamp inReplyTo in a room as done or not
+// done, and returns the created message ID.
+func (r *Robot) ReplyTask(roomID, inReplyTo string, done bool) (string, error) {
+	return r.sendActionMessage(context.Background(), roomID, direct.WireTypeTaskDone, replyContent(inReplyTo, "done", done))
+}
+
+// ReplyTaskContext is like ReplyTask, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) ReplyTaskContext(ctx context.Context, roomID, inReplyTo string, done bool) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeTaskDone, replyContent(inReplyTo, "done", done)).Wait(ctx))
+}
+
+func replyContent(inReplyTo, key string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"in_reply_to": normalizeRoomID(inReplyTo),
+		key:           value,
+	}
+}
+
+// CloseSelect closes the select action stamp messageID in a room so that it
+// accepts no more answers, and returns the created message ID.
+func (r *Robot) CloseSelect(roomID, messageID string) (string, error) {
+	return r.sendActionMessage(context.Background(), roomID, direct.WireTypeSelectClosed, closeContent(messageID))
+}
+
+// CloseSelectContext is like CloseSelect, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) CloseSelectContext(ctx context.Context, roomID, messageID string) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeSelectClosed, closeContent(messageID)).Wait(ctx))
+}
+
+// CloseYesNo closes the yes/no action stamp messageID in a room so that it
+// accepts no more answers, and returns the created message ID.
+func (r *Robot) CloseYesNo(roomID, messageID string) (string, error) {
+	return r.sendActionMessage(context.Background(), roomID, direct.WireTypeYesNoClosed, closeContent(messageID))
+}
+
+// CloseYesNoContext is like CloseYesNo, but stops waiting when ctx is done
+// and traces the message as a child of the span in ctx.
+func (r *Robot) CloseYesNoContext(ctx context.Context, roomID, messageID string) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, direct.WireTypeYesNoClosed, closeContent(messageID)).Wait(ctx))
+}
+
+func closeContent(messageID string) map[string]interface{} {
+	return map[string]interface{}{
+		"in_reply_to": normalizeRoomID(messageID),
+	}
+}
+
+// SendStamp sends a stamp to a room and returns the created message ID.
+// text is an optional caption.
+func (r *Robot) SendStamp(roomID, stampSet, stampIndex, text string) (string, error) {
+	return r.SendStampContext(context.Background(), roomID, stampSet, stampIndex, text)
+}
+
+// SendStampContext is like SendStamp, but stops waiting when ctx is done and
+// traces the message as a child of the span in ctx.
+func (r *Robot) SendStampContext(ctx context.Context, roomID, stampSet, stampIndex, text string) (string, error) {
+	content := map[string]interface{}{
+		"stamp_set":   stampSet,
+		"stamp_index": stampIndex,
+	}
+	if text != "" {
+		content["text"] = text
+	}
+	return r.EnqueueContext(ctx, roomID, direct.MsgTypeStamp, content).Wait(ctx)
 }
 
 // Call exposes direct-go Client.Call for advanced use cases such as fetching action stamp answers.
@@ -299,18 +843,25 @@ func (r *Robot) Call(method string, params []interface{}) (interface{}, error) {
 	return r.client.Call(method, params)
 }
 
-func (r *Robot) sendActionMessage(roomID string, msgType int, content interface{}) (string, error) {
+// CallContext is like Call, but stops waiting when ctx is done and traces
+// the call as a child of the span in ctx, such as Response.Context().
+func (r *Robot) CallContext(ctx context.Context, method string, params []interface{}) (interface{}, error) {
 	if r.client == nil {
-		return "", ErrNotConnected
+		return nil, ErrNotConnected
 	}
+	return r.client.CallContext(ctx, method, params)
+}
 
-	talkID := normalizeRoomID(roomID)
-	result, err := r.client.Call(direct.MethodCreateMessage, []interface{}{talkID, msgType, content})
+// sendActionMessage sends an action stamp traced as a child of the span in
+// ctx, and waits until it was sent even if ctx is done.
+func (r *Robot) sendActionMessage(ctx context.Context, roomID string, msgType int, content interface{}) (string, error) {
+	return actionMessageID(r.EnqueueContext(ctx, roomID, msgType, content).Wait(context.Background()))
+}
+
+func actionMessageID(messageID string, err error) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	messageID := extractMessageID(result)
 	if messageID == "" {
 		return "", fmt.Errorf("create_message returned empty id")
 	}
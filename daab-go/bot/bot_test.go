@@ -3,6 +3,7 @@ package bot
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -106,21 +107,16 @@ func TestRespond(t *testing.T) {
 		t.Error("Expected Respond listener to be direct")
 	}
 
-	// Test pattern matching for direct address
-	matches := listener.Pattern.FindStringSubmatch("@testbot ping")
-	if matches == nil {
-		t.Error("Expected pattern to match '@testbot ping'")
-	}
-
-	matches = listener.Pattern.FindStringSubmatch("testbot: ping")
-	if matches == nil {
-		t.Error("Expected pattern to match 'testbot: ping'")
+	// Test matching for direct address
+	for _, text := range []string{"@testbot ping", "testbot: ping"} {
+		if _, ok := listener.matches(direct.ReceivedMessage{Text: text}); !ok {
+			t.Errorf("Expected listener to match %q", text)
+		}
 	}
 
 	// Should not match without bot name
-	matches = listener.Pattern.FindStringSubmatch("ping")
-	if matches != nil {
-		t.Error("Expected pattern to not match 'ping' without bot name")
+	if _, ok := listener.matches(direct.ReceivedMessage{Text: "ping"}); ok {
+		t.Error("Expected listener to not match 'ping' without bot name")
 	}
 
 	// Simulate handler call
@@ -421,6 +417,39 @@ func TestSendSelect(t *testing.T) {
 	}
 }
 
+func TestActionStampReplies(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	tests := []struct {
+		send    func() (string, error)
+		msgType int
+		field   string
+		value   string
+	}{
+		{func() (string, error) { return robot.SendTask("100", "レビュー") }, direct.WireTypeTask, "title", "レビュー"},
+		{func() (string, error) { return robot.ReplySelect("100", "42", 1) }, direct.WireTypeSelectReply, "response", "1"},
+		{func() (string, error) { return robot.ReplyYesNo("100", "42", true) }, direct.WireTypeYesNoReply, "response", "true"},
+		{func() (string, error) { return robot.ReplyTask("100", "42", true) }, direct.WireTypeTaskDone, "done", "true"},
+		{func() (string, error) { return robot.CloseSelect("100", "42") }, direct.WireTypeSelectClosed, "in_reply_to", "42"},
+		{func() (string, error) { return robot.CloseYesNo("100", "42") }, direct.WireTypeYesNoClosed, "in_reply_to", "42"},
+	}
+	for i, tt := range tests {
+		if id, err := tt.send(); err != nil || id != "m" {
+			t.Fatalf("Action %d: got %q, %v", i, id, err)
+		}
+		msgs := mockServer.GetReceivedMessages()
+		params := msgs[len(msgs)-1][3].([]interface{})
+		content, _ := params[2].(map[string]interface{})
+		if fmt.Sprint(params[1]) != fmt.Sprint(tt.msgType) || fmt.Sprint(content[tt.field]) != tt.value {
+			t.Errorf("Action %d: expected type %d with %s=%s, got %v", i, tt.msgType, tt.field, tt.value, params)
+		}
+		if tt.field != "title" && fmt.Sprint(content["in_reply_to"]) != "42" {
+			t.Errorf("Action %d: expected in_reply_to 42, got %v", i, content)
+		}
+	}
+}
+
 func TestReply(t *testing.T) {
 	mockServer := testutil.NewMockServer()
 	defer mockServer.Close()
@@ -0,0 +1,282 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Brain is the robot's key-value storage, like robot.brain in hubot.
+type Brain interface {
+	// Get returns the value stored under key.
+	// The bool is false if the key is missing or expired.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores value under key. A ttl of zero or less never expires.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key.
+	Delete(key string) error
+
+	// Load reads persisted data. It is called by Robot.Run before connecting.
+	Load() error
+
+	// Save persists the data. It is called by Robot.Run on shutdown.
+	Save() error
+}
+
+// GetJSON decodes the JSON value stored under key into v.
+// It reports false if the key is missing or expired.
+func GetJSON(b Brain, key string, v interface{}) (bool, error) {
+	data, ok, err := b.Get(key)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("daab: failed to decode brain key %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// SetJSON stores v under key encoded as JSON.
+func SetJSON(b Brain, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("daab: failed to encode brain key %s: %w", key, err)
+	}
+	return b.Set(key, data, ttl)
+}
+
+type brainEntry struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+func (e brainEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+// MemoryBrain is a Brain kept in memory. It is the default brain of a Robot
+// and loses its data when the process exits.
+type MemoryBrain struct {
+	mu   sync.Mutex
+	data map[string]brainEntry
+}
+
+// NewMemoryBrain creates an empty MemoryBrain.
+func NewMemoryBrain() *MemoryBrain {
+	return &MemoryBrain{data: make(map[string]brainEntry)}
+}
+
+// Get implements Brain.
+func (b *MemoryBrain) Get(key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(time.Now()) {
+		delete(b.data, key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), entry.Value...), true, nil
+}
+
+// Set implements Brain.
+func (b *MemoryBrain) Set(key string, value []byte, ttl time.Duration) error {
+	entry := brainEntry{Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.Expires = time.Now().Add(ttl)
+	}
+
+	b.mu.Lock()
+	b.data[key] = entry
+	b.mu.Unlock()
+	return nil
+}
+
+// Delete implements Brain.
+func (b *MemoryBrain) Delete(key string) error {
+	b.mu.Lock()
+	delete(b.data, key)
+	b.mu.Unlock()
+	return nil
+}
+
+// Load implements Brain. It does nothing for a MemoryBrain.
+func (b *MemoryBrain) Load() error { return nil }
+
+// Save implements Brain. It does nothing for a MemoryBrain.
+func (b *MemoryBrain) Save() error { return nil }
+
+// snapshot returns the unexpired entries.
+func (b *MemoryBrain) snapshot() map[string]brainEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	data := make(map[string]brainEntry, len(b.data))
+	for key, entry := range b.data {
+		if entry.expired(now) {
+			delete(b.data, key)
+			continue
+		}
+		data[key] = entry
+	}
+	return data
+}
+
+// FileBrain is a Brain persisted to a JSON file.
+// Every change is written to the file, replacing it atomically.
+type FileBrain struct {
+	MemoryBrain
+	path   string
+	saveMu sync.Mutex
+}
+
+// NewFileBrain creates a FileBrain stored at path.
+// The file is read by Load and created on the first change.
+func NewFileBrain(path string) *FileBrain {
+	return &FileBrain{
+		MemoryBrain: MemoryBrain{data: make(map[string]brainEntry)},
+		path:        path,
+	}
+}
+
+// Set implements Brain.
+func (b *FileBrain) Set(key string, value []byte, ttl time.Duration) error {
+	if err := b.MemoryBrain.Set(key, value, ttl); err != nil {
+		return err
+	}
+	return b.Save()
+}
+
+// Delete implements Brain.
+func (b *FileBrain) Delete(key string) error {
+	if err := b.MemoryBrain.Delete(key); err != nil {
+		return err
+	}
+	return b.Save()
+}
+
+// Load implements Brain. A missing file is treated as an empty brain.
+func (b *FileBrain) Load() error {
+	raw, err := os.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]brainEntry)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("failed to parse brain %s: %w", b.path, err)
+	}
+	if data == nil {
+		// The file holds null.
+		data = make(map[string]brainEntry)
+	}
+
+	b.mu.Lock()
+	b.data = data
+	b.mu.Unlock()
+	return nil
+}
+
+// Save implements Brain. The file is written to a temporary file in the
+// same directory and renamed over the old one.
+func (b *FileBrain) Save() error {
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+
+	raw, err := json.MarshalIndent(b.snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), filepath.Base(b.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), b.path)
+}
+
+// Namespace is a view of a Brain whose keys are prefixed with a name.
+type Namespace struct {
+	brain  Brain
+	prefix string
+}
+
+// NewNamespace returns a view of b whose keys are prefixed with name.
+func NewNamespace(b Brain, name string) *Namespace {
+	return &Namespace{brain: b, prefix: name + ":"}
+}
+
+// Get implements Brain.
+func (n *Namespace) Get(key string) ([]byte, bool, error) {
+	return n.brain.Get(n.prefix + key)
+}
+
+// Set implements Brain.
+func (n *Namespace) Set(key string, value []byte, ttl time.Duration) error {
+	return n.brain.Set(n.prefix+key, value, ttl)
+}
+
+// Delete implements Brain.
+func (n *Namespace) Delete(key string) error {
+	return n.brain.Delete(n.prefix + key)
+}
+
+// Load implements Brain by loading the underlying brain.
+func (n *Namespace) Load() error {
+	return n.brain.Load()
+}
+
+// Save implements Brain by saving the underlying brain.
+func (n *Namespace) Save() error {
+	return n.brain.Save()
+}
+
+// Brain returns the robot's brain.
+func (r *Robot) Brain() Brain {
+	return r.brain
+}
+
+// UserBrain returns the brain namespace of userID.
+func (r *Robot) UserBrain(userID string) *Namespace {
+	return NewNamespace(r.brain, "user:"+userID)
+}
+
+// TalkBrain returns the brain namespace of talkID.
+func (r *Robot) TalkBrain(talkID string) *Namespace {
+	return NewNamespace(r.brain, "talk:"+talkID)
+}
+
+// UserBrain returns the brain namespace of the message sender.
+func (r Response) UserBrain() *Namespace {
+	return r.Robot.UserBrain(r.Message.UserID)
+}
+
+// TalkBrain returns the brain namespace of the message's talk.
+func (r Response) TalkBrain() *Namespace {
+	return r.Robot.TalkBrain(r.Message.TalkID)
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+func TestMemoryBrain(t *testing.T) {
+	b := NewMemoryBrain()
+
+	if _, ok, _ := b.Get("missing"); ok {
+		t.Error("Expected missing key not to be found")
+	}
+
+	if err := b.Set("k", []byte("v"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if v, ok, _ := b.Get("k"); !ok || string(v) != "v" {
+		t.Errorf("Expected v, got %q (found=%v)", v, ok)
+	}
+
+	if err := b.Delete("k"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok, _ := b.Get("k"); ok {
+		t.Error("Expected deleted key not to be found")
+	}
+
+	b.Set("short", []byte("v"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, _ := b.Get("short"); ok {
+		t.Error("Expected expired key not to be found")
+	}
+}
+
+func TestBrainJSON(t *testing.T) {
+	b := NewMemoryBrain()
+
+	type counter struct {
+		Count int `json:"count"`
+	}
+	if err := SetJSON(b, "c", counter{Count: 3}, 0); err != nil {
+		t.Fatalf("SetJSON failed: %v", err)
+	}
+
+	var got counter
+	ok, err := GetJSON(b, "c", &got)
+	if err != nil || !ok || got.Count != 3 {
+		t.Errorf("Expected count 3, got %+v (found=%v, err=%v)", got, ok, err)
+	}
+
+	if ok, err := GetJSON(b, "missing", &got); ok || err != nil {
+		t.Errorf("Expected missing key, got found=%v err=%v", ok, err)
+	}
+
+	b.Set("bad", []byte("{"), 0)
+	if _, err := GetJSON(b, "bad", &got); err == nil {
+		t.Error("Expected error decoding invalid JSON")
+	}
+}
+
+func TestBrainNamespaces(t *testing.T) {
+	robot := New()
+	res := Response{
+		Message: direct.ReceivedMessage{TalkID: "t1", UserID: "u1"},
+		Robot:   robot,
+	}
+
+	res.UserBrain().Set("name", []byte("alice"), 0)
+	res.TalkBrain().Set("name", []byte("general"), 0)
+
+	if v, _, _ := robot.Brain().Get("user:u1:name"); string(v) != "alice" {
+		t.Errorf("Expected user namespace value alice, got %q", v)
+	}
+	if v, _, _ := robot.TalkBrain("t1").Get("name"); string(v) != "general" {
+		t.Errorf("Expected talk namespace value general, got %q", v)
+	}
+	if _, ok, _ := robot.UserBrain("u2").Get("name"); ok {
+		t.Error("Expected other user's namespace to be empty")
+	}
+}
+
+func TestFileBrain(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "brain.json")
+
+	b := NewFileBrain(path)
+	if err := b.Load(); err != nil {
+		t.Fatalf("Load of missing file failed: %v", err)
+	}
+	if err := SetJSON(b, "k", []string{"a", "b"}, 0); err != nil {
+		t.Fatalf("SetJSON failed: %v", err)
+	}
+	b.Set("gone", []byte("x"), time.Nanosecond)
+	b.Set("deleted", []byte("x"), 0)
+	b.Delete("deleted")
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the brain file in %s, got %d entries", dir, len(entries))
+	}
+
+	loaded := NewFileBrain(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	var got []string
+	if ok, _ := GetJSON(loaded, "k", &got); !ok || len(got) != 2 || got[1] != "b" {
+		t.Errorf("Expected [a b], got %v (found=%v)", got, ok)
+	}
+	for _, key := range []string{"gone", "deleted"} {
+		if _, ok, _ := loaded.Get(key); ok {
+			t.Errorf("Expected %s not to be persisted", key)
+		}
+	}
+}
+
+func TestFileBrainNull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brain.json")
+	if err := os.WriteFile(path, []byte("null"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewFileBrain(path)
+	if err := b.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := b.Set("k", []byte("v"), 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if v, ok, _ := b.Get("k"); !ok || string(v) != "v" {
+		t.Errorf("Expected the value to be stored, got %q (found=%v)", v, ok)
+	}
+}
+
+func TestRunFailsOnBrokenBrain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brain.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	robot := New(WithToken("token"), WithBrain(NewFileBrain(path)))
+	if err := robot.Run(context.Background()); err == nil {
+		t.Error("Expected Run to fail loading a broken brain")
+	}
+}
@@ -0,0 +1,531 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ArgType is the type of a command argument or flag.
+type ArgType string
+
+// Argument types usable in command specs, e.g. <count:int>.
+const (
+	ArgString   ArgType = "string"
+	ArgInt      ArgType = "int"
+	ArgBool     ArgType = "bool"
+	ArgDuration ArgType = "duration"
+)
+
+// CommandArg is a positional argument of a command.
+type CommandArg struct {
+	Name     string
+	Type     ArgType
+	Optional bool
+	Variadic bool // Collects all remaining arguments
+}
+
+// CommandFlag is a --name flag of a command.
+type CommandFlag struct {
+	Name    string
+	Type    ArgType
+	Default string
+}
+
+// CommandHandler handles a parsed command.
+type CommandHandler func(ctx context.Context, res Response, args Args)
+
+// Command is a command registered with Commands.
+type Command struct {
+	// Path is the command name, including subcommand words.
+	Path []string
+
+	// Spec is the spec the command was declared with.
+	Spec string
+
+	// Description is shown by the help command.
+	Description string
+
+	Args  []CommandArg
+	Flags []CommandFlag
+
+	handler     CommandHandler
+	permissions []Filter
+}
+
+// CommandOption configures a Command.
+type CommandOption func(*Command)
+
+// WithPermission only lets messages accepted by f run the command.
+func WithPermission(f Filter) CommandOption {
+	return func(c *Command) {
+		c.permissions = append(c.permissions, f)
+	}
+}
+
+// RequireUsers only lets the given user IDs run the command.
+func RequireUsers(userIDs ...string) CommandOption {
+	allowed := toSet(userIDs)
+	return WithPermission(func(ctx context.Context, res Response) bool {
+		return allowed[res.Message.UserID]
+	})
+}
+
+// RequireDomains only lets users of the given domain IDs run the command.
+func RequireDomains(domainIDs ...string) CommandOption {
+	allowed := toSet(domainIDs)
+	return WithPermission(func(ctx context.Context, res Response) bool {
+		return allowed[res.Message.DomainID]
+	})
+}
+
+// Usage returns the usage line of the command.
+func (c *Command) Usage() string {
+	return c.Spec
+}
+
+func (c *Command) allowed(ctx context.Context, res Response) bool {
+	for _, p := range c.permissions {
+		if !p(ctx, res) {
+			return false
+		}
+	}
+	return true
+}
+
+// Commands routes messages directed at the robot to declared commands.
+// Commands are declared with a spec such as
+//
+//	deploy <service> [--env=prod]
+//
+// where <name> is a required argument, [name] an optional one, <name...>
+// collects the remaining arguments and [--name=default] is a flag.
+// Arguments and flags can be typed with :int, :bool or :duration, e.g.
+// <count:int> or [--timeout:duration=30s]. A flag without a value is a bool.
+//
+// A robot answers help once, from its shared router (see Robot.Commands),
+// whatever number of routers it has. The texts of help and usage errors are
+// taken from the robot's Messages.
+type Commands struct {
+	robot    *Robot
+	mu       sync.RWMutex
+	commands []*Command
+}
+
+// NewCommands creates a command router and registers it on the robot with
+// Respond. The listener options apply to every command. The robot's shared
+// router is created too, so that help lists the commands.
+func NewCommands(r *Robot, opts ...ListenerOption) *Commands {
+	r.Commands()
+	return newCommands(r, opts...)
+}
+
+func newCommands(r *Robot, opts ...ListenerOption) *Commands {
+	c := &Commands{robot: r}
+	r.routersMu.Lock()
+	r.routers = append(r.routers, c)
+	r.routersMu.Unlock()
+	r.Respond(`(?s:(.*))`, c.handle, opts...)
+	return c
+}
+
+// Command declares a command. It panics if spec is invalid.
+func (c *Commands) Command(spec, description string, handler CommandHandler, opts ...CommandOption) *Command {
+	cmd, err := parseCommandSpec(spec)
+	if err != nil {
+		panic(fmt.Sprintf("daab: invalid command spec %q: %v", spec, err))
+	}
+	cmd.Description = description
+	cmd.handler = handler
+	for _, opt := range opts {
+		opt(cmd)
+	}
+
+	c.mu.Lock()
+	c.commands = append(c.commands, cmd)
+	c.mu.Unlock()
+	return cmd
+}
+
+// Commands returns the declared commands in declaration order.
+func (c *Commands) Commands() []*Command {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]*Command(nil), c.commands...)
+}
+
+// Help returns the help text listing the commands of all the robot's
+// routers available to the sender of res. If prefix is given only matching
+// commands are listed.
+func (c *Commands) Help(ctx context.Context, res Response, prefix ...string) string {
+	m := c.robot.Messages()
+	var cmds []*Command
+	for _, router := range c.robot.routerList() {
+		cmds = append(cmds, router.Commands()...)
+	}
+	var lines []string
+	for _, cmd := range cmds {
+		if !hasPrefixFold(cmd.Path, prefix) || !cmd.allowed(ctx, res) {
+			continue
+		}
+		line := cmd.Usage()
+		if cmd.Description != "" {
+			line += " - " + cmd.Description
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return m.NoCommands
+	}
+	return m.HelpHeader + "\n" + strings.Join(lines, "\n")
+}
+
+func (c *Commands) help(ctx context.Context, res Response, args Args) {
+	res.Send(c.Help(ctx, res, args.Strings("command")...))
+}
+
+// handle runs the command matching the message. Messages that do not name
+// a command are left to other listeners.
+func (c *Commands) handle(ctx context.Context, res Response) {
+	if len(res.Match) < 2 {
+		return
+	}
+	words, err := splitCommandLine(res.Match[1])
+	if err != nil || len(words) == 0 {
+		return
+	}
+
+	cmd := c.lookup(words)
+	if cmd == nil {
+		return
+	}
+	m := c.robot.Messages()
+	if !cmd.allowed(ctx, res) {
+		res.Send(m.PermissionDenied)
+		return
+	}
+
+	args, err := cmd.parseArgs(words[len(cmd.Path):], m)
+	if err != nil {
+		res.Send(err.Error() + "\n" + formatMessage(m.Usage, cmd.Usage()))
+		return
+	}
+	cmd.handler(ctx, res, args)
+}
+
+// lookup returns the command with the longest path matching words.
+func (c *Commands) lookup(words []string) *Command {
+	var found *Command
+	for _, cmd := range c.Commands() {
+		if hasPrefixFold(words, cmd.Path) && (found == nil || len(cmd.Path) > len(found.Path)) {
+			found = cmd
+		}
+	}
+	return found
+}
+
+func hasPrefixFold(words, prefix []string) bool {
+	if len(prefix) > len(words) {
+		return false
+	}
+	for i, p := range prefix {
+		if !strings.EqualFold(words[i], p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Args holds the parsed arguments and flags of a command.
+type Args struct {
+	values map[string]interface{}
+}
+
+// Has reports whether the argument or flag was given or has a default.
+func (a Args) Has(name string) bool {
+	_, ok := a.values[name]
+	return ok
+}
+
+// String returns a string argument or flag, or "" if it is not set.
+func (a Args) String(name string) string {
+	switch v := a.values[name].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, " ")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Int returns an int argument or flag, or 0 if it is not set.
+func (a Args) Int(name string) int {
+	v, _ := a.values[name].(int)
+	return v
+}
+
+// Bool returns a bool argument or flag, or false if it is not set.
+func (a Args) Bool(name string) bool {
+	v, _ := a.values[name].(bool)
+	return v
+}
+
+// Duration returns a duration argument or flag, or 0 if it is not set.
+func (a Args) Duration(name string) time.Duration {
+	v, _ := a.values[name].(time.Duration)
+	return v
+}
+
+// Strings returns the values collected by a variadic argument.
+func (a Args) Strings(name string) []string {
+	v, _ := a.values[name].([]string)
+	return v
+}
+
+// parseArgs parses the words following the command path. Errors are
+// worded with m.
+func (c *Command) parseArgs(words []string, m Messages) (Args, error) {
+	args := Args{values: make(map[string]interface{})}
+	for _, f := range c.Flags {
+		if f.Default == "" {
+			continue
+		}
+		v, err := convertArg(f.Type, f.Default, m)
+		if err != nil {
+			return args, err
+		}
+		args.values[f.Name] = v
+	}
+
+	var positional []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if word == "--" {
+			positional = append(positional, words[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(word, "--") || len(word) == 2 {
+			positional = append(positional, word)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(word[2:], "=")
+		flag := c.flag(name)
+		if flag == nil {
+			return args, errors.New(formatMessage(m.UnknownFlag, name))
+		}
+		if !hasValue {
+			if flag.Type == ArgBool {
+				value = "true"
+			} else if i+1 < len(words) {
+				i++
+				value = words[i]
+			} else {
+				return args, errors.New(formatMessage(m.FlagValueRequired, name))
+			}
+		}
+		v, err := convertArg(flag.Type, value, m)
+		if err != nil {
+			return args, fmt.Errorf("--%s: %w", name, err)
+		}
+		args.values[name] = v
+	}
+
+	for _, arg := range c.Args {
+		if arg.Variadic {
+			if len(positional) == 0 && !arg.Optional {
+				return args, errors.New(formatMessage(m.ArgRequired, arg.Name))
+			}
+			if len(positional) > 0 {
+				args.values[arg.Name] = positional
+			}
+			positional = nil
+			break
+		}
+		if len(positional) == 0 {
+			if !arg.Optional {
+				return args, errors.New(formatMessage(m.ArgRequired, arg.Name))
+			}
+			continue
+		}
+		v, err := convertArg(arg.Type, positional[0], m)
+		if err != nil {
+			return args, fmt.Errorf("%s: %w", arg.Name, err)
+		}
+		args.values[arg.Name] = v
+		positional = positional[1:]
+	}
+	if len(positional) > 0 {
+		return args, errors.New(formatMessage(m.TooManyArgs, strings.Join(positional, " ")))
+	}
+	return args, nil
+}
+
+func (c *Command) flag(name string) *CommandFlag {
+	for i := range c.Flags {
+		if c.Flags[i].Name == name {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+func convertArg(t ArgType, s string, m Messages) (interface{}, error) {
+	switch t {
+	case ArgInt:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.New(formatMessage(m.InvalidInt, s))
+		}
+		return n, nil
+	case ArgBool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.New(formatMessage(m.InvalidBool, s))
+		}
+		return b, nil
+	case ArgDuration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, errors.New(formatMessage(m.InvalidDuration, s))
+		}
+		return d, nil
+	default:
+		return s, nil
+	}
+}
+
+// parseCommandSpec parses a spec such as "deploy <service> [--env=prod]".
+func parseCommandSpec(spec string) (*Command, error) {
+	cmd := &Command{Spec: strings.Join(strings.Fields(spec), " ")}
+	for _, token := range strings.Fields(spec) {
+		switch {
+		case strings.HasPrefix(token, "[--") && strings.HasSuffix(token, "]"):
+			flag, err := parseFlagSpec(token[3 : len(token)-1])
+			if err != nil {
+				return nil, err
+			}
+			cmd.Flags = append(cmd.Flags, flag)
+
+		case strings.HasPrefix(token, "<") && strings.HasSuffix(token, ">"),
+			strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
+			if n := len(cmd.Args); n > 0 && cmd.Args[n-1].Variadic {
+				return nil, fmt.Errorf("argument after variadic argument %s", cmd.Args[n-1].Name)
+			}
+			arg := CommandArg{Optional: token[0] == '['}
+			name := token[1 : len(token)-1]
+			if strings.HasSuffix(name, "...") {
+				arg.Variadic = true
+				name = strings.TrimSuffix(name, "...")
+			}
+			name, typ, _ := strings.Cut(name, ":")
+			t, err := parseArgType(typ)
+			if err != nil {
+				return nil, err
+			}
+			if name == "" {
+				return nil, fmt.Errorf("empty argument name in %s", token)
+			}
+			arg.Name, arg.Type = name, t
+			cmd.Args = append(cmd.Args, arg)
+
+		default:
+			if len(cmd.Args) > 0 || len(cmd.Flags) > 0 {
+				return nil, fmt.Errorf("command word %q after arguments", token)
+			}
+			cmd.Path = append(cmd.Path, token)
+		}
+	}
+	if len(cmd.Path) == 0 {
+		return nil, fmt.Errorf("missing command name")
+	}
+	return cmd, nil
+}
+
+// parseFlagSpec parses the inside of [--name:type=default].
+func parseFlagSpec(s string) (CommandFlag, error) {
+	nameType, def, hasDefault := strings.Cut(s, "=")
+	name, typ, _ := strings.Cut(nameType, ":")
+	if name == "" {
+		return CommandFlag{}, fmt.Errorf("empty flag name in [--%s]", s)
+	}
+
+	flag := CommandFlag{Name: name, Default: def}
+	switch {
+	case typ != "":
+		t, err := parseArgType(typ)
+		if err != nil {
+			return flag, err
+		}
+		flag.Type = t
+	case hasDefault:
+		flag.Type = ArgString
+	default:
+		flag.Type = ArgBool
+	}
+	if def != "" {
+		if _, err := convertArg(flag.Type, def, DefaultMessages); err != nil {
+			return flag, fmt.Errorf("invalid default for --%s: %v", name, err)
+		}
+	}
+	return flag, nil
+}
+
+func parseArgType(s string) (ArgType, error) {
+	switch ArgType(s) {
+	case "", ArgString:
+		return ArgString, nil
+	case ArgInt, ArgBool, ArgDuration:
+		return ArgType(s), nil
+	}
+	return "", fmt.Errorf("unknown argument type %q", s)
+}
+
+// splitCommandLine splits s into words, keeping quoted text together.
+func splitCommandLine(s string) ([]string, error) {
+	var words []string
+	var b strings.Builder
+	var quote rune
+	inWord := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '"' || r == '\'' || r == '“' || r == '”':
+			quote = r
+			if r == '“' {
+				quote = '”'
+			}
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, b.String())
+				b.Reset()
+				inWord = false
+			}
+		default:
+			b.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, b.String())
+	}
+	return words, nil
+}
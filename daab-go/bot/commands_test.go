@@ -0,0 +1,250 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// sentTexts returns the texts of create_message calls received by the mock server.
+func sentTexts(mockServer *testutil.MockServer) []string {
+	var texts []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if len(msg) >= 4 && msg[2] == "create_message" {
+			params := msg[3].([]interface{})
+			if text, ok := params[2].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return texts
+}
+
+func TestParseCommandSpec(t *testing.T) {
+	cmd, err := parseCommandSpec("deploy rollback <service> [count:int] [--env=prod] [--dry-run] [--timeout:duration=30s]")
+	if err != nil {
+		t.Fatalf("parseCommandSpec failed: %v", err)
+	}
+	if strings.Join(cmd.Path, " ") != "deploy rollback" {
+		t.Errorf("Unexpected path %v", cmd.Path)
+	}
+	wantArgs := []CommandArg{
+		{Name: "service", Type: ArgString},
+		{Name: "count", Type: ArgInt, Optional: true},
+	}
+	if len(cmd.Args) != len(wantArgs) || cmd.Args[0] != wantArgs[0] || cmd.Args[1] != wantArgs[1] {
+		t.Errorf("Unexpected args %+v", cmd.Args)
+	}
+	wantFlags := []CommandFlag{
+		{Name: "env", Type: ArgString, Default: "prod"},
+		{Name: "dry-run", Type: ArgBool},
+		{Name: "timeout", Type: ArgDuration, Default: "30s"},
+	}
+	if len(cmd.Flags) != len(wantFlags) {
+		t.Fatalf("Unexpected flags %+v", cmd.Flags)
+	}
+	for i, f := range wantFlags {
+		if cmd.Flags[i] != f {
+			t.Errorf("Flag %d = %+v, want %+v", i, cmd.Flags[i], f)
+		}
+	}
+
+	for _, spec := range []string{
+		"",
+		"<service>",
+		"deploy <service> now",
+		"deploy <args...> <more>",
+		"deploy <n:float>",
+		"deploy [--n:int=abc]",
+	} {
+		if _, err := parseCommandSpec(spec); err == nil {
+			t.Errorf("Expected spec %q to be invalid", spec)
+		}
+	}
+}
+
+func TestCommandParseArgs(t *testing.T) {
+	cmd, _ := parseCommandSpec("deploy <service> [count:int] [--env=prod] [--dry-run] [--timeout:duration=30s]")
+
+	args, err := cmd.parseArgs([]string{"api", "3", "--env", "dev", "--dry-run"}, DefaultMessages)
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if args.String("service") != "api" || args.Int("count") != 3 || args.String("env") != "dev" ||
+		!args.Bool("dry-run") || args.Duration("timeout") != 30*time.Second {
+		t.Errorf("Unexpected args %+v", args.values)
+	}
+
+	args, err = cmd.parseArgs([]string{"--env=stg", "api"}, DefaultMessages)
+	if err != nil || args.String("env") != "stg" || args.Has("count") {
+		t.Errorf("Unexpected args %+v (err=%v)", args.values, err)
+	}
+
+	for _, words := range [][]string{
+		{},
+		{"api", "many"},
+		{"api", "1", "2"},
+		{"api", "--unknown"},
+		{"api", "--env"},
+		{"api", "--timeout=soon"},
+	} {
+		if _, err := cmd.parseArgs(words, DefaultMessages); err == nil {
+			t.Errorf("Expected %v to fail", words)
+		}
+	}
+
+	rest, _ := parseCommandSpec("say <words...>")
+	args, err = rest.parseArgs([]string{"hello", "--", "--world"}, DefaultMessages)
+	if err != nil || strings.Join(args.Strings("words"), " ") != "hello --world" {
+		t.Errorf("Unexpected variadic args %+v (err=%v)", args.values, err)
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	words, err := splitCommandLine(`deploy  "my service" '' --env=prod`)
+	if err != nil {
+		t.Fatalf("splitCommandLine failed: %v", err)
+	}
+	want := []string{"deploy", "my service", "", "--env=prod"}
+	if strings.Join(words, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, words)
+	}
+
+	if _, err := splitCommandLine(`say "hello`); err == nil {
+		t.Error("Expected unterminated quote to fail")
+	}
+}
+
+func TestCommandsRouting(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	commands := NewCommands(robot)
+
+	calls := make(chan string, 10)
+	commands.Command("deploy <service> [--env=prod]", "デプロイします", func(ctx context.Context, res Response, args Args) {
+		calls <- "deploy " + args.String("service") + " " + args.String("env")
+	})
+	commands.Command("deploy rollback <service>", "ロールバックします", func(ctx context.Context, res Response, args Args) {
+		calls <- "rollback " + args.String("service")
+	})
+	commands.Command("shutdown", "停止します", func(ctx context.Context, res Response, args Args) {
+		calls <- "shutdown"
+	}, RequireUsers("admin"))
+
+	send := func(userID, text string) {
+		robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "t1", UserID: userID, Text: text})
+	}
+
+	send("u1", "@daabgo deploy api")
+	send("u1", "daabgo DEPLOY rollback api")
+	send("admin", "daabgo shutdown")
+	got := waitCalls(t, calls, 3)
+	want := map[string]bool{"deploy api prod": true, "rollback api": true, "shutdown": true}
+	for _, call := range got {
+		if !want[call] {
+			t.Errorf("Unexpected call %q", call)
+		}
+	}
+
+	// Usage errors, permission errors and help are sent to the talk.
+	send("u1", "daabgo deploy")
+	waitSent(t, mockServer, 1)
+	send("u1", "daabgo shutdown")
+	waitSent(t, mockServer, 2)
+	send("u1", "daabgo help")
+	waitSent(t, mockServer, 3)
+	send("admin", "daabgo help deploy")
+	waitSent(t, mockServer, 4)
+
+	texts := sentTexts(mockServer)
+	joined := strings.Join(texts, "\n---\n")
+	if !strings.Contains(joined, "使い方: deploy <service> [--env=prod]") {
+		t.Errorf("Expected usage error, got %q", joined)
+	}
+	if !strings.Contains(joined, "権限がありません") {
+		t.Errorf("Expected permission error, got %q", joined)
+	}
+	for _, text := range texts {
+		if strings.HasPrefix(text, "コマンド一覧") && strings.Contains(text, "shutdown") {
+			t.Errorf("Expected help to hide commands the user cannot run, got %q", text)
+		}
+	}
+
+	// Unknown commands are left to other listeners.
+	send("u1", "daabgo unknown")
+	select {
+	case call := <-calls:
+		t.Errorf("Unexpected call %q", call)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if n := mockServer.GetCallCount("create_message"); n != 4 {
+		t.Errorf("Expected no reply to an unknown command, got %d messages", n)
+	}
+}
+
+func TestCommandsMessages(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	WithMessages(Messages{
+		HelpHeader:       "Commands:",
+		PermissionDenied: "Permission denied.",
+		Usage:            "Usage: %s",
+		ArgRequired:      "%s is required",
+	})(robot)
+	commands := NewCommands(robot)
+	commands.Command("deploy <service>", "Deploys a service", func(ctx context.Context, res Response, args Args) {})
+	commands.Command("shutdown", "Shuts down", func(ctx context.Context, res Response, args Args) {}, RequireUsers("admin"))
+
+	send := func(text string) {
+		robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "t1", UserID: "u1", Text: text})
+	}
+	send("daabgo deploy")
+	waitSent(t, mockServer, 1)
+	send("daabgo shutdown")
+	waitSent(t, mockServer, 2)
+	send("daabgo help")
+	waitSent(t, mockServer, 3)
+
+	joined := strings.Join(sentTexts(mockServer), "\n---\n")
+	for _, want := range []string{"service is required\nUsage: deploy <service>", "Permission denied.", "Commands:\nhelp [command...] - コマンドの一覧を表示します"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected %q, got %q", want, joined)
+		}
+	}
+}
+
+func TestCommandsMessagesWithoutVerb(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	WithMessages(Messages{Usage: "Usage:", ArgRequired: "Missing argument."})(robot)
+	commands := NewCommands(robot)
+	commands.Command("deploy <service>", "Deploys a service", func(ctx context.Context, res Response, args Args) {})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "t1", UserID: "u1", Text: "daabgo deploy"})
+	waitSent(t, mockServer, 1)
+
+	if got := sentTexts(mockServer); len(got) != 1 || got[0] != "Missing argument.\nUsage:" {
+		t.Errorf("Expected the overrides as is, got %q", got)
+	}
+}
+
+func TestCommandsSingleHelp(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	commands := NewCommands(robot)
+	commands.Command("deploy <service>", "デプロイします", func(ctx context.Context, res Response, args Args) {})
+	robot.Commands().Command("plugins", "プラグインの一覧", func(ctx context.Context, res Response, args Args) {})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "t1", UserID: "u1", Text: "daabgo help"})
+	waitSent(t, mockServer, 1)
+	time.Sleep(20 * time.Millisecond)
+	texts := sentTexts(mockServer)
+	if len(texts) != 1 {
+		t.Fatalf("Expected one help reply, got %q", texts)
+	}
+	for _, want := range []string{"help [command...]", "deploy <service>", "plugins"} {
+		if !strings.Contains(texts[0], want) {
+			t.Errorf("Expected %q in help, got %q", want, texts[0])
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the configuration file read by the daabgo CLI when
+// no other file is given.
+const DefaultConfigFile = "daabgo.yaml"
+
+// Config is the declarative configuration of a robot, usually read from
+// daabgo.yaml.
+//
+// Settings are resolved with the precedence command-line flags >
+// environment variables > configuration file > defaults: start from
+// DefaultConfig, merge the file with LoadConfigFile, apply the environment
+// with ApplyEnv and finally set fields from flags. LoadConfig does all but
+// the last step.
+type Config struct {
+	// Name is the robot name (env DAABGO_NAME).
+	Name string `yaml:"name"`
+
+	// Token is the access token (env HUBOT_DIRECT_TOKEN). Prefer
+	// "${HUBOT_DIRECT_TOKEN}" or TokenEnv over writing the token in the file.
+	Token string `yaml:"token,omitempty"`
+
+	// TokenEnv names an environment variable holding the access token.
+	TokenEnv string `yaml:"token_env,omitempty"`
+
+	// Endpoint is the WebSocket API endpoint (env HUBOT_DIRECT_ENDPOINT).
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// ProxyURL is the proxy for connections (env HUBOT_DIRECT_PROXY_URL).
+	ProxyURL string `yaml:"proxy_url,omitempty"`
+
+	// BrainFile is the path of a FileBrain (env DAABGO_BRAIN_FILE).
+	// The default is an in-memory brain.
+	BrainFile string `yaml:"brain_file,omitempty"`
+
+	// GracePeriod is how long shutdown waits for handlers; see WithGracePeriod.
+	GracePeriod time.Duration `yaml:"grace_period"`
+
+	// Send configures the outgoing message queue.
+	Send SendConfig `yaml:"send"`
+
+	// Debug configures direct-go debug logging.
+	Debug DebugConfig `yaml:"debug"`
+
+	// Webhook configures forwarding to an external webhook such as n8n.
+	Webhook WebhookConfig `yaml:"webhook"`
+
+	// Metrics configures the metrics endpoint.
+	Metrics MetricsConfig `yaml:"metrics"`
+
+	// Plugins holds the per-plugin sections passed to plugins; see PluginConfig.
+	Plugins PluginSettings `yaml:"plugins,omitempty"`
+}
+
+// SendConfig configures the outgoing message queue; see WithGlobalRateLimit,
+// WithTalkRateLimit and WithSendRetries. A rate of 0 disables the limit.
+type SendConfig struct {
+	GlobalRate  float64       `yaml:"global_rate"`
+	GlobalBurst int           `yaml:"global_burst"`
+	TalkRate    float64       `yaml:"talk_rate"`
+	TalkBurst   int           `yaml:"talk_burst"`
+	Retries     int           `yaml:"retries"`
+	Backoff     time.Duration `yaml:"backoff"`
+}
+
+// DebugConfig configures direct-go debug logging.
+type DebugConfig struct {
+	// Level is 0 (off), 1 (normal) or 2 (verbose) (env DIRECT_DEBUG).
+	Level int `yaml:"level"`
+
+	// Server is the URL of a debug log server (env DEBUG_SERVER).
+	Server string `yaml:"server,omitempty"`
+}
+
+// WebhookConfig configures forwarding to an external webhook; see the
+// webhook package.
+type WebhookConfig struct {
+	// URL receives the forwarded events (env N8N_WEBHOOK_URL).
+	URL string `yaml:"url,omitempty"`
+
+	// Secret signs the requests with HMAC-SHA256 (env N8N_WEBHOOK_SECRET).
+	Secret string `yaml:"secret,omitempty"`
+
+	// Timeout is the timeout of each request.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retries is how many times requests failing with a network error or a
+	// 5xx status are retried, waiting Backoff before the first retry and
+	// twice as long before each next one.
+	Retries int           `yaml:"retries"`
+	Backoff time.Duration `yaml:"backoff"`
+
+	// DeadLetterDir keeps the events that could not be delivered, to be
+	// sent again with "daabgo webhook replay" (env N8N_WEBHOOK_DEAD_LETTER_DIR).
+	DeadLetterDir string `yaml:"dead_letter_dir,omitempty"`
+}
+
+// MetricsConfig configures the metrics endpoint; see WithMetricsAddr.
+type MetricsConfig struct {
+	// Addr serves /metrics on an address such as ":9090"
+	// (env DAABGO_METRICS_ADDR). Metrics are disabled if it is empty.
+	Addr string `yaml:"addr,omitempty"`
+}
+
+// DefaultConfig returns the configuration used when nothing is set.
+func DefaultConfig() *Config {
+	return &Config{
+		Name:        "daabgo",
+		Endpoint:    direct.DefaultEndpoint,
+		GracePeriod: DefaultGracePeriod,
+		Send: SendConfig{
+			GlobalRate:  DefaultGlobalRate,
+			GlobalBurst: DefaultGlobalBurst,
+			TalkRate:    DefaultTalkRate,
+			TalkBurst:   DefaultTalkBurst,
+			Retries:     DefaultSendRetries,
+			Backoff:     DefaultSendBackoff,
+		},
+		// The defaults of webhook.NewClient.
+		Webhook: WebhookConfig{
+			Timeout: 10 * time.Second,
+			Retries: 3,
+			Backoff: time.Second,
+		},
+	}
+}
+
+// LoadConfig returns the defaults overridden by the file at path, if path
+// is not empty, and then by the environment. The result is validated.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		if err := cfg.LoadConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadConfigFile merges the YAML file at path into c. Settings missing from
+// the file keep their current values. "${VAR}" and "${VAR:-default}" in
+// values are replaced by environment variables after parsing, so the
+// variables cannot change the structure of the file, and unknown keys are
+// reported as errors.
+func (c *Config) LoadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		return nil // empty file
+	}
+	expandEnvNode(&doc)
+	// Encode the expanded document again, as only the Decoder reports
+	// unknown keys.
+	data, err = yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// configEnv lists the environment variables read by ApplyEnv.
+var configEnv = []struct {
+	key   string
+	apply func(c *Config, v string) error
+}{
+	{"DAABGO_NAME", func(c *Config, v string) error { c.Name = v; return nil }},
+	{direct.TokenEnvKey, func(c *Config, v string) error {
+		if c.TokenEnv == "" {
+			c.Token = v
+		}
+		return nil
+	}},
+	{"HUBOT_DIRECT_ENDPOINT", func(c *Config, v string) error { c.Endpoint = v; return nil }},
+	{"HUBOT_DIRECT_PROXY_URL", func(c *Config, v string) error { c.ProxyURL = v; return nil }},
+	{"DAABGO_BRAIN_FILE", func(c *Config, v string) error { c.BrainFile = v; return nil }},
+	{"DIRECT_DEBUG", func(c *Config, v string) error {
+		if v == "true" {
+			c.Debug.Level = 1
+			return nil
+		}
+		level, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		c.Debug.Level = level
+		return nil
+	}},
+	{"DEBUG_SERVER", func(c *Config, v string) error { c.Debug.Server = v; return nil }},
+	{"N8N_WEBHOOK_URL", func(c *Config, v string) error { c.Webhook.URL = v; return nil }},
+	{"N8N_WEBHOOK_SECRET", func(c *Config, v string) error { c.Webhook.Secret = v; return nil }},
+	{"N8N_WEBHOOK_DEAD_LETTER_DIR", func(c *Config, v string) error { c.Webhook.DeadLetterDir = v; return nil }},
+	{"DAABGO_METRICS_ADDR", func(c *Config, v string) error { c.Metrics.Addr = v; return nil }},
+}
+
+// ApplyEnv overrides c with the environment variables that are set and not
+// empty. Plugin settings are read from the environment by PluginConfig.
+func (c *Config) ApplyEnv() error {
+	for _, e := range configEnv {
+		v := os.Getenv(e.key)
+		if v == "" {
+			continue
+		}
+		if err := e.apply(c, v); err != nil {
+			return fmt.Errorf("daab: invalid %s %q: %w", e.key, v, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks c and reports every invalid setting.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Name == "" {
+		invalid("name", "must not be empty")
+	}
+	if u, err := url.Parse(c.Endpoint); c.Endpoint != "" && (err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "") {
+		invalid("endpoint", "must be a ws:// or wss:// URL, got %q", c.Endpoint)
+	}
+	if u, err := url.Parse(c.ProxyURL); c.ProxyURL != "" && (err != nil || u.Scheme == "" || u.Host == "") {
+		invalid("proxy_url", "must be a URL, got %q", c.ProxyURL)
+	}
+	if u, err := url.Parse(c.Webhook.URL); c.Webhook.URL != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https")) {
+		invalid("webhook.url", "must be an http:// or https:// URL, got %q", c.Webhook.URL)
+	}
+	if c.Webhook.Timeout < 0 || c.Webhook.Retries < 0 || c.Webhook.Backoff < 0 {
+		invalid("webhook", "timeout, retries and backoff must not be negative")
+	}
+	if c.GracePeriod < 0 {
+		invalid("grace_period", "must not be negative")
+	}
+	if c.Send.GlobalRate < 0 || c.Send.TalkRate < 0 {
+		invalid("send", "rates must not be negative")
+	}
+	if c.Send.GlobalBurst < 0 || c.Send.TalkBurst < 0 {
+		invalid("send", "bursts must not be negative")
+	}
+	if c.Send.Retries < 0 || c.Send.Backoff < 0 {
+		invalid("send", "retries and backoff must not be negative")
+	}
+	if c.Debug.Level < 0 || c.Debug.Level > 2 {
+		invalid("debug.level", "must be 0, 1 or 2, got %d", c.Debug.Level)
+	}
+	names := make([]string, 0, len(c.Plugins))
+	for name := range c.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section := c.Plugins[name]
+		if name == "" {
+			invalid("plugins", "plugin name must not be empty")
+		}
+		if v, ok := section["enabled"]; ok {
+			if _, isBool := v.(bool); !isBool {
+				invalid("plugins."+name+".enabled", "must be true or false")
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("daab: invalid config:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Options converts c into Robot options. The debug settings are not
+// options, as they are process-wide; apply them with ApplyDebug.
+func (c *Config) Options() []Option {
+	opts := []Option{
+		WithName(c.Name),
+		WithGracePeriod(c.GracePeriod),
+		WithGlobalRateLimit(c.Send.GlobalRate, c.Send.GlobalBurst),
+		WithTalkRateLimit(c.Send.TalkRate, c.Send.TalkBurst),
+		WithSendRetries(c.Send.Retries, c.Send.Backoff),
+		WithPluginSettings(c.Plugins),
+	}
+	if c.Token != "" {
+		opts = append(opts, WithToken(c.Token))
+	} else if c.TokenEnv != "" {
+		opts = append(opts, WithTokenEnv(c.TokenEnv))
+	}
+	if c.Endpoint != "" {
+		opts = append(opts, WithEndpoint(c.Endpoint))
+	}
+	if c.ProxyURL != "" {
+		opts = append(opts, WithProxy(c.ProxyURL))
+	}
+	if c.BrainFile != "" {
+		opts = append(opts, WithBrain(NewFileBrain(c.BrainFile)))
+	}
+	if c.Metrics.Addr != "" {
+		opts = append(opts, WithMetricsAddr(c.Metrics.Addr))
+	}
+	return opts
+}
+
+// ApplyDebug applies the debug settings to direct-go. They affect every
+// client in the process, so call it once from main rather than per robot.
+func (c *Config) ApplyDebug() {
+	if c.Debug.Level > 0 {
+		direct.SetDebugLevel(c.Debug.Level)
+	}
+	if c.Debug.Server != "" {
+		direct.EnableDebugServer(c.Debug.Server)
+	}
+}
+
+// Redacted returns a copy of c with secrets masked, for display: the access
+// token, the webhook secret and URL, the proxy URL and the plugin settings
+// named "token", "secret" or "url" or ending in "_token", "_secret" or
+// "_url". Plugin settings are deep-copied, so c is left unchanged.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	for _, s := range []*string{&cp.Token, &cp.ProxyURL, &cp.Webhook.URL, &cp.Webhook.Secret} {
+		if *s != "" {
+			*s = redactedValue
+		}
+	}
+	if c.Plugins != nil {
+		cp.Plugins = make(PluginSettings, len(c.Plugins))
+		for name, section := range c.Plugins {
+			cp.Plugins[name] = redactSettings(section)
+		}
+	}
+	return &cp
+}
+
+const redactedValue = "********"
+
+// isSecretKey reports whether the plugin setting key holds a secret.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, name := range []string{"token", "secret", "url"} {
+		if key == name || strings.HasSuffix(key, "_"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSettings returns a copy of values with the secret settings masked,
+// including those of nested sections.
+func redactSettings(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	cp := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if isSecretKey(k) && v != nil {
+			cp[k] = redactedValue
+			continue
+		}
+		cp[k] = redactValue(v)
+	}
+	return cp
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return redactSettings(v)
+	case []interface{}:
+		cp := make([]interface{}, len(v))
+		for i, item := range v {
+			cp[i] = redactValue(item)
+		}
+		return cp
+	}
+	return v
+}
+
+// YAML returns c encoded as YAML.
+func (c *Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv replaces "${VAR}" in s with the value of the environment
+// variable VAR, and "${VAR:-default}" with default if VAR is unset or empty.
+// A bare "$VAR" is left untouched.
+func expandEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRef.FindStringSubmatch(ref)
+		if v := os.Getenv(m[1]); v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// expandEnvNode expands the environment variables in the scalar values
+// under n. Mapping keys are left as written. A plain scalar gets the type of
+// its expanded value, so "${PORT}" can set a number, while a quoted one
+// stays a string.
+func expandEnvNode(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			expandEnvNode(n.Content[i])
+		}
+	case yaml.ScalarNode:
+		v := expandEnv(n.Value)
+		if v == n.Value {
+			return
+		}
+		n.Value = v
+		if n.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			n.Tag = "" // resolved again from the value
+		}
+	default:
+		for _, child := range n.Content {
+			expandEnvNode(child)
+		}
+	}
+}
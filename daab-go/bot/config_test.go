@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultConfigFile)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("DAABGO_NAME", "")
+	t.Setenv("HUBOT_DIRECT_TOKEN", "")
+	t.Setenv("HUBOT_DIRECT_ENDPOINT", "")
+	t.Setenv("N8N_WEBHOOK_URL", "")
+	t.Setenv("DAABGO_METRICS_ADDR", "")
+	t.Setenv("MY_TOKEN", "secret")
+	path := writeConfig(t, `
+name: reportbot
+token: ${MY_TOKEN}
+proxy_url: ${PROXY:-http://proxy.example.com:8080}
+grace_period: 30s
+send:
+  talk_rate: 1
+  talk_burst: 3
+metrics:
+  addr: :9090
+plugins:
+  remind:
+    location: Asia/Tokyo
+`)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Name != "reportbot" || cfg.Token != "secret" || cfg.ProxyURL != "http://proxy.example.com:8080" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if cfg.GracePeriod != 30*time.Second || cfg.Send.TalkRate != 1 || cfg.Send.TalkBurst != 3 {
+		t.Errorf("Unexpected durations or limits %+v", cfg)
+	}
+	// Settings missing from the file keep their defaults.
+	if cfg.Send.GlobalRate != DefaultGlobalRate || cfg.Endpoint == "" {
+		t.Errorf("Expected defaults for unset settings, got %+v", cfg.Send)
+	}
+	if NewPluginConfig("remind", cfg.Plugins["remind"]).String("location", "") != "Asia/Tokyo" {
+		t.Errorf("Unexpected plugin settings %v", cfg.Plugins)
+	}
+
+	// The environment overrides the file.
+	t.Setenv("DAABGO_NAME", "envbot")
+	t.Setenv("N8N_WEBHOOK_URL", "https://n8n.example.com/webhook/x")
+	cfg, err = LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Name != "envbot" || cfg.Webhook.URL != "https://n8n.example.com/webhook/x" {
+		t.Errorf("Expected environment to override the file, got %+v", cfg)
+	}
+
+	robot := New(cfg.Options()...)
+	if robot.Name != "envbot" || robot.Token != "secret" || robot.gracePeriod != 30*time.Second {
+		t.Errorf("Unexpected robot from config: %s %s %v", robot.Name, robot.Token, robot.gracePeriod)
+	}
+	if robot.metricsAddr != ":9090" || robot.Metrics() == nil {
+		t.Errorf("Expected metrics on :9090, got %q", robot.metricsAddr)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Setenv("DIRECT_DEBUG", "")
+	if _, err := LoadConfig(writeConfig(t, "nmae: typo\n")); err == nil || !strings.Contains(err.Error(), "nmae") {
+		t.Errorf("Expected unknown key error, got %v", err)
+	}
+
+	_, err := LoadConfig(writeConfig(t, `
+name: ""
+endpoint: https://example.com
+grace_period: -1s
+debug:
+  level: 5
+plugins:
+  ping:
+    enabled: "no"
+`))
+	if err == nil {
+		t.Fatal("Expected validation to fail")
+	}
+	for _, want := range []string{"name:", "endpoint:", "grace_period:", "debug.level:", "plugins.ping.enabled:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+
+	t.Setenv("DIRECT_DEBUG", "loud")
+	if _, err := LoadConfig(""); err == nil || !strings.Contains(err.Error(), "DIRECT_DEBUG") {
+		t.Errorf("Expected invalid environment variable error, got %v", err)
+	}
+}
+
+func TestConfigYAML(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Token = "secret"
+	cfg.Webhook.Secret = "hmac-key"
+	cfg.Plugins = PluginSettings{
+		"httpapi": {"addr": ":8080", "token": "api-token", "hmac_secret": "api-hmac"},
+		"bridge":  {"slack_url": "https://hooks.example.com/x", "talks": []interface{}{"1"}},
+		"hooks":   {"hooks": []interface{}{map[string]interface{}{"name": "ci", "secret": "hook-secret"}}},
+	}
+	data, err := cfg.Redacted().YAML()
+	if err != nil {
+		t.Fatalf("YAML failed: %v", err)
+	}
+	if strings.Contains(string(data), "secret\n") || strings.Contains(string(data), "hmac-key") || !strings.Contains(string(data), "grace_period: 10s") {
+		t.Errorf("Unexpected YAML:\n%s", data)
+	}
+	for _, secret := range []string{"api-token", "api-hmac", "hooks.example.com", "hook-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %q to be masked:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "addr: :8080") || !strings.Contains(string(data), "name: ci") {
+		t.Errorf("Expected other plugin settings to be kept:\n%s", data)
+	}
+	if cfg.Token != "secret" || cfg.Plugins["httpapi"]["token"] != "api-token" || cfg.Plugins["bridge"]["slack_url"] == redactedValue {
+		t.Error("Expected Redacted not to modify the config")
+	}
+
+	// The output can be read back.
+	path := writeConfig(t, string(data))
+	loaded := DefaultConfig()
+	if err := loaded.LoadConfigFile(path); err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if loaded.GracePeriod != cfg.GracePeriod || loaded.Send != cfg.Send {
+		t.Errorf("Expected round trip, got %+v", loaded)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("A", "1")
+	t.Setenv("EMPTY", "")
+	got := expandEnv("${A} ${EMPTY:-x} ${MISSING} $A ${B:-a b}")
+	if got != "1 x  $A a b" {
+		t.Errorf("expandEnv() = %q", got)
+	}
+}
+
+func TestLoadConfigFileExpandsValues(t *testing.T) {
+	t.Setenv("MY_TOKEN", "abc#def: x\nname: evil")
+	t.Setenv("GRACE", "5s")
+	t.Setenv("LEVEL", "2")
+	path := writeConfig(t, `
+name: bot
+token: ${MY_TOKEN}
+grace_period: ${GRACE}
+debug:
+  level: ${LEVEL}
+plugins:
+  ping:
+    reply: "${LEVEL}"
+`)
+	cfg := DefaultConfig()
+	if err := cfg.LoadConfigFile(path); err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if cfg.Token != "abc#def: x\nname: evil" || cfg.Name != "bot" {
+		t.Errorf("Expected the token to be kept whole, got token %q and name %q", cfg.Token, cfg.Name)
+	}
+	if cfg.GracePeriod != 5*time.Second || cfg.Debug.Level != 2 {
+		t.Errorf("Expected typed values, got %v and %d", cfg.GracePeriod, cfg.Debug.Level)
+	}
+	if v := cfg.Plugins["ping"]["reply"]; v != "2" {
+		t.Errorf("Expected a quoted value to stay a string, got %#v", v)
+	}
+}
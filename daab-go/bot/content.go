@@ -0,0 +1,152 @@
+package bot
+
+import (
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// OnStamp registers a listener for stamp messages.
+// Use Response.Stamp to get the decoded stamp.
+func (r *Robot) OnStamp(handler Handler, opts ...ListenerOption) {
+	r.addTypedListener("stamp", handler, opts, func(msg direct.ReceivedMessage) bool {
+		_, ok := stampOf(msg)
+		return ok
+	})
+}
+
+// OnFile registers a listener for file messages, including text with files.
+// Use Response.Files to get the decoded attachments.
+func (r *Robot) OnFile(handler Handler, opts ...ListenerOption) {
+	r.addTypedListener("file", handler, opts, func(msg direct.ReceivedMessage) bool {
+		return len(filesOf(msg)) > 0
+	})
+}
+
+// OnLocation registers a listener for location messages.
+// Use Response.Location to get the decoded location.
+func (r *Robot) OnLocation(handler Handler, opts ...ListenerOption) {
+	r.addTypedListener("location", handler, opts, func(msg direct.ReceivedMessage) bool {
+		_, ok := locationOf(msg)
+		return ok
+	})
+}
+
+// OnSelectReply registers a listener for answers to the select action stamp
+// with message ID questionID, or to any select stamp if questionID is "".
+// Use Response.SelectReply to get the decoded answer.
+func (r *Robot) OnSelectReply(questionID string, handler Handler, opts ...ListenerOption) {
+	r.addTypedListener("select_reply", handler, opts, func(msg direct.ReceivedMessage) bool {
+		reply, ok := selectReplyOf(msg)
+		return ok && (questionID == "" || reply.InReplyTo == questionID)
+	})
+}
+
+// OnYesNoReply registers a listener for answers to the yes/no action stamp
+// with message ID questionID, or to any yes/no stamp if questionID is "".
+// Use Response.YesNoReply to get the decoded answer.
+func (r *Robot) OnYesNoReply(questionID string, handler Handler, opts ...ListenerOption) {
+	r.addTypedListener("yesno_reply", handler, opts, func(msg direct.ReceivedMessage) bool {
+		reply, ok := yesNoReplyOf(msg)
+		return ok && (questionID == "" || reply.InReplyTo == questionID)
+	})
+}
+
+// OnTaskDone registers a listener for completions of the task action stamp
+// with message ID taskID, or of any task if taskID is "".
+// Use Response.TaskDone to get the decoded completion.
+func (r *Robot) OnTaskDone(taskID string, handler Handler, opts ...ListenerOption) {
+	r.addTypedListener("task_done", handler, opts, func(msg direct.ReceivedMessage) bool {
+		done, ok := taskDoneOf(msg)
+		return ok && (taskID == "" || done.InReplyTo == taskID)
+	})
+}
+
+func (r *Robot) addTypedListener(name string, handler Handler, opts []ListenerOption, accept func(direct.ReceivedMessage) bool) {
+	r.addListener(&Listener{
+		Handler: handler,
+		Name:    name,
+		match: func(msg direct.ReceivedMessage) ([]string, bool) {
+			if !accept(msg) {
+				return nil, false
+			}
+			return []string{msg.Text}, true
+		},
+	}, opts)
+}
+
+// Stamp returns the decoded stamp, or nil if the message is not a stamp.
+func (r Response) Stamp() *direct.StampContent {
+	stamp, _ := stampOf(r.Message)
+	return stamp
+}
+
+// Files returns the decoded attachments, or nil if the message has no files.
+func (r Response) Files() []direct.FileContent {
+	return filesOf(r.Message)
+}
+
+// Location returns the decoded location, or nil if the message is not a location.
+func (r Response) Location() *direct.LocationContent {
+	loc, _ := locationOf(r.Message)
+	return loc
+}
+
+// SelectReply returns the decoded select answer, or nil if the message is not one.
+func (r Response) SelectReply() *direct.SelectReplyContent {
+	reply, _ := selectReplyOf(r.Message)
+	return reply
+}
+
+// YesNoReply returns the decoded yes/no answer, or nil if the message is not one.
+func (r Response) YesNoReply() *direct.YesNoReplyContent {
+	reply, _ := yesNoReplyOf(r.Message)
+	return reply
+}
+
+// TaskDone returns the decoded task completion, or nil if the message is not one.
+func (r Response) TaskDone() *direct.TaskDoneContent {
+	done, _ := taskDoneOf(r.Message)
+	return done
+}
+
+func stampOf(msg direct.ReceivedMessage) (*direct.StampContent, bool) {
+	if msg.Type.Internal() != direct.MessageTypeStamp {
+		return nil, false
+	}
+	return direct.ParseStampContent(msg.Content)
+}
+
+func filesOf(msg direct.ReceivedMessage) []direct.FileContent {
+	switch msg.Type.Internal() {
+	case direct.MessageTypeFile, direct.MessageTypeTextMultipleFile:
+		return direct.ParseFileContents(msg.Content)
+	}
+	return nil
+}
+
+func locationOf(msg direct.ReceivedMessage) (*direct.LocationContent, bool) {
+	if msg.Type.Internal() != direct.MessageTypeLocation {
+		return nil, false
+	}
+	return direct.ParseLocationContent(msg.Content)
+}
+
+func selectReplyOf(msg direct.ReceivedMessage) (*direct.SelectReplyContent, bool) {
+	if msg.Type.Internal() != direct.MessageTypeSelectReply {
+		return nil, false
+	}
+	return direct.ParseSelectReplyContent(msg.Content)
+}
+
+func yesNoReplyOf(msg direct.ReceivedMessage) (*direct.YesNoReplyContent, bool) {
+	if msg.Type.Internal() != direct.MessageTypeYesNoReply {
+		return nil, false
+	}
+	return direct.ParseYesNoReplyContent(msg.Content)
+}
+
+func taskDoneOf(msg direct.ReceivedMessage) (*direct.TaskDoneContent, bool) {
+	if msg.Type.Internal() != direct.MessageTypeTaskDone {
+		return nil, false
+	}
+	return direct.ParseTaskDoneContent(msg.Content)
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+func TestTypedListeners(t *testing.T) {
+	robot := New()
+	calls := make(chan string, 10)
+
+	robot.OnStamp(func(ctx context.Context, res Response) {
+		calls <- "stamp:" + res.Stamp().StampSet
+	})
+	robot.OnFile(func(ctx context.Context, res Response) {
+		calls <- "file:" + res.Files()[0].Name
+	})
+	robot.OnLocation(func(ctx context.Context, res Response) {
+		calls <- "location:" + res.Location().Place
+	})
+	robot.OnSelectReply("100", func(ctx context.Context, res Response) {
+		calls <- "select:" + res.SelectReply().Answer()
+	})
+	robot.OnYesNoReply("", func(ctx context.Context, res Response) {
+		if res.YesNoReply().Response {
+			calls <- "yesno:yes"
+		} else {
+			calls <- "yesno:no"
+		}
+	})
+	robot.OnTaskDone("", func(ctx context.Context, res Response) {
+		calls <- "task:" + res.TaskDone().Title
+	})
+
+	messages := []direct.ReceivedMessage{
+		{Type: direct.MessageTypeStamp, Content: map[string]interface{}{"stamp_set": uint64(3), "stamp_index": uint64(1)}},
+		{Type: direct.MessageTypeFile, Content: map[string]interface{}{"file_id": uint64(1), "name": "a.txt"}},
+		{Type: direct.MessageTypeLocation, Content: map[string]interface{}{"place": "Tokyo", "lat": 35.6, "lng": 139.7}},
+		{Type: direct.MessageType(direct.WireTypeSelectReply), Content: map[string]interface{}{
+			"options": []interface{}{"A", "B"}, "response": int8(1), "in_reply_to": uint64(100),
+		}},
+		// Answer to a different select stamp: ignored by OnSelectReply("100").
+		{Type: direct.MessageType(direct.WireTypeSelectReply), Content: map[string]interface{}{
+			"options": []interface{}{"A", "B"}, "response": int8(0), "in_reply_to": uint64(200),
+		}},
+		{Type: direct.MessageType(direct.WireTypeYesNoReply), Content: map[string]interface{}{"response": true, "in_reply_to": uint64(5)}},
+		{Type: direct.MessageType(direct.WireTypeTaskDone), Content: map[string]interface{}{"title": "Deploy", "done": true, "in_reply_to": uint64(6)}},
+		{Type: direct.MessageTypeText, Text: "plain text", Content: "plain text"},
+	}
+	for _, msg := range messages {
+		robot.handleMessage(context.Background(), msg)
+	}
+
+	got := map[string]bool{}
+	for _, v := range waitCalls(t, calls, 6) {
+		got[v] = true
+	}
+	for _, want := range []string{"stamp:3", "file:a.txt", "location:Tokyo", "select:B", "yesno:yes", "task:Deploy"} {
+		if !got[want] {
+			t.Errorf("Expected %s, got %v", want, got)
+		}
+	}
+
+	select {
+	case v := <-calls:
+		t.Errorf("Unexpected call: %s", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestResponseContentAccessorsOnText(t *testing.T) {
+	res := Response{Message: direct.ReceivedMessage{Type: direct.MessageTypeText, Content: "hi"}}
+
+	if res.Stamp() != nil || res.Files() != nil || res.Location() != nil {
+		t.Error("Expected nil content accessors for text message")
+	}
+	if res.SelectReply() != nil || res.YesNoReply() != nil || res.TaskDone() != nil {
+		t.Error("Expected nil reply accessors for text message")
+	}
+}
@@ -0,0 +1,346 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Errors returned by conversations.
+var (
+	// ErrConversationTimeout is returned when the user does not answer in time.
+	ErrConversationTimeout = errors.New("daab: conversation timed out")
+
+	// ErrConversationCancelled is returned when the user answers with a cancel word.
+	ErrConversationCancelled = errors.New("daab: conversation cancelled")
+
+	// ErrConversationBusy is returned when a question is already waiting for
+	// an answer from the same user in the same talk.
+	ErrConversationBusy = errors.New("daab: conversation already waiting for an answer")
+)
+
+// DefaultConversationTimeout is how long Ask waits for an answer by default.
+const DefaultConversationTimeout = 5 * time.Minute
+
+// DefaultCancelWords end a conversation with ErrConversationCancelled.
+var DefaultCancelWords = []string{"cancel", "キャンセル"}
+
+// Validator checks a text answer. A non-nil error is sent back to the user
+// as the reason and the conversation keeps waiting for a valid answer.
+type Validator func(answer string) error
+
+// OneOf accepts answers equal to one of choices, ignoring case. Other
+// answers are rejected with the OneOf text of the robot's Messages.
+func OneOf(choices ...string) Validator {
+	return func(answer string) error {
+		for _, c := range choices {
+			if strings.EqualFold(answer, c) {
+				return nil
+			}
+		}
+		return oneOfError{choices: choices}
+	}
+}
+
+// MatchPattern accepts answers matching the regular expression pattern.
+// Other answers are rejected with the NoMatch text of the robot's Messages.
+func MatchPattern(pattern string) Validator {
+	re := regexp.MustCompile(pattern)
+	return func(answer string) error {
+		if !re.MatchString(answer) {
+			return noMatchError{}
+		}
+		return nil
+	}
+}
+
+// validationError is an error of the built-in validators, worded with the
+// robot's Messages when sent to the user.
+type validationError interface {
+	error
+	message(m Messages) string
+}
+
+type oneOfError struct {
+	choices []string
+}
+
+func (e oneOfError) Error() string { return e.message(DefaultMessages) }
+
+func (e oneOfError) message(m Messages) string {
+	return formatMessage(m.OneOf, strings.Join(e.choices, " / "))
+}
+
+type noMatchError struct{}
+
+func (e noMatchError) Error() string { return e.message(DefaultMessages) }
+
+func (e noMatchError) message(m Messages) string { return m.NoMatch }
+
+// Conversation asks questions to one user in one talk and waits for the
+// answers. While a question is waiting, the user's answers in that talk are
+// delivered to the conversation instead of the robot's listeners.
+type Conversation struct {
+	robot       *Robot
+	talkID      string
+	userID      string
+	timeout     time.Duration
+	cancelWords []string
+}
+
+// ConversationOption configures a Conversation.
+type ConversationOption func(*Conversation)
+
+// WithConversationTimeout sets how long each question waits for an answer.
+func WithConversationTimeout(d time.Duration) ConversationOption {
+	return func(c *Conversation) {
+		c.timeout = d
+	}
+}
+
+// WithCancelWords replaces the words that cancel the conversation.
+// Calling it without words disables cancellation.
+func WithCancelWords(words ...string) ConversationOption {
+	return func(c *Conversation) {
+		c.cancelWords = words
+	}
+}
+
+// Conversation starts a conversation with userID in talkID.
+func (r *Robot) Conversation(talkID, userID string, opts ...ConversationOption) *Conversation {
+	c := &Conversation{
+		robot:       r,
+		talkID:      talkID,
+		userID:      userID,
+		timeout:     DefaultConversationTimeout,
+		cancelWords: DefaultCancelWords,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Conversation starts a conversation with the sender of the message.
+func (r Response) Conversation(opts ...ConversationOption) *Conversation {
+	return r.Robot.Conversation(r.Message.TalkID, r.Message.UserID, opts...)
+}
+
+// Send sends a text message to the conversation's talk.
+func (c *Conversation) Send(text string) error {
+	return c.robot.SendText(c.talkID, text)
+}
+
+// Ask sends prompt and returns the user's next text answer that passes all
+// validators. An empty prompt only waits for the answer.
+func (c *Conversation) Ask(ctx context.Context, prompt string, validators ...Validator) (string, error) {
+	w, err := c.robot.conversations.add(c.talkID, c.userID, func(msg direct.ReceivedMessage) bool {
+		return msg.Type.Internal() == direct.MessageTypeText
+	})
+	if err != nil {
+		return "", err
+	}
+	defer c.robot.conversations.remove(c.talkID, c.userID, w)
+
+	if prompt != "" {
+		if err := c.Send(prompt); err != nil {
+			return "", err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	for {
+		msg, err := c.wait(ctx, w)
+		if err != nil {
+			return "", err
+		}
+		answer := strings.TrimSpace(msg.Text)
+		if c.isCancel(answer) {
+			return "", ErrConversationCancelled
+		}
+		if err := validate(answer, validators); err != nil {
+			reason := err.Error()
+			var verr validationError
+			if errors.As(err, &verr) {
+				reason = verr.message(c.robot.Messages())
+			}
+			if sendErr := c.Send(reason); sendErr != nil {
+				return "", sendErr
+			}
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// AskSelect sends a select action stamp and returns the index of the option
+// the user chose.
+func (c *Conversation) AskSelect(ctx context.Context, question string, options []string) (int, error) {
+	msg, err := c.askStamp(ctx, func() (string, error) {
+		return c.robot.SendSelect(c.talkID, question, options)
+	}, func(msg direct.ReceivedMessage, questionID string) bool {
+		reply, ok := selectReplyOf(msg)
+		return ok && reply.InReplyTo == questionID
+	})
+	if err != nil {
+		return -1, err
+	}
+	reply, _ := selectReplyOf(msg)
+	return reply.Response, nil
+}
+
+// AskYesNo sends a yes/no action stamp and returns the user's answer.
+func (c *Conversation) AskYesNo(ctx context.Context, question string) (bool, error) {
+	msg, err := c.askStamp(ctx, func() (string, error) {
+		return c.robot.SendYesNo(c.talkID, question)
+	}, func(msg direct.ReceivedMessage, questionID string) bool {
+		reply, ok := yesNoReplyOf(msg)
+		return ok && reply.InReplyTo == questionID
+	})
+	if err != nil {
+		return false, err
+	}
+	reply, _ := yesNoReplyOf(msg)
+	return reply.Response, nil
+}
+
+// askStamp sends an action stamp and waits for the user's reply to it.
+// A text message with a cancel word also ends the wait.
+func (c *Conversation) askStamp(ctx context.Context, send func() (string, error), isReply func(msg direct.ReceivedMessage, questionID string) bool) (direct.ReceivedMessage, error) {
+	var mu sync.Mutex
+	var questionID string
+	w, err := c.robot.conversations.add(c.talkID, c.userID, func(msg direct.ReceivedMessage) bool {
+		if msg.Type.Internal() == direct.MessageTypeText {
+			return c.isCancel(strings.TrimSpace(msg.Text))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return questionID != "" && isReply(msg, questionID)
+	})
+	if err != nil {
+		return direct.ReceivedMessage{}, err
+	}
+	defer c.robot.conversations.remove(c.talkID, c.userID, w)
+
+	id, err := send()
+	if err != nil {
+		return direct.ReceivedMessage{}, err
+	}
+	mu.Lock()
+	questionID = id
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	msg, err := c.wait(ctx, w)
+	if err != nil {
+		return direct.ReceivedMessage{}, err
+	}
+	if msg.Type.Internal() == direct.MessageTypeText {
+		return direct.ReceivedMessage{}, ErrConversationCancelled
+	}
+	return msg, nil
+}
+
+// wait returns the next message delivered to w.
+func (c *Conversation) wait(ctx context.Context, w *waiter) (direct.ReceivedMessage, error) {
+	select {
+	case msg := <-w.messages:
+		return msg, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return direct.ReceivedMessage{}, ErrConversationTimeout
+		}
+		return direct.ReceivedMessage{}, ctx.Err()
+	}
+}
+
+func (c *Conversation) isCancel(answer string) bool {
+	for _, word := range c.cancelWords {
+		if strings.EqualFold(answer, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func validate(answer string, validators []Validator) error {
+	for _, v := range validators {
+		if err := v(answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waiter receives the messages a conversation is waiting for.
+type waiter struct {
+	accept   func(msg direct.ReceivedMessage) bool
+	messages chan direct.ReceivedMessage
+}
+
+// conversations tracks the waiting questions of a robot, keyed by talk and user.
+type conversations struct {
+	mu      sync.Mutex
+	waiters map[string]*waiter
+}
+
+func conversationKey(talkID, userID string) string {
+	return talkID + "/" + userID
+}
+
+func (cs *conversations) add(talkID, userID string, accept func(msg direct.ReceivedMessage) bool) (*waiter, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	key := conversationKey(talkID, userID)
+	if _, ok := cs.waiters[key]; ok {
+		return nil, ErrConversationBusy
+	}
+	if cs.waiters == nil {
+		cs.waiters = make(map[string]*waiter)
+	}
+	w := &waiter{
+		accept:   accept,
+		messages: make(chan direct.ReceivedMessage, 1),
+	}
+	cs.waiters[key] = w
+	return w, nil
+}
+
+func (cs *conversations) remove(talkID, userID string, w *waiter) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	key := conversationKey(talkID, userID)
+	if cs.waiters[key] == w {
+		delete(cs.waiters, key)
+	}
+}
+
+// deliver hands msg to the waiting conversation of its talk and sender.
+// It reports false if no conversation accepted the message.
+func (cs *conversations) deliver(msg direct.ReceivedMessage) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	w, ok := cs.waiters[conversationKey(msg.TalkID, msg.UserID)]
+	if !ok || !w.accept(msg) {
+		return false
+	}
+	select {
+	case w.messages <- msg:
+		return true
+	default:
+		// The previous answer is still being handled.
+		return false
+	}
+}
@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// newTestRobot returns a robot created with opts and connected to a mock
+// server that answers create_message calls with message ID q1.
+func newTestRobot(t *testing.T, opts ...Option) (*Robot, *testutil.MockServer) {
+	t.Helper()
+
+	mockServer := testutil.NewMockServer()
+	t.Cleanup(mockServer.Close)
+	mockServer.OnSimple("create_message", map[string]interface{}{
+		"message_id": "q1",
+	})
+
+	client := direct.NewClient(direct.Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	robot := New(opts...)
+	robot.client = client
+	return robot, mockServer
+}
+
+// waitSent waits until the mock server has received n create_message calls.
+func waitSent(t *testing.T, mockServer *testutil.MockServer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for mockServer.GetCallCount("create_message") < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d create_message calls, got %d", n, mockServer.GetCallCount("create_message"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type askResult struct {
+	answer string
+	err    error
+}
+
+func TestConversationAsk(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	heard := make(chan string, 10)
+	robot.Hear(".*", func(ctx context.Context, res Response) {
+		heard <- res.Text()
+	})
+
+	conv := robot.Conversation("talk1", "alice")
+	result := make(chan askResult, 1)
+	go func() {
+		answer, err := conv.Ask(context.Background(), "Which env?", OneOf("dev", "prod"))
+		result <- askResult{answer, err}
+	}()
+	waitSent(t, mockServer, 1)
+
+	text := func(userID, text string) direct.ReceivedMessage {
+		return direct.ReceivedMessage{TalkID: "talk1", UserID: userID, Type: direct.MessageTypeText, Text: text}
+	}
+
+	// Other users keep reaching the listeners.
+	robot.handleMessage(context.Background(), text("bob", "hello"))
+	if got := waitCalls(t, heard, 1); got[0] != "hello" {
+		t.Errorf("Expected listener to hear bob, got %v", got)
+	}
+
+	// An invalid answer is rejected with the validator's message.
+	robot.handleMessage(context.Background(), text("alice", "staging"))
+	waitSent(t, mockServer, 2)
+
+	robot.handleMessage(context.Background(), text("alice", " prod "))
+	select {
+	case r := <-result:
+		if r.err != nil || r.answer != "prod" {
+			t.Errorf("Expected answer prod, got %q, %v", r.answer, r.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Ask did not return")
+	}
+
+	select {
+	case got := <-heard:
+		t.Errorf("Expected answers not to reach listeners, got %q", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Once answered, the user's messages reach the listeners again.
+	robot.handleMessage(context.Background(), text("alice", "thanks"))
+	if got := waitCalls(t, heard, 1); got[0] != "thanks" {
+		t.Errorf("Expected listener to hear alice, got %v", got)
+	}
+}
+
+func TestValidatorMessages(t *testing.T) {
+	if err := OneOf("dev", "prod")("stg"); err == nil || err.Error() != "dev / prod のいずれかで答えてください" {
+		t.Errorf("Unexpected OneOf error: %v", err)
+	}
+	if err := MatchPattern(`^\d+$`)("12"); err != nil {
+		t.Errorf("Expected 12 to match, got %v", err)
+	}
+
+	robot, mockServer := newTestRobot(t)
+	WithMessages(Messages{OneOf: "Answer with %s.", NoMatch: "Enter a number."})(robot)
+	conv := robot.Conversation("talk1", "alice")
+	result := make(chan askResult, 1)
+	go func() {
+		answer, err := conv.Ask(context.Background(), "Which env?", OneOf("dev", "prod"), MatchPattern(`^[a-z]+$`))
+		result <- askResult{answer, err}
+	}()
+	answer := func(text string) {
+		robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "talk1", UserID: "alice", Type: direct.MessageTypeText, Text: text})
+	}
+
+	waitSent(t, mockServer, 1)
+	answer("stg")
+	waitSent(t, mockServer, 2)
+	answer("dev")
+	if r := <-result; r.err != nil || r.answer != "dev" {
+		t.Errorf("Expected answer dev, got %q, %v", r.answer, r.err)
+	}
+	if texts := sentTexts(mockServer); len(texts) != 2 || texts[1] != "Answer with dev / prod." {
+		t.Errorf("Expected the overridden OneOf text, got %q", texts)
+	}
+}
+
+func TestConversationTimeoutAndCancel(t *testing.T) {
+	robot, _ := newTestRobot(t)
+
+	conv := robot.Conversation("talk1", "alice", WithConversationTimeout(20*time.Millisecond))
+	if _, err := conv.Ask(context.Background(), ""); !errors.Is(err, ErrConversationTimeout) {
+		t.Errorf("Expected ErrConversationTimeout, got %v", err)
+	}
+
+	conv = robot.Conversation("talk1", "alice")
+	result := make(chan askResult, 1)
+	go func() {
+		answer, err := conv.Ask(context.Background(), "")
+		result <- askResult{answer, err}
+	}()
+	waitFor(t, func() bool {
+		return robot.conversations.deliver(direct.ReceivedMessage{TalkID: "talk1", UserID: "alice", Type: direct.MessageTypeText, Text: "キャンセル"})
+	})
+	if r := <-result; !errors.Is(r.err, ErrConversationCancelled) {
+		t.Errorf("Expected ErrConversationCancelled, got %v", r.err)
+	}
+}
+
+func TestConversationBusy(t *testing.T) {
+	robot, _ := newTestRobot(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go robot.Conversation("talk1", "alice").Ask(ctx, "")
+
+	waitFor(t, func() bool {
+		robot.conversations.mu.Lock()
+		defer robot.conversations.mu.Unlock()
+		return len(robot.conversations.waiters) == 1
+	})
+
+	_, err := robot.Conversation("talk1", "alice").Ask(context.Background(), "")
+	if !errors.Is(err, ErrConversationBusy) {
+		t.Errorf("Expected ErrConversationBusy, got %v", err)
+	}
+}
+
+func TestConversationAskSelect(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+
+	conv := robot.Conversation("talk1", "alice")
+	result := make(chan int, 1)
+	go func() {
+		idx, err := conv.AskSelect(context.Background(), "Which env?", []string{"dev", "prod"})
+		if err != nil {
+			t.Errorf("AskSelect failed: %v", err)
+		}
+		result <- idx
+	}()
+	waitSent(t, mockServer, 1)
+
+	reply := func(inReplyTo string) direct.ReceivedMessage {
+		return direct.ReceivedMessage{
+			TalkID: "talk1",
+			UserID: "alice",
+			Type:   direct.MessageType(direct.WireTypeSelectReply),
+			Content: map[string]interface{}{
+				"in_reply_to": inReplyTo,
+				"response":    uint64(1),
+			},
+		}
+	}
+	if robot.conversations.deliver(reply("other")) {
+		t.Error("Expected reply to another question not to be delivered")
+	}
+	waitFor(t, func() bool {
+		return robot.conversations.deliver(reply("q1"))
+	})
+
+	select {
+	case idx := <-result:
+		if idx != 1 {
+			t.Errorf("Expected option 1, got %d", idx)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("AskSelect did not return")
+	}
+}
+
+// waitFor polls cond until it returns true.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
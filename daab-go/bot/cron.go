@@ -0,0 +1,395 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CronSchedule is a parsed standard 5-field cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Fields accept *, lists (1,15), ranges (1-5), steps (*/10, 0-30/5) and
+// month and weekday names (jan, mon). The descriptors @yearly, @monthly,
+// @weekly, @daily and @hourly are also accepted.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	cronDayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// ParseCron parses a cron expression.
+func ParseCron(spec string) (*CronSchedule, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("daab: cron spec %q must have 5 fields", spec)
+	}
+
+	s := &CronSchedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("daab: cron spec %q: minute: %w", spec, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("daab: cron spec %q: hour: %w", spec, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("daab: cron spec %q: day of month: %w", spec, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("daab: cron spec %q: month: %w", spec, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("daab: cron spec %q: day of week: %w", spec, err)
+	}
+	// 7 is another name for Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses one field into a bit set of allowed values.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseCronValue(from, names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(to, names); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := parseCronValue(rangePart, names)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value out of range in %q (%d-%d)", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule,
+// in t's location. It returns the zero time if there is none within 5 years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule that when both day fields are
+// restricted, a day matching either of them matches.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// CronOption configures a job registered with Robot.Cron.
+type CronOption func(*CronJob)
+
+// WithJitter delays each run by a random duration up to d,
+// spreading the load of many bots scheduled at the same time.
+func WithJitter(d time.Duration) CronOption {
+	return func(j *CronJob) {
+		j.jitter = d
+	}
+}
+
+// AllowOverlap lets a run start while the previous one is still running.
+// By default such runs are skipped.
+func AllowOverlap() CronOption {
+	return func(j *CronJob) {
+		j.overlap = true
+	}
+}
+
+// WithCronName names the job and records its last run time in the
+// robot's brain under "cron:<name>".
+func WithCronName(name string) CronOption {
+	return func(j *CronJob) {
+		j.name = name
+	}
+}
+
+// WithCatchUp runs a named job once at startup if a scheduled run was
+// missed while the robot was stopped. It requires WithCronName.
+func WithCatchUp() CronOption {
+	return func(j *CronJob) {
+		j.catchUp = true
+	}
+}
+
+// CronJob is a job scheduled with Robot.Cron.
+type CronJob struct {
+	robot    *Robot
+	spec     string
+	schedule *CronSchedule
+	loc      *time.Location
+	fn       func(ctx context.Context)
+	jitter   time.Duration
+	overlap  bool
+	name     string
+	catchUp  bool
+	running  atomic.Bool
+}
+
+// Spec returns the cron expression of the job.
+func (j *CronJob) Spec() string {
+	return j.spec
+}
+
+// Next returns the next scheduled run after t.
+func (j *CronJob) Next(t time.Time) time.Time {
+	return j.schedule.Next(t.In(j.loc))
+}
+
+// LastRun returns the time the last run started, as recorded in the brain.
+// It returns false for unnamed jobs or jobs that have not run yet.
+func (j *CronJob) LastRun() (time.Time, bool) {
+	if j.name == "" {
+		return time.Time{}, false
+	}
+	var last time.Time
+	ok, err := GetJSON(j.robot.brain, "cron:"+j.name, &last)
+	if err != nil {
+		log.Printf("Warning: could not read last run of cron job %s: %v", j.name, err)
+	}
+	return last, ok
+}
+
+// Cron schedules fn to run on the cron spec in the time zone tz
+// (for example "Asia/Tokyo"; "" means local time). Jobs run while Run is
+// running and receive a context that is cancelled when Run returns;
+// Run waits for running jobs before returning.
+func (r *Robot) Cron(spec, tz string, fn func(ctx context.Context), opts ...CronOption) (*CronJob, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	loc := time.Local
+	if tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("daab: invalid time zone %q: %w", tz, err)
+		}
+	}
+
+	job := &CronJob{
+		robot:    r,
+		spec:     spec,
+		schedule: schedule,
+		loc:      loc,
+		fn:       fn,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if job.catchUp && job.name == "" {
+		return nil, fmt.Errorf("daab: cron job %q: WithCatchUp requires WithCronName", spec)
+	}
+
+	r.cron.add(job)
+	return job, nil
+}
+
+// cronScheduler runs the cron jobs of a robot while it is running.
+type cronScheduler struct {
+	mu   sync.Mutex
+	jobs []*CronJob
+	ctx  context.Context // Non-nil while running
+	wg   sync.WaitGroup
+}
+
+func (c *cronScheduler) add(job *CronJob) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.jobs = append(c.jobs, job)
+	if c.ctx != nil {
+		c.wg.Add(1)
+		go job.loop(c.ctx, &c.wg)
+	}
+}
+
+// start runs all jobs until ctx is done. The returned function waits for
+// the scheduler and any running jobs to stop.
+func (c *cronScheduler) start(ctx context.Context) (wait func()) {
+	c.mu.Lock()
+	c.ctx = ctx
+	for _, job := range c.jobs {
+		c.wg.Add(1)
+		go job.loop(ctx, &c.wg)
+	}
+	c.mu.Unlock()
+
+	return func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		c.ctx = nil
+		c.mu.Unlock()
+		c.wg.Wait()
+	}
+}
+
+func (j *CronJob) loop(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if j.catchUp {
+		if last, ok := j.LastRun(); ok {
+			if next := j.Next(last); !next.IsZero() && !next.After(time.Now()) {
+				j.fire(ctx, wg)
+			}
+		}
+	}
+
+	for {
+		next := j.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Warning: cron job %q never runs", j.spec)
+			return
+		}
+		delay := time.Until(next)
+		if j.jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(j.jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			j.fire(ctx, wg)
+		}
+	}
+}
+
+// fire starts one run of the job unless the previous run is still going.
+func (j *CronJob) fire(ctx context.Context, wg *sync.WaitGroup) {
+	if !j.overlap && !j.running.CompareAndSwap(false, true) {
+		log.Printf("[DEBUG] Skipping cron job %q: previous run still in progress", j.spec)
+		return
+	}
+
+	started := time.Now()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if !j.overlap {
+			defer j.running.Store(false)
+		}
+		defer func() {
+			if v := recover(); v != nil {
+				log.Printf("[ERROR] cron job panic: %v (spec=%q)\n%s", v, j.spec, debug.Stack())
+			}
+		}()
+
+		if j.name != "" {
+			if err := SetJSON(j.robot.brain, "cron:"+j.name, started, 0); err != nil {
+				log.Printf("Warning: could not record last run of cron job %s: %v", j.name, err)
+			}
+		}
+		j.fn(ctx)
+	}()
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	from := time.Date(2026, 10, 18, 10, 30, 15, 0, tokyo) // Sunday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 18, 10, 31, 0, 0, tokyo)},
+		{"*/15 * * * *", time.Date(2026, 10, 18, 10, 45, 0, 0, tokyo)},
+		{"0 9 * * mon", time.Date(2026, 10, 19, 9, 0, 0, 0, tokyo)},
+		{"0 9 * * 1-5", time.Date(2026, 10, 19, 9, 0, 0, 0, tokyo)},
+		{"30 10 * * 7", time.Date(2026, 10, 25, 10, 30, 0, 0, tokyo)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, tokyo)},
+		{"0 12 31 * *", time.Date(2026, 10, 31, 12, 0, 0, 0, tokyo)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, tokyo)},
+		// Both day fields restricted: either matches.
+		{"0 8 20 * fri", time.Date(2026, 10, 20, 8, 0, 0, 0, tokyo)},
+		{"@daily", time.Date(2026, 10, 19, 0, 0, 0, 0, tokyo)},
+		{"@hourly", time.Date(2026, 10, 18, 11, 0, 0, 0, tokyo)},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("Expected ParseCron(%q) to fail", spec)
+		}
+	}
+}
+
+func TestCronInvalidOptions(t *testing.T) {
+	robot := New()
+	if _, err := robot.Cron("* * * * *", "Nowhere/Unknown", func(ctx context.Context) {}); err == nil {
+		t.Error("Expected invalid time zone to fail")
+	}
+	if _, err := robot.Cron("* * * * *", "", func(ctx context.Context) {}, WithCatchUp()); err == nil {
+		t.Error("Expected WithCatchUp without a name to fail")
+	}
+}
+
+func TestCronOverlapPrevention(t *testing.T) {
+	robot := New()
+	release := make(chan struct{})
+	var runs int32
+	job, err := robot.Cron("* * * * *", "", func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+		<-release
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	job.fire(context.Background(), &wg)
+	job.fire(context.Background(), &wg)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&runs); n != 1 {
+		t.Errorf("Expected overlapping run to be skipped, got %d runs", n)
+	}
+
+	job.fire(context.Background(), &wg)
+	wg.Wait()
+	if n := atomic.LoadInt32(&runs); n != 2 {
+		t.Errorf("Expected run after the previous one finished, got %d runs", n)
+	}
+}
+
+func TestCronCatchUpAndShutdown(t *testing.T) {
+	robot := New()
+	SetJSON(robot.Brain(), "cron:report", time.Now().Add(-2*time.Hour), 0)
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	job, err := robot.Cron("0 * * * *", "", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+	}, WithCronName("report"), WithCatchUp())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := robot.cron.start(ctx)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Expected missed run to be caught up at startup")
+	}
+	if last, ok := job.LastRun(); !ok || time.Since(last) > time.Second {
+		t.Errorf("Expected last run to be recorded, got %v (found=%v)", last, ok)
+	}
+
+	cancel()
+	wait()
+	if !finished.Load() {
+		t.Error("Expected shutdown to wait for the running job")
+	}
+}
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// DefaultDirectoryTTL is how long looked up entries stay cached by default.
+const DefaultDirectoryTTL = 10 * time.Minute
+
+// Directory caches user, talk and domain lookups made through the direct API.
+// A single Directory can be shared by several robots (for example all robots
+// of a Fleet) so that each user is only fetched once per TTL.
+type Directory struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	users   map[string]directoryUser
+	talks   map[string]directoryTalk
+	domains map[string]directoryDomain
+}
+
+type directoryUser struct {
+	info    direct.UserInfo
+	expires time.Time
+}
+
+type directoryTalk struct {
+	talk    direct.Talk
+	expires time.Time
+}
+
+type directoryDomain struct {
+	domain  direct.DomainInfo
+	expires time.Time
+}
+
+// NewDirectory creates an empty Directory.
+// A ttl of zero or less uses DefaultDirectoryTTL.
+func NewDirectory(ttl time.Duration) *Directory {
+	if ttl <= 0 {
+		ttl = DefaultDirectoryTTL
+	}
+	return &Directory{
+		ttl:     ttl,
+		users:   make(map[string]directoryUser),
+		talks:   make(map[string]directoryTalk),
+		domains: make(map[string]directoryDomain),
+	}
+}
+
+// CachedUser returns the cached user info for userID, if present and not expired.
+func (d *Directory) CachedUser(userID string) (*direct.UserInfo, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entry, ok := d.users[userID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	info := entry.info
+	return &info, true
+}
+
+// StoreUser adds or replaces a cached user entry.
+func (d *Directory) StoreUser(user direct.UserInfo) {
+	if user.ID == nil {
+		return
+	}
+	d.mu.Lock()
+	d.users[fmt.Sprintf("%v", user.ID)] = directoryUser{
+		info:    user,
+		expires: time.Now().Add(d.ttl),
+	}
+	d.mu.Unlock()
+}
+
+// Forget removes a cached user entry.
+func (d *Directory) Forget(userID string) {
+	d.mu.Lock()
+	delete(d.users, userID)
+	d.mu.Unlock()
+}
+
+// CachedTalk returns the cached talk for talkID, if present and not expired.
+func (d *Directory) CachedTalk(talkID string) (*direct.Talk, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entry, ok := d.talks[talkID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	talk := entry.talk
+	return &talk, true
+}
+
+// StoreTalk adds or replaces a cached talk entry.
+func (d *Directory) StoreTalk(talk direct.Talk) {
+	if talk.ID == nil {
+		return
+	}
+	d.mu.Lock()
+	d.talks[fmt.Sprintf("%v", talk.ID)] = directoryTalk{
+		talk:    talk,
+		expires: time.Now().Add(d.ttl),
+	}
+	d.mu.Unlock()
+}
+
+// CachedDomain returns the cached domain for domainID, if present and not expired.
+func (d *Directory) CachedDomain(domainID string) (*direct.DomainInfo, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entry, ok := d.domains[domainID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	domain := entry.domain
+	return &domain, true
+}
+
+// StoreDomain adds or replaces a cached domain entry.
+func (d *Directory) StoreDomain(domain direct.DomainInfo) {
+	if domain.ID == nil {
+		return
+	}
+	d.mu.Lock()
+	d.domains[fmt.Sprintf("%v", domain.ID)] = directoryDomain{
+		domain:  domain,
+		expires: time.Now().Add(d.ttl),
+	}
+	d.mu.Unlock()
+}
+
+// LookupUser returns the profile of userID within domainID.
+// Results are served from the robot's Directory when cached.
+func (r *Robot) LookupUser(ctx context.Context, domainID, userID string) (*direct.UserInfo, error) {
+	if user, ok := r.directory.CachedUser(userID); ok {
+		return user, nil
+	}
+	if r.client == nil {
+		return nil, ErrNotConnected
+	}
+
+	users, err := r.client.GetUsers(ctx, normalizeRoomID(domainID), []interface{}{normalizeRoomID(userID)})
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		r.directory.StoreUser(user)
+	}
+
+	if user, ok := r.directory.CachedUser(userID); ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+}
+
+// LookupTalk returns the talk with talkID.
+// On a cache miss all talks of the robot are fetched and cached.
+func (r *Robot) LookupTalk(ctx context.Context, talkID string) (*direct.Talk, error) {
+	if talk, ok := r.directory.CachedTalk(talkID); ok {
+		return talk, nil
+	}
+	if r.client == nil {
+		return nil, ErrNotConnected
+	}
+
+	// Serialize refreshes so concurrent misses fetch the talk list only once.
+	r.talkFetch.Lock()
+	defer r.talkFetch.Unlock()
+	if talk, ok := r.directory.CachedTalk(talkID); ok {
+		return talk, nil
+	}
+
+	if _, err := r.fetchTalks(ctx); err != nil {
+		return nil, err
+	}
+	if talk, ok := r.directory.CachedTalk(talkID); ok {
+		return talk, nil
+	}
+	return nil, fmt.Errorf("%w: talk %s", ErrNotFound, talkID)
+}
+
+// LookupDomain returns the domain with domainID.
+// On a cache miss all domains of the robot are fetched and cached.
+func (r *Robot) LookupDomain(ctx context.Context, domainID string) (*direct.DomainInfo, error) {
+	if domain, ok := r.directory.CachedDomain(domainID); ok {
+		return domain, nil
+	}
+	if r.client == nil {
+		return nil, ErrNotConnected
+	}
+
+	domains, err := r.client.GetDomainsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, domain := range domains {
+		r.directory.StoreDomain(domain)
+	}
+
+	if domain, ok := r.directory.CachedDomain(domainID); ok {
+		return domain, nil
+	}
+	return nil, fmt.Errorf("%w: domain %s", ErrNotFound, domainID)
+}
+
+// Talks returns all talks of the robot, fetched from the server.
+// The talks are also stored in the robot's Directory.
+func (r *Robot) Talks(ctx context.Context) ([]direct.Talk, error) {
+	if r.client == nil {
+		return nil, ErrNotConnected
+	}
+	r.talkFetch.Lock()
+	defer r.talkFetch.Unlock()
+	return r.fetchTalks(ctx)
+}
+
+// fetchTalks fetches all talks and caches them. r.talkFetch must be held.
+func (r *Robot) fetchTalks(ctx context.Context) ([]direct.Talk, error) {
+	talks, err := r.client.GetTalksWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, talk := range talks {
+		r.directory.StoreTalk(talk)
+	}
+	return talks, nil
+}
+
+// Directory returns the directory used by the robot.
+func (r *Robot) Directory() *Directory {
+	return r.directory
+}
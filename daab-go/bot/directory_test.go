@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestDirectoryCache(t *testing.T) {
+	d := NewDirectory(time.Minute)
+
+	if _, ok := d.CachedUser("1"); ok {
+		t.Error("Expected empty directory")
+	}
+
+	d.StoreUser(direct.UserInfo{ID: uint64(1), DisplayName: "Alice"})
+	user, ok := d.CachedUser("1")
+	if !ok || user.DisplayName != "Alice" {
+		t.Errorf("Expected cached Alice, got %+v", user)
+	}
+
+	d.Forget("1")
+	if _, ok := d.CachedUser("1"); ok {
+		t.Error("Expected user to be forgotten")
+	}
+}
+
+func TestDirectoryExpiry(t *testing.T) {
+	d := NewDirectory(time.Millisecond)
+	d.StoreUser(direct.UserInfo{ID: "1"})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := d.CachedUser("1"); ok {
+		t.Error("Expected entry to expire")
+	}
+}
+
+func TestLookupUser(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("get_users", []interface{}{
+		map[string]interface{}{
+			"id":           uint64(42),
+			"display_name": "Alice",
+		},
+	})
+
+	client := direct.NewClient(direct.Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	shared := NewDirectory(0)
+	robot := New(WithDirectory(shared))
+	robot.client = client
+	other := New(WithDirectory(shared))
+
+	ctx := context.Background()
+	user, err := robot.LookupUser(ctx, "1", "42")
+	if err != nil {
+		t.Fatalf("LookupUser failed: %v", err)
+	}
+	if user.DisplayName != "Alice" {
+		t.Errorf("Expected Alice, got %s", user.DisplayName)
+	}
+
+	// The second robot is not connected but is served from the shared cache.
+	if _, err := other.LookupUser(ctx, "1", "42"); err != nil {
+		t.Errorf("Expected cached lookup, got %v", err)
+	}
+	if n := mockServer.GetCallCount("get_users"); n != 1 {
+		t.Errorf("Expected 1 get_users call, got %d", n)
+	}
+
+	if _, err := other.LookupUser(ctx, "1", "43"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+}
+
+func TestLookupDomain(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("get_domains", []interface{}{
+		map[string]interface{}{"id": uint64(1), "name": "Example Inc."},
+	})
+
+	client := direct.NewClient(direct.Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	robot := New()
+	robot.client = client
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		domain, err := robot.LookupDomain(ctx, "1")
+		if err != nil || domain.Name != "Example Inc." {
+			t.Fatalf("Expected Example Inc., got %+v %v", domain, err)
+		}
+	}
+	if n := mockServer.GetCallCount("get_domains"); n != 1 {
+		t.Errorf("Expected 1 get_domains call, got %d", n)
+	}
+	if _, err := robot.LookupDomain(ctx, "2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Filter decides whether a matched listener should handle a message.
+type Filter func(ctx context.Context, res Response) bool
+
+// ListenerOption configures a listener registered with Hear or Respond.
+type ListenerOption func(*Listener)
+
+// WithFilter adds a custom filter to the listener.
+func WithFilter(f Filter) ListenerOption {
+	return func(l *Listener) {
+		l.Filters = append(l.Filters, f)
+	}
+}
+
+// Named sets the name identifying the listener in metrics. The default is
+// "hear:" or "respond:" followed by the pattern, or the message type such as
+// "stamp" for typed listeners.
+func Named(name string) ListenerOption {
+	return func(l *Listener) {
+		l.Name = name
+	}
+}
+
+// InPairTalks restricts the listener to 1:1 talks.
+func InPairTalks() ListenerOption {
+	return WithFilter(talkTypeFilter(direct.RoomTypePair))
+}
+
+// InGroupTalks restricts the listener to group talks.
+func InGroupTalks() ListenerOption {
+	return WithFilter(talkTypeFilter(direct.RoomTypeGroup))
+}
+
+// InTalks restricts the listener to the given talk IDs.
+func InTalks(talkIDs ...string) ListenerOption {
+	allowed := toSet(talkIDs)
+	return WithFilter(func(ctx context.Context, res Response) bool {
+		return allowed[res.Message.TalkID]
+	})
+}
+
+// InDomains restricts the listener to messages from the given domain IDs.
+func InDomains(domainIDs ...string) ListenerOption {
+	allowed := toSet(domainIDs)
+	return WithFilter(func(ctx context.Context, res Response) bool {
+		return allowed[res.Message.DomainID]
+	})
+}
+
+// FromUsers restricts the listener to messages sent by the given user IDs.
+func FromUsers(userIDs ...string) ListenerOption {
+	allowed := toSet(userIDs)
+	return WithFilter(func(ctx context.Context, res Response) bool {
+		return allowed[res.Message.UserID]
+	})
+}
+
+// OfTypes restricts the listener to the given message types.
+func OfTypes(types ...direct.MessageType) ListenerOption {
+	allowed := make(map[direct.MessageType]bool, len(types))
+	for _, t := range types {
+		allowed[t] = true
+	}
+	return WithFilter(func(ctx context.Context, res Response) bool {
+		return allowed[res.Message.Type]
+	})
+}
+
+// talkTypeFilter accepts messages from talks of the given type,
+// looking the talk up through the robot's Directory.
+func talkTypeFilter(want direct.RoomType) Filter {
+	return func(ctx context.Context, res Response) bool {
+		talk, err := res.Robot.LookupTalk(ctx, res.Message.TalkID)
+		if err != nil {
+			log.Printf("Warning: could not look up talk %s: %v", res.Message.TalkID, err)
+			return false
+		}
+		return talk.Type == int(want)
+	}
+}
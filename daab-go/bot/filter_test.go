@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestListenerFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		opt    ListenerOption
+		accept direct.ReceivedMessage
+		reject direct.ReceivedMessage
+	}{
+		{
+			name:   "InTalks",
+			opt:    InTalks("t1"),
+			accept: direct.ReceivedMessage{TalkID: "t1"},
+			reject: direct.ReceivedMessage{TalkID: "t2"},
+		},
+		{
+			name:   "InDomains",
+			opt:    InDomains("d1"),
+			accept: direct.ReceivedMessage{DomainID: "d1"},
+			reject: direct.ReceivedMessage{DomainID: "d2"},
+		},
+		{
+			name:   "FromUsers",
+			opt:    FromUsers("u1"),
+			accept: direct.ReceivedMessage{UserID: "u1"},
+			reject: direct.ReceivedMessage{UserID: "u2"},
+		},
+		{
+			name:   "OfTypes",
+			opt:    OfTypes(direct.MessageTypeStamp),
+			accept: direct.ReceivedMessage{Type: direct.MessageTypeStamp},
+			reject: direct.ReceivedMessage{Type: direct.MessageTypeText},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Listener{}
+			tt.opt(l)
+			if len(l.Filters) != 1 {
+				t.Fatalf("Expected 1 filter, got %d", len(l.Filters))
+			}
+			ctx := context.Background()
+			if !l.Filters[0](ctx, Response{Message: tt.accept}) {
+				t.Errorf("Expected %+v to be accepted", tt.accept)
+			}
+			if l.Filters[0](ctx, Response{Message: tt.reject}) {
+				t.Errorf("Expected %+v to be rejected", tt.reject)
+			}
+		})
+	}
+}
+
+func TestTalkTypeFilters(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{"talk_id": uint64(1), "type": int8(1)},
+		map[string]interface{}{"talk_id": uint64(2), "type": int8(2)},
+	})
+
+	client := direct.NewClient(direct.Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	robot := New()
+	robot.client = client
+
+	calls := make(chan string, 10)
+	robot.Hear(".*", func(ctx context.Context, res Response) {
+		calls <- "pair:" + res.RoomID()
+	}, InPairTalks())
+	robot.Hear(".*", func(ctx context.Context, res Response) {
+		calls <- "group:" + res.RoomID()
+	}, InGroupTalks())
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "1", Text: "hi"})
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{TalkID: "2", Text: "hi"})
+
+	got := map[string]bool{}
+	for _, v := range waitCalls(t, calls, 2) {
+		got[v] = true
+	}
+	if !got["pair:1"] || !got["group:2"] {
+		t.Errorf("Expected pair:1 and group:2, got %v", got)
+	}
+
+	select {
+	case v := <-calls:
+		t.Errorf("Unexpected call: %s", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if n := mockServer.GetCallCount("get_talks"); n != 1 {
+		t.Errorf("Expected talks to be cached, got %d get_talks calls", n)
+	}
+}
+
+func TestIgnoreSelfMessages(t *testing.T) {
+	robot := New()
+	robot.setSelfID("100")
+
+	calls := make(chan string, 10)
+	robot.Hear(".*", func(ctx context.Context, res Response) {
+		calls <- res.UserID()
+	})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{UserID: "100", Text: "echo"})
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{UserID: "200", Text: "hello"})
+
+	got := waitCalls(t, calls, 1)
+	if got[0] != "200" {
+		t.Errorf("Expected only user 200, got %v", got)
+	}
+	select {
+	case v := <-calls:
+		t.Errorf("Unexpected call from %s", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWithSelfMessages(t *testing.T) {
+	robot := New(WithSelfMessages())
+	robot.setSelfID("100")
+
+	calls := make(chan string, 10)
+	robot.Hear(".*", func(ctx context.Context, res Response) {
+		calls <- res.UserID()
+	})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{UserID: "100", Text: "echo"})
+	if got := waitCalls(t, calls, 1); got[0] != "100" {
+		t.Errorf("Expected self message to be delivered, got %v", got)
+	}
+}
+
+func TestLearnSelf(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("get_me", map[string]interface{}{
+		"user_id":      uint64(42),
+		"display_name": "bot",
+	})
+
+	client := direct.NewClient(direct.Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	robot := New()
+	robot.client = client
+	robot.learnSelf(context.Background(), map[string]interface{}{"user_id": uint64(41)})
+
+	if robot.SelfID() != "42" {
+		t.Errorf("Expected self ID 42 from get_me, got %s", robot.SelfID())
+	}
+	if robot.SelfName() != "bot" {
+		t.Errorf("Expected self name bot from get_me, got %s", robot.SelfName())
+	}
+}
@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Errors returned by fleet operations.
+var (
+	// ErrNoProfiles is returned when running a Fleet without any robots.
+	ErrNoProfiles = errors.New("daab: fleet has no profiles")
+
+	// ErrDuplicateProfile is returned when two profiles share the same name.
+	ErrDuplicateProfile = errors.New("daab: duplicate profile name")
+)
+
+// Profile describes one bot identity managed by a Fleet.
+type Profile struct {
+	// Name identifies the profile and is used as the robot name.
+	Name string `json:"name"`
+
+	// Token is the access token for this identity.
+	Token string `json:"token,omitempty"`
+
+	// TokenEnv names an environment variable holding the access token.
+	// It is used when Token is empty.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// Endpoint is an optional custom API endpoint.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ProxyURL is an optional proxy URL.
+	ProxyURL string `json:"proxy_url,omitempty"`
+
+	// BrainFile is an optional path of a FileBrain for this identity.
+	BrainFile string `json:"brain_file,omitempty"`
+}
+
+// options converts the profile into Robot options.
+func (p Profile) options() []Option {
+	opts := []Option{WithName(p.Name)}
+	if p.Token != "" {
+		opts = append(opts, WithToken(p.Token))
+	} else if p.TokenEnv != "" {
+		opts = append(opts, WithTokenEnv(p.TokenEnv))
+	}
+	if p.Endpoint != "" {
+		opts = append(opts, WithEndpoint(p.Endpoint))
+	}
+	if p.ProxyURL != "" {
+		opts = append(opts, WithProxy(p.ProxyURL))
+	}
+	if p.BrainFile != "" {
+		opts = append(opts, WithBrain(NewFileBrain(p.BrainFile)))
+	}
+	return opts
+}
+
+// LoadProfiles reads profiles from a JSON file of the form
+//
+//	{"profiles": [{"name": "alice", "token_env": "ALICE_TOKEN"}, ...]}
+func LoadProfiles(path string) ([]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Profiles []Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles %s: %w", path, err)
+	}
+	return file.Profiles, nil
+}
+
+// Fleet runs several robots, each with its own identity, in one process.
+// Robots connecting to the same endpoint share a Directory; robots on
+// different endpoints talk to different direct instances, whose IDs must not
+// be mixed, so each endpoint has its own.
+type Fleet struct {
+	mu          sync.RWMutex
+	robots      []*Robot
+	byName      map[string]*Robot
+	directories map[string]*Directory
+	opts        []Option
+}
+
+// NewFleet creates a Fleet with one robot per profile.
+// The given options are applied to every robot before the profile settings.
+func NewFleet(profiles []Profile, opts ...Option) (*Fleet, error) {
+	f := &Fleet{
+		byName:      make(map[string]*Robot),
+		directories: make(map[string]*Directory),
+		opts:        opts,
+	}
+	for _, p := range profiles {
+		if _, err := f.Add(p); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Add creates a robot for the profile and adds it to the fleet.
+// Extra options are applied after the profile settings. A profile must set
+// Token or TokenEnv, so that no robot falls back to the shared login token.
+func (f *Fleet) Add(p Profile, opts ...Option) (*Robot, error) {
+	if p.Name == "" {
+		return nil, fmt.Errorf("daab: profile name is required")
+	}
+	if p.Token == "" && p.TokenEnv == "" {
+		return nil, fmt.Errorf("%w: profile %s needs token or token_env", ErrNoToken, p.Name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.byName[p.Name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateProfile, p.Name)
+	}
+
+	// The directory depends on the endpoint, which is known once all
+	// options are applied. An option setting a directory is kept.
+	placeholder := &Directory{}
+	all := append([]Option{WithDirectory(placeholder)}, f.opts...)
+	all = append(all, p.options()...)
+	all = append(all, opts...)
+	robot := New(all...)
+	if robot.directory == placeholder {
+		robot.directory = f.directoryLocked(robot.endpoint)
+	}
+
+	f.robots = append(f.robots, robot)
+	f.byName[p.Name] = robot
+	return robot, nil
+}
+
+// Robot returns the robot for the named profile, or nil if there is none.
+func (f *Fleet) Robot(name string) *Robot {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.byName[name]
+}
+
+// Robots returns all robots in the order they were added.
+func (f *Fleet) Robots() []*Robot {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]*Robot(nil), f.robots...)
+}
+
+// Directory returns the directory shared by the robots of the fleet that
+// connect to endpoint. An empty endpoint stands for the one from the
+// environment or the default, as in Robot.Run.
+func (f *Fleet) Directory(endpoint string) *Directory {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.directoryLocked(endpoint)
+}
+
+func (f *Fleet) directoryLocked(endpoint string) *Directory {
+	endpoint = resolveEndpoint(endpoint)
+	d, ok := f.directories[endpoint]
+	if !ok {
+		d = NewDirectory(0)
+		f.directories[endpoint] = d
+	}
+	return d
+}
+
+// Each calls fn for every robot in the fleet.
+func (f *Fleet) Each(fn func(*Robot)) {
+	for _, robot := range f.Robots() {
+		fn(robot)
+	}
+}
+
+// Hear registers a Hear listener on every robot in the fleet.
+// Use Robot(name).Hear to register a listener for a single identity.
+func (f *Fleet) Hear(pattern string, handler Handler, opts ...ListenerOption) {
+	f.Each(func(r *Robot) {
+		r.Hear(pattern, handler, opts...)
+	})
+}
+
+// Respond registers a Respond listener on every robot in the fleet.
+// Use Robot(name).Respond to register a listener for a single identity.
+func (f *Fleet) Respond(pattern string, handler Handler, opts ...ListenerOption) {
+	f.Each(func(r *Robot) {
+		r.Respond(pattern, handler, opts...)
+	})
+}
+
+// Run starts all robots and blocks until every robot has stopped.
+// A robot that fails does not stop the others; all errors are joined.
+func (f *Fleet) Run(ctx context.Context) error {
+	robots := f.Robots()
+	if len(robots) == 0 {
+		return ErrNoProfiles
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(robots))
+	for i, robot := range robots {
+		wg.Add(1)
+		go func(i int, robot *Robot) {
+			defer wg.Done()
+			if err := robot.Run(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", robot.Name, err)
+			}
+		}(i, robot)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
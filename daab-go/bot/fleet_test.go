@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+func TestNewFleet(t *testing.T) {
+	fleet, err := NewFleet([]Profile{
+		{Name: "alice", Token: "token-a"},
+		{Name: "bob", TokenEnv: "BOB_TOKEN", Endpoint: "wss://bob.example.com", ProxyURL: "http://proxy.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewFleet failed: %v", err)
+	}
+
+	if len(fleet.Robots()) != 2 {
+		t.Fatalf("Expected 2 robots, got %d", len(fleet.Robots()))
+	}
+
+	alice := fleet.Robot("alice")
+	if alice == nil || alice.Token != "token-a" {
+		t.Errorf("Expected alice robot with token-a, got %+v", alice)
+	}
+
+	bob := fleet.Robot("bob")
+	if bob == nil {
+		t.Fatal("Expected bob robot")
+	}
+	if bob.tokenEnv != "BOB_TOKEN" {
+		t.Errorf("Expected tokenEnv BOB_TOKEN, got %s", bob.tokenEnv)
+	}
+	if bob.endpoint != "wss://bob.example.com" {
+		t.Errorf("Expected bob endpoint, got %s", bob.endpoint)
+	}
+	if bob.proxyURL != "http://proxy.example.com" {
+		t.Errorf("Expected bob proxy, got %s", bob.proxyURL)
+	}
+
+	if alice.Directory() != fleet.Directory("") || bob.Directory() != fleet.Directory("wss://bob.example.com") {
+		t.Error("Expected robots to use the fleet directory of their endpoint")
+	}
+	if alice.Directory() == bob.Directory() {
+		t.Error("Expected robots on different endpoints not to share a directory")
+	}
+	carol, err := fleet.Add(Profile{Name: "carol", Token: "token-c", Endpoint: "wss://bob.example.com"})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if carol.Directory() != bob.Directory() {
+		t.Error("Expected robots on the same endpoint to share a directory")
+	}
+
+	if fleet.Robot("dave") != nil {
+		t.Error("Expected nil for unknown profile")
+	}
+}
+
+func TestNewFleetDuplicateProfile(t *testing.T) {
+	_, err := NewFleet([]Profile{{Name: "alice", Token: "a"}, {Name: "alice", Token: "a"}})
+	if !errors.Is(err, ErrDuplicateProfile) {
+		t.Errorf("Expected ErrDuplicateProfile, got %v", err)
+	}
+
+	_, err = NewFleet([]Profile{{Name: ""}})
+	if err == nil {
+		t.Error("Expected error for empty profile name")
+	}
+}
+
+func TestFleetListeners(t *testing.T) {
+	fleet, err := NewFleet([]Profile{{Name: "alice", Token: "a"}, {Name: "bob", Token: "b"}})
+	if err != nil {
+		t.Fatalf("NewFleet failed: %v", err)
+	}
+
+	fleet.Hear("hello", func(ctx context.Context, res Response) {})
+	fleet.Robot("bob").Respond("ping", func(ctx context.Context, res Response) {})
+
+	if n := len(fleet.Robot("alice").listeners); n != 1 {
+		t.Errorf("Expected 1 listener on alice, got %d", n)
+	}
+	if n := len(fleet.Robot("bob").listeners); n != 2 {
+		t.Errorf("Expected 2 listeners on bob, got %d", n)
+	}
+	if _, ok := fleet.Robot("bob").listeners[1].matches(direct.ReceivedMessage{Text: "@bob ping"}); !ok {
+		t.Error("Expected bob's Respond listener to use its own name")
+	}
+}
+
+func TestFleetRunWithoutProfiles(t *testing.T) {
+	fleet, err := NewFleet(nil)
+	if err != nil {
+		t.Fatalf("NewFleet failed: %v", err)
+	}
+	if err := fleet.Run(context.Background()); !errors.Is(err, ErrNoProfiles) {
+		t.Errorf("Expected ErrNoProfiles, got %v", err)
+	}
+}
+
+func TestFleetRunMissingToken(t *testing.T) {
+	t.Setenv("ALICE_TOKEN", "")
+
+	fleet, err := NewFleet([]Profile{{Name: "alice", TokenEnv: "ALICE_TOKEN"}})
+	if err != nil {
+		t.Fatalf("NewFleet failed: %v", err)
+	}
+
+	err = fleet.Run(context.Background())
+	if !errors.Is(err, ErrNoToken) {
+		t.Errorf("Expected ErrNoToken, got %v", err)
+	}
+}
+
+func TestFleetAddWithoutToken(t *testing.T) {
+	_, err := NewFleet([]Profile{{Name: "alice"}})
+	if !errors.Is(err, ErrNoToken) {
+		t.Errorf("Expected ErrNoToken for a profile without token or token_env, got %v", err)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	data := `{"profiles": [
+		{"name": "alice", "token_env": "ALICE_TOKEN"},
+		{"name": "bob", "token": "token-b", "endpoint": "wss://bob.example.com"}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadProfiles failed: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(profiles))
+	}
+	if profiles[0].Name != "alice" || profiles[0].TokenEnv != "ALICE_TOKEN" {
+		t.Errorf("Unexpected first profile: %+v", profiles[0])
+	}
+	if profiles[1].Token != "token-b" || profiles[1].Endpoint != "wss://bob.example.com" {
+		t.Errorf("Unexpected second profile: %+v", profiles[1])
+	}
+
+	if _, err := LoadProfiles(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
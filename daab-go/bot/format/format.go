@@ -0,0 +1,278 @@
+// Package format renders structured output as plain text for direct.
+//
+// direct shows messages as plain text in a proportional font, so the
+// helpers here avoid markup that would be shown literally, except for the
+// code fences that most readers recognize.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fence is the line that opens and closes a code block.
+const Fence = "```"
+
+// CodeBlock wraps text in code fences.
+func CodeBlock(text string) string {
+	return Fence + "\n" + strings.TrimRight(text, "\n") + "\n" + Fence
+}
+
+// Bullets renders items as a bulleted list, one item per line.
+func Bullets(items ...string) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = "・" + item
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Numbered renders items as a numbered list starting at 1.
+func Numbered(items ...string) string {
+	width := len(fmt.Sprint(len(items)))
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = fmt.Sprintf("%*d. %s", width, i+1, item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Quote prefixes each line of text with "> ".
+func Quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// KeyValues renders pairs as "key: value" lines with the values aligned.
+// pairs alternates keys and values; a trailing key without value is ignored.
+func KeyValues(pairs ...string) string {
+	width := 0
+	for i := 0; i+1 < len(pairs); i += 2 {
+		width = max(width, Width(pairs[i]))
+	}
+	var lines []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		lines = append(lines, pad(pairs[i]+":", width+1)+" "+pairs[i+1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Table renders rows as columns separated by " | ", with a rule under the
+// header. Columns are padded by display width, counting East Asian wide
+// characters as two columns; alignment is exact only in monospaced fonts,
+// so the table is wrapped in a code block.
+func Table(header []string, rows [][]string) string {
+	columns := len(header)
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	widths := make([]int, columns)
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], Width(cell))
+		}
+	}
+
+	render := func(row []string) string {
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(row)-1 {
+				cell = pad(cell, widths[i])
+			}
+			cells[i] = cell
+		}
+		return strings.Join(cells, " | ")
+	}
+
+	var lines []string
+	if len(header) > 0 {
+		lines = append(lines, render(header))
+		rule := make([]string, columns)
+		for i, w := range widths {
+			rule[i] = strings.Repeat("-", w)
+		}
+		lines = append(lines, strings.Join(rule, "-+-"))
+	}
+	for _, row := range rows {
+		lines = append(lines, render(row))
+	}
+	return CodeBlock(strings.Join(lines, "\n"))
+}
+
+// Width returns the display width of s, counting East Asian wide and
+// fullwidth characters as two columns.
+func Width(s string) int {
+	w := 0
+	for _, r := range s {
+		if isWide(r) {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return w
+}
+
+func pad(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+func isWide(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f || // Hangul Jamo
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f || // CJK ... Yi
+		r >= 0xac00 && r <= 0xd7a3 || // Hangul syllables
+		r >= 0xf900 && r <= 0xfaff || // CJK compatibility ideographs
+		r >= 0xfe30 && r <= 0xfe4f || // CJK compatibility forms
+		r >= 0xff00 && r <= 0xff60 || // Fullwidth forms
+		r >= 0xffe0 && r <= 0xffe6 ||
+		r >= 0x1f300 && r <= 0x1f64f || // Emoji
+		r >= 0x1f900 && r <= 0x1f9ff ||
+		r >= 0x20000 && r <= 0x3fffd)
+}
+
+// Split splits text into chunks of at most limit characters, breaking at
+// line boundaries where possible. Lines longer than limit are broken
+// between characters. A code block cut by a chunk boundary is closed at
+// the end of the chunk and reopened at the start of the next one, with the
+// language tag of the block if it fits. A line starting with a fence that
+// does not fit in a chunk is split as plain text.
+func Split(text string, limit int) []string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+	// Room for reopening and closing a fence in a chunk.
+	fenceCost := 2 * (utf8.RuneCountInString(Fence) + 1)
+	if limit <= fenceCost {
+		fenceCost = 0
+	}
+
+	var chunks []string
+	var cur []string
+	curLen := 0
+	inFence := false  // inside a code block after the current line
+	fenceLine := ""   // the line that opened the current code block
+	openedIn := false // the current chunk only holds a reopened fence
+
+	flush := func() {
+		reopen := inFence && fenceCost > 0
+		closing := reopen
+		if closing && len(cur) > 0 && (openedIn || cur[len(cur)-1] == fenceLine) {
+			// Move a code block that has just been opened to the next chunk.
+			cur = cur[:len(cur)-1]
+			closing = false
+		}
+		if len(cur) > 0 {
+			chunk := strings.Join(cur, "\n")
+			if closing {
+				chunk += "\n" + Fence
+			}
+			chunks = append(chunks, chunk)
+		}
+		cur, curLen, openedIn = nil, 0, false
+		if reopen {
+			// Leave room for a newline, at least one character and the
+			// closing fence.
+			line := reopenFence(fenceLine, limit-fenceCost/2-2)
+			cur = []string{line}
+			curLen = utf8.RuneCountInString(line)
+			openedIn = true
+		}
+	}
+	add := func(line string) {
+		n := utf8.RuneCountInString(line)
+		if len(cur) > 0 {
+			n++ // newline
+		}
+		cur = append(cur, line)
+		curLen += n
+		openedIn = false
+	}
+	// room returns the space left for a line in the current chunk, keeping
+	// space for a closing fence if the chunk would end inside a code block.
+	room := func(endsInFence bool) int {
+		r := limit - curLen
+		if len(cur) > 0 {
+			r-- // newline
+		}
+		if endsInFence && fenceCost > 0 {
+			r -= fenceCost / 2
+		}
+		return r
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		isFence := isFenceLine(line)
+		endsIn := inFence != isFence // in a code block after this line
+		n := utf8.RuneCountInString(line)
+		if n > room(endsIn) && !openedIn {
+			flush()
+		}
+		if isFence && n > room(endsIn) {
+			// A fence cut in two would leave its rest inside the block.
+			isFence, endsIn = false, inFence
+		}
+		for n > room(endsIn) {
+			if room(endsIn) <= 0 {
+				// Not even part of the line fits after the reopened fence.
+				cur, curLen, openedIn = nil, 0, false
+			}
+			head, rest := splitRunes(line, room(endsIn))
+			add(head)
+			flush()
+			line, n = rest, n-utf8.RuneCountInString(head)
+		}
+		add(line)
+		if isFence {
+			inFence = !inFence
+			fenceLine = line
+		}
+	}
+	inFence = false
+	flush()
+	return chunks
+}
+
+// isFenceLine reports whether line opens or closes a code block. Like
+// CommonMark, it does not take a line with backticks after the fence, such
+// as inline code at the start of a line, as a fence.
+func isFenceLine(line string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), Fence)
+	return ok && !strings.Contains(rest, "`")
+}
+
+// reopenFence returns the fence reopening the code block opened by line:
+// Fence and the language tag, cut to at most max characters.
+func reopenFence(line string, max int) string {
+	tag := strings.TrimPrefix(strings.TrimSpace(line), Fence)
+	if fields := strings.Fields(tag); len(fields) > 0 {
+		tag = fields[0]
+	} else {
+		tag = ""
+	}
+	head, _ := splitRunes(Fence+tag, max)
+	if utf8.RuneCountInString(head) < utf8.RuneCountInString(Fence) {
+		return Fence
+	}
+	return head
+}
+
+func splitRunes(s string, n int) (string, string) {
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j], s[j:]
+		}
+		i++
+	}
+	return s, ""
+}
@@ -0,0 +1,159 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestLists(t *testing.T) {
+	if got := Bullets("a", "b"); got != "・a\n・b" {
+		t.Errorf("Bullets() = %q", got)
+	}
+	items := make([]string, 10)
+	for i := range items {
+		items[i] = "x"
+	}
+	got := strings.Split(Numbered(items...), "\n")
+	if got[0] != " 1. x" || got[9] != "10. x" {
+		t.Errorf("Expected numbers to be aligned, got %q", got)
+	}
+	if got := Quote("a\nb\n"); got != "> a\n> b" {
+		t.Errorf("Quote() = %q", got)
+	}
+	if got := KeyValues("name", "daab", "バージョン", "1.0"); got != "name:       daab\nバージョン: 1.0" {
+		t.Errorf("KeyValues() = %q", got)
+	}
+	if got := CodeBlock("x := 1\n"); got != "```\nx := 1\n```" {
+		t.Errorf("CodeBlock() = %q", got)
+	}
+}
+
+func TestTable(t *testing.T) {
+	got := Table([]string{"名前", "状態"}, [][]string{
+		{"web", "ok"},
+		{"データベース", "停止", "extra"},
+	})
+	want := "```\n" +
+		"名前         | 状態\n" +
+		"-------------+------+------\n" +
+		"web          | ok\n" +
+		"データベース | 停止 | extra\n" +
+		"```"
+	if got != want {
+		t.Errorf("Table() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	if w := Width("aあＡ한"); w != 7 {
+		t.Errorf("Width() = %d, want 7", w)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	if got := Split("short", 10); len(got) != 1 || got[0] != "short" {
+		t.Errorf("Split() = %q", got)
+	}
+
+	text := "line1\nline2\nline3\nline4"
+	got := Split(text, 12)
+	if strings.Join(got, "|") != "line1\nline2|line3\nline4" {
+		t.Errorf("Expected split at line boundaries, got %q", got)
+	}
+
+	got = Split(strings.Repeat("あ", 25), 10)
+	if len(got) != 3 || got[2] != strings.Repeat("あ", 5) {
+		t.Errorf("Expected long line to be broken, got %q", got)
+	}
+}
+
+func TestSplitCodeBlock(t *testing.T) {
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, "output line")
+	}
+	text := "結果:\n" + CodeBlock(strings.Join(lines, "\n")) + "\n完了"
+
+	chunks := Split(text, 50)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %q", chunks)
+	}
+	var body []string
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 50 {
+			t.Errorf("Chunk %d has %d characters", i, n)
+		}
+		if strings.Count(chunk, Fence)%2 != 0 {
+			t.Errorf("Expected chunk %d to have balanced fences, got %q", i, chunk)
+		}
+		for _, line := range strings.Split(chunk, "\n") {
+			if line == "output line" {
+				body = append(body, line)
+			}
+		}
+	}
+	if len(body) != 10 {
+		t.Errorf("Expected every line to be kept, got %d", len(body))
+	}
+	if !strings.HasSuffix(chunks[len(chunks)-1], "```\n完了") {
+		t.Errorf("Unexpected last chunk %q", chunks[len(chunks)-1])
+	}
+}
+
+func TestSplitLimits(t *testing.T) {
+	text := "見出し\n```go\n" + strings.Repeat("fmt.Println(\"こんにちは\")\n", 8) + "```\n" +
+		strings.Repeat("とても長い行", 20) + "\n" + Bullets("a", "b", "c")
+	for limit := 12; limit <= 120; limit++ {
+		for i, chunk := range Split(text, limit) {
+			if n := utf8.RuneCountInString(chunk); n > limit {
+				t.Fatalf("limit %d: chunk %d has %d characters: %q", limit, i, n, chunk)
+			}
+			if strings.Count(chunk, Fence)%2 != 0 {
+				t.Fatalf("limit %d: chunk %d has unbalanced fences: %q", limit, i, chunk)
+			}
+			if chunk == "" || strings.HasSuffix(chunk, "```go\n```") {
+				t.Fatalf("limit %d: chunk %d is empty: %q", limit, i, chunk)
+			}
+		}
+	}
+}
+
+func TestSplitLongFenceLine(t *testing.T) {
+	text := "```goxxxxxxxxxxxxxxxxxxxxbb日本語テキスト\n" + strings.Repeat("コード\n", 10) + "```\n" +
+		"```" + strings.Repeat("x", 60) + "\n終わり"
+	for _, chunk := range Split(text, 40) {
+		if n := utf8.RuneCountInString(chunk); n > 40 {
+			t.Errorf("Chunk has %d characters: %q", n, chunk)
+		}
+		if strings.HasPrefix(chunk, "```") && !strings.HasPrefix(chunk, "```goxxx") && !strings.HasPrefix(chunk, "```xxx") {
+			t.Errorf("Expected the code block to be reopened with its tag, got %q", chunk)
+		}
+	}
+	if got := reopenFence("```go  extra", 5); got != "```go" {
+		t.Errorf("reopenFence() = %q", got)
+	}
+	if got := reopenFence("```golang", 4); got != "```g" {
+		t.Errorf("reopenFence() = %q", got)
+	}
+}
+
+func TestSplitInlineCode(t *testing.T) {
+	text := "```x```\nplain prose line one\nplain prose line two\nplain prose line three"
+	got := Split(text, 26)
+	want := []string{"```x```", "plain prose line one", "plain prose line two", "plain prose line three"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %q, want %q", got, want)
+	}
+	for _, line := range []string{"```", "  ```go", "```go title"} {
+		if !isFenceLine(line) {
+			t.Errorf("Expected %q to be a fence", line)
+		}
+	}
+	for _, line := range []string{"```x```", "``` a`b", "`` x"} {
+		if isFenceLine(line) {
+			t.Errorf("Expected %q not to be a fence", line)
+		}
+	}
+}
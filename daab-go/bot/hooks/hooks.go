@@ -0,0 +1,314 @@
+// Package hooks serves incoming webhooks that post to direct talks, for
+// tools such as CI or monitoring that can only fire generic webhooks.
+//
+// Each hook has a secret URL, /hooks/<token>, and posts to one talk:
+//
+//	POST /hooks/<token>   {"text": "..."}
+//	                      or a Slack incoming webhook payload, as JSON or
+//	                      as the "payload" field of a form
+//
+// Hooks are kept in a Store, usually managed with "daabgo hooks", and are
+// formatted with an optional template (see Render) and rate limited per hook.
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// DefaultRateLimit is the number of requests per minute accepted by hooks
+// without a rate limit.
+const DefaultRateLimit = 60
+
+// DefaultMaxBodySize is the default limit of a request body, in bytes.
+const DefaultMaxBodySize = 1 << 20
+
+// Server serves the hooks of a store. It is an http.Handler that can be
+// mounted in any server, and a bot.Plugin named "hooks" that listens on its
+// own address while the robot runs.
+type Server struct {
+	robot       *bot.Robot
+	store       *Store
+	addr        string
+	maxBodySize int64
+	mux         *http.ServeMux
+	httpServer  *http.Server
+
+	mu       sync.Mutex
+	limiters map[string]*limiter
+	swept    time.Time
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithStore sets the store of the hooks. The default is NewStore(DefaultFile).
+func WithStore(store *Store) Option {
+	return func(s *Server) {
+		s.store = store
+	}
+}
+
+// WithAddr sets the address the plugin listens on, such as ":8080".
+// Without an address the server only serves requests passed to ServeHTTP.
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithMaxBodySize limits the size of request bodies.
+func WithMaxBodySize(n int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = n
+	}
+}
+
+// New returns a server to be added with Robot.UsePlugins.
+func New(opts ...Option) *Server {
+	s := &Server{maxBodySize: DefaultMaxBodySize, limiters: make(map[string]*limiter)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Name returns "hooks".
+func (s *Server) Name() string { return "hooks" }
+
+// Description describes the plugin.
+func (s *Server) Description() string {
+	return "Incoming Webhook で受け取った通知をトークに投稿します"
+}
+
+// Configure reads the "addr", "file" and "max_body_size" settings.
+// Settings override options.
+func (s *Server) Configure(cfg bot.PluginConfig) error {
+	s.addr = cfg.String("addr", s.addr)
+	if file := cfg.String("file", ""); file != "" {
+		s.store = NewStore(file)
+	}
+	s.maxBodySize = int64(cfg.Int("max_body_size", int(s.maxBodySize)))
+	return nil
+}
+
+// Register binds the server to r.
+func (s *Server) Register(r *bot.Robot) error {
+	s.robot = r
+	if s.store == nil {
+		s.store = NewStore(DefaultFile)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /hooks/{token}", s.handleHook)
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusNotFound, Response{Error: "no such endpoint"})
+	})
+	s.mux = mux
+	return nil
+}
+
+// Start listens on the configured address, if any.
+func (s *Server) Start(ctx context.Context) error {
+	if s.addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.httpServer = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("[DEBUG] incoming webhooks listening on %s (hooks in %s)", ln.Addr(), s.store.Path())
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: incoming webhook server: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the listener down, waiting for requests in progress.
+func (s *Server) Stop(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// ServeHTTP dispatches the request to its hook.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.mux == nil {
+		writeJSON(w, http.StatusServiceUnavailable, Response{Error: "server is not registered with a robot"})
+		return
+	}
+	s.mux.ServeHTTP(w, req)
+}
+
+// Response is the body of every response.
+type Response struct {
+	OK        bool   `json:"ok"`
+	MessageID string `json:"messageId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (s *Server) handleHook(w http.ResponseWriter, req *http.Request) {
+	hook, err := s.store.Get(req.PathValue("token"))
+	if errors.Is(err, ErrNotFound) {
+		s.forgetLimiter(req.PathValue("token"))
+		writeJSON(w, http.StatusNotFound, Response{Error: "no such hook"})
+		return
+	}
+	if err != nil {
+		log.Printf("Warning: incoming webhook: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "could not read the hooks"})
+		return
+	}
+
+	if wait := s.limiter(hook).reserve(); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeJSON(w, http.StatusTooManyRequests, Response{Error: "rate limit exceeded"})
+		return
+	}
+
+	payload, err := s.decode(w, req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+	text, err := Render(hook, payload)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+	if text == "" {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "payload has no text"})
+		return
+	}
+
+	id, err := s.robot.SendTextAsync(hook.TalkID, text).Wait(req.Context())
+	switch {
+	case errors.Is(err, bot.ErrNotConnected):
+		writeJSON(w, http.StatusServiceUnavailable, Response{Error: err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusBadGateway, Response{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, Response{OK: true, MessageID: id})
+	}
+}
+
+// decode reads a JSON body, or the JSON "payload" field of a form as sent
+// by Slack clients.
+func (s *Server) decode(w http.ResponseWriter, req *http.Request) (Payload, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, s.maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(form.Get("payload"))
+	}
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	return p, nil
+}
+
+// limiter returns the rate limiter of hook. Limiters idle for a minute are
+// swept at most once a minute: their bucket is full again, so a new one
+// behaves the same.
+func (s *Server) limiter(hook *Hook) *limiter {
+	perMinute := hook.RateLimit
+	if perMinute == 0 {
+		perMinute = DefaultRateLimit
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := time.Now(); now.Sub(s.swept) >= time.Minute {
+		s.sweepLimiters(now)
+	}
+	l, ok := s.limiters[hook.Token]
+	if !ok || l.perMinute != perMinute {
+		l = newLimiter(perMinute)
+		s.limiters[hook.Token] = l
+	}
+	return l
+}
+
+// sweepLimiters deletes the limiters unused since a minute before now.
+// s.mu must be held.
+func (s *Server) sweepLimiters(now time.Time) {
+	for token, l := range s.limiters {
+		if l.idle(now) {
+			delete(s.limiters, token)
+		}
+	}
+	s.swept = now
+}
+
+// forgetLimiter deletes the limiter of a hook that no longer exists.
+func (s *Server) forgetLimiter(token string) {
+	s.mu.Lock()
+	delete(s.limiters, token)
+	s.mu.Unlock()
+}
+
+// limiter is a token bucket allowing perMinute requests per minute, in
+// bursts of up to perMinute requests.
+type limiter struct {
+	mu        sync.Mutex
+	perMinute int
+	tokens    float64
+	last      time.Time
+}
+
+func newLimiter(perMinute int) *limiter {
+	return &limiter{perMinute: perMinute, tokens: float64(perMinute), last: time.Now()}
+}
+
+// reserve takes a token if one is available, and otherwise returns how long
+// to wait for the next one.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	rate := float64(l.perMinute) / time.Minute.Seconds()
+	l.tokens = math.Min(float64(l.perMinute), l.tokens+now.Sub(l.last).Seconds()*rate)
+	l.last = now
+	if l.tokens < 1 {
+		return time.Duration((1 - l.tokens) / rate * float64(time.Second))
+	}
+	l.tokens--
+	return 0
+}
+
+// idle reports whether the limiter was last used a minute or more before now.
+func (l *limiter) idle(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return now.Sub(l.last) >= time.Minute
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Warning: incoming webhook: writing response: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// readyPlugin signals that the robot is connected.
+type readyPlugin struct {
+	started chan struct{}
+}
+
+func (p *readyPlugin) Name() string                    { return "ready" }
+func (p *readyPlugin) Register(r *bot.Robot) error     { return nil }
+func (p *readyPlugin) Start(ctx context.Context) error { close(p.started); return nil }
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "hooks", "hooks.json"))
+	if hooks, err := store.List(); err != nil || len(hooks) != 0 {
+		t.Fatalf("Expected an empty store, got %v %v", hooks, err)
+	}
+
+	ci, err := store.Create(Hook{Name: "ci", TalkID: "100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ci.Token) != 48 || ci.CreatedAt.IsZero() {
+		t.Errorf("Unexpected hook %+v", ci)
+	}
+	if _, err := store.Create(Hook{Name: "ci", TalkID: "200"}); err == nil {
+		t.Error("Expected duplicate names to be rejected")
+	}
+	if _, err := store.Create(Hook{Name: "bad", TalkID: "200", Template: "{{.text"}); err == nil {
+		t.Error("Expected invalid templates to be rejected")
+	}
+	if _, err := store.Create(Hook{Name: "notalk"}); err == nil {
+		t.Error("Expected a talk ID to be required")
+	}
+	mon, err := store.Create(Hook{TalkID: "200", RateLimit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h, err := store.Get(mon.Token); err != nil || h.TalkID != "200" || h.RateLimit != 10 {
+		t.Errorf("Unexpected hook %+v %v", h, err)
+	}
+	if _, err := store.Get("unknown"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if h, err := store.Revoke("ci"); err != nil || h.Token != ci.Token {
+		t.Errorf("Unexpected revoked hook %+v %v", h, err)
+	}
+	if _, err := store.Revoke(mon.Token); err != nil {
+		t.Error(err)
+	}
+	if _, err := store.Revoke("ci"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if hooks, _ := NewStore(store.Path()).List(); len(hooks) != 0 {
+		t.Errorf("Expected all hooks to be revoked, got %v", hooks)
+	}
+}
+
+func TestRender(t *testing.T) {
+	slack := Payload{
+		"text": "Build <https://ci.example.com/1|#1> failed &lt;main&gt; <!here>",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"title": "main", "title_link": "https://ci.example.com/1",
+				"fields": []interface{}{map[string]interface{}{"title": "Author", "value": "alice"}},
+			},
+			map[string]interface{}{"fallback": "fallback only", "color": "danger"},
+		},
+	}
+	want := "Build #1 (https://ci.example.com/1) failed <main> @here\nmain https://ci.example.com/1\nAuthor: alice\nfallback only"
+	if text, err := Render(&Hook{}, slack); err != nil || text != want {
+		t.Errorf("Unexpected default text %q %v", text, err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"[{{.status}}] {{text .}}", "[down] db"},
+		{"{{.status}}: {{.title}}", "down: db"},
+		{"{{slack .link}}", "db (https://status.example.com)"},
+		{"{{json .count}}", "3"},
+	}
+	p := Payload{"status": "down", "title": "db", "link": "<https://status.example.com|db>", "count": 3.0}
+	for _, tt := range tests {
+		if text, err := Render(&Hook{Template: tt.template}, p); err != nil || text != tt.want {
+			t.Errorf("Render(%q) = %q, %v; want %q", tt.template, text, err, tt.want)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	var n atomic.Int32
+	mockServer.On("create_message", func(params []interface{}) (interface{}, error) {
+		return map[string]interface{}{"message_id": fmt.Sprint("m", n.Add(1))}, nil
+	})
+
+	store := NewStore(filepath.Join(t.TempDir(), "hooks.json"))
+	plain, _ := store.Create(Hook{Name: "plain", TalkID: "100", RateLimit: 2})
+	tmpl, _ := store.Create(Hook{Name: "tmpl", TalkID: "200", Template: "{{.status}}: {{text .}}"})
+
+	robot := bot.New(
+		bot.WithToken("token"),
+		bot.WithEndpoint(mockServer.URL()),
+		bot.WithGlobalRateLimit(0, 0),
+		bot.WithTalkRateLimit(0, 0),
+	)
+	s := New(WithStore(store))
+	ready := &readyPlugin{started: make(chan struct{})}
+	if err := robot.UsePlugins(s, ready); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	select {
+	case <-ready.started:
+	case err := <-done:
+		t.Fatalf("Run failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Robot did not start")
+	}
+
+	post := func(token, contentType, body string) (*httptest.ResponseRecorder, Response) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/hooks/"+token, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		var resp Response
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w, resp
+	}
+
+	if w, resp := post(plain.Token, "application/json", `{"text": "deployed"}`); w.Code != http.StatusOK || resp.MessageID != "m1" {
+		t.Errorf("Unexpected response %d %+v", w.Code, resp)
+	}
+	form := url.Values{"payload": {`{"text": "from a form"}`}}.Encode()
+	if w, _ := post(plain.Token, "application/x-www-form-urlencoded", form); w.Code != http.StatusOK {
+		t.Errorf("Expected form payloads to be accepted, got %d", w.Code)
+	}
+	w, _ := post(plain.Token, "application/json", `{"text": "too many"}`)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Errorf("Expected the rate limit to apply, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w, _ := post(tmpl.Token, "application/json", `{"status": "down", "text": "db"}`); w.Code != http.StatusOK {
+		t.Errorf("Unexpected status %d", w.Code)
+	}
+
+	var sent []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if len(msg) > 3 && msg[2] == "create_message" {
+			params := msg[3].([]interface{})
+			sent = append(sent, fmt.Sprint(params[0], " ", params[2]))
+		}
+	}
+	if strings.Join(sent, "|") != "100 deployed|100 from a form|200 down: db" {
+		t.Errorf("Unexpected messages %q", sent)
+	}
+
+	if w, _ := post("unknown", "application/json", `{"text": "x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown hooks to be rejected, got %d", w.Code)
+	}
+	if w, _ := post(tmpl.Token, "application/json", `not json`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid JSON to be rejected, got %d", w.Code)
+	}
+	empty, _ := store.Create(Hook{TalkID: "300"})
+	if w, _ := post(empty.Token, "application/json", `{"status": "up"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected payloads without text to be rejected, got %d", w.Code)
+	}
+	store.Revoke("tmpl")
+	if w, _ := post(tmpl.Token, "application/json", `{"text": "x"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected revoked hooks to be rejected, got %d", w.Code)
+	}
+	if _, ok := s.limiters[tmpl.Token]; ok {
+		t.Error("Expected the limiter of a revoked hook to be dropped")
+	}
+}
+
+func TestServerSweepsLimiters(t *testing.T) {
+	s := New()
+	now := time.Now()
+	s.limiters["idle"] = &limiter{perMinute: 1, last: now.Add(-time.Minute)}
+	s.limiters["busy"] = &limiter{perMinute: 1, last: now.Add(-time.Second)}
+
+	s.sweepLimiters(now)
+	if _, ok := s.limiters["idle"]; ok {
+		t.Error("Expected the idle limiter to be swept")
+	}
+	if _, ok := s.limiters["busy"]; !ok {
+		t.Error("Expected the recently used limiter to be kept")
+	}
+}
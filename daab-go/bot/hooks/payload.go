@@ -0,0 +1,126 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Payload is a decoded request body. Both simple JSON such as
+// {"text": "..."} and Slack incoming webhook payloads are accepted.
+type Payload map[string]interface{}
+
+// Render formats p with the template of h, or returns its Text if the hook
+// has no template.
+//
+// Templates see the payload fields, such as {{.text}} or
+// {{range .attachments}}{{.title}}{{end}}, and these functions:
+//
+//	text   the default text of a payload, as in {{text .}}
+//	slack  converts Slack markup such as <url|label> to plain text
+//	json   encodes a value as JSON
+func Render(h *Hook, p Payload) (string, error) {
+	if h.Template == "" {
+		return p.Text(), nil
+	}
+	tmpl, err := parseTemplate(h.Template)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, map[string]interface{}(p)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("hook").Funcs(template.FuncMap{
+		"text":  func(p map[string]interface{}) string { return Payload(p).Text() },
+		"slack": slackText,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("hooks: invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Text returns the title and text of p followed by its Slack attachments:
+// their pretext, title and link, text and fields, or their fallback text.
+// Slack markup is converted to plain text.
+func (p Payload) Text() string {
+	var lines []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			lines = append(lines, slackText(s))
+		}
+	}
+	add(str(p["title"]))
+	add(str(p["text"]))
+
+	attachments, _ := p["attachments"].([]interface{})
+	for _, a := range attachments {
+		a, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		n := len(lines)
+		add(str(a["pretext"]))
+		add(strings.TrimSpace(str(a["title"]) + " " + str(a["title_link"])))
+		add(str(a["text"]))
+		fields, _ := a["fields"].([]interface{})
+		for _, f := range fields {
+			if f, ok := f.(map[string]interface{}); ok {
+				add(str(f["title"]) + ": " + str(f["value"]))
+			}
+		}
+		if len(lines) == n {
+			add(str(a["fallback"]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// str returns v as a string, or "" for missing values.
+func str(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var slackLink = regexp.MustCompile(`<([^<>|]+)(?:\|([^<>]+))?>`)
+
+// slackText converts Slack links and mentions to plain text: <url|label>
+// becomes "label (url)", <url> the URL and <!here> "@here".
+func slackText(s string) string {
+	s = slackLink.ReplaceAllStringFunc(s, func(m string) string {
+		parts := slackLink.FindStringSubmatch(m)
+		target, label := parts[1], parts[2]
+		switch {
+		case strings.HasPrefix(target, "!"):
+			return "@" + strings.TrimPrefix(target, "!")
+		case strings.HasPrefix(target, "@") || strings.HasPrefix(target, "#"):
+			if label != "" {
+				return target[:1] + label
+			}
+			return target
+		case label != "":
+			return label + " (" + target + ")"
+		default:
+			return target
+		}
+	})
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(s)
+}
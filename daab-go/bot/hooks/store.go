@@ -0,0 +1,185 @@
+package hooks
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultFile is the default path of the hook store.
+const DefaultFile = "daabgo-hooks.json"
+
+// ErrNotFound is returned for hooks that do not exist.
+var ErrNotFound = errors.New("hooks: no such hook")
+
+// Hook is an incoming webhook posting to a talk.
+type Hook struct {
+	// Token is the secret part of the hook URL, /hooks/<token>.
+	Token string `json:"token"`
+
+	// Name identifies the hook in the management commands.
+	Name string `json:"name,omitempty"`
+
+	// TalkID is the talk the hook posts to.
+	TalkID string `json:"talkId"`
+
+	// Template formats the payload with text/template; see Render.
+	// Without a template the text is taken from the payload.
+	Template string `json:"template,omitempty"`
+
+	// RateLimit is the number of requests accepted per minute, with bursts
+	// of as many requests. 0 means DefaultRateLimit.
+	RateLimit int `json:"rateLimit,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Store keeps hooks in a JSON file, so that the management commands and a
+// running server share them. The file is read on every lookup, so changes
+// take effect without restarting the server.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store in the file at path. The file is created when
+// the first hook is added.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the path of the store file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns the hooks in the order they were created.
+func (s *Store) List() ([]Hook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Get returns the hook with token.
+func (s *Store) Get(token string) (*Hook, error) {
+	hooks, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hooks {
+		if subtle.ConstantTimeCompare([]byte(h.Token), []byte(token)) == 1 {
+			return &h, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Create adds h, generating its token, and returns it. Names must be
+// unique and templates must parse.
+func (s *Store) Create(h Hook) (*Hook, error) {
+	if h.TalkID == "" {
+		return nil, errors.New("hooks: a talk ID is required")
+	}
+	if h.RateLimit < 0 {
+		return nil, errors.New("hooks: the rate limit must not be negative")
+	}
+	if h.Template != "" {
+		if _, err := parseTemplate(h.Template); err != nil {
+			return nil, err
+		}
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	h.Token = token
+	h.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hooks, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range hooks {
+		if h.Name != "" && other.Name == h.Name {
+			return nil, fmt.Errorf("hooks: a hook named %q already exists", h.Name)
+		}
+	}
+	if err := s.write(append(hooks, h)); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// Revoke removes the hook with the given name or token and returns it.
+func (s *Store) Revoke(nameOrToken string) (*Hook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hooks, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for i, h := range hooks {
+		if h.Token == nameOrToken || h.Name != "" && h.Name == nameOrToken {
+			if err := s.write(append(hooks[:i:i], hooks[i+1:]...)); err != nil {
+				return nil, err
+			}
+			return &h, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *Store) read() ([]Hook, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hooks []Hook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("hooks: reading %s: %w", s.path, err)
+	}
+	return hooks, nil
+}
+
+// write replaces the file through a temporary file, so that a crash never
+// leaves a partial store behind. The file holds secrets and is private.
+func (s *Store) write(hooks []Hook) error {
+	if hooks == nil {
+		hooks = []Hook{}
+	}
+	data, err := json.MarshalIndent(hooks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// newToken returns a random URL-safe token.
+func newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
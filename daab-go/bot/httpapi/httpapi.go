@@ -0,0 +1,468 @@
+// Package httpapi serves an authenticated HTTP API that lets other systems,
+// such as n8n workflows, post into direct through a robot.
+//
+// The API exposes these endpoints; request and response bodies are JSON
+// with the same camelCase names and error codes as package webhook:
+//
+//	POST /v1/talks/{talkID}/text    {"text": "..."}
+//	POST /v1/talks/{talkID}/stamp   {"stampSet": "...", "stampIndex": "...", "text": "..."}
+//	POST /v1/talks/{talkID}/file    {"name": "...", "contentType": "...", "data": "<base64>"}
+//	                                or a multipart form with a "file" part
+//	POST /v1/talks/{talkID}/select  {"question": "...", "options": ["...", "..."]}
+//	POST /v1/talks/{talkID}/yesno   {"question": "..."}
+//	POST /v1/talks/{talkID}/task    {"title": "..."}
+//	GET  /v1/talks
+//	GET  /v1/domains/{domainID}/users/{userID}
+//
+// Every request must carry a bearer token (Authorization: Bearer <token>)
+// or an HMAC signature of its body (see webhook.Sign).
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+)
+
+// DefaultMaxBodySize is the default limit of a request body, in bytes.
+const DefaultMaxBodySize = 10 << 20
+
+// Server is the HTTP API of a robot. It is an http.Handler that can be
+// mounted in any server, and a bot.Plugin named "httpapi" that listens on
+// its own address while the robot runs.
+type Server struct {
+	robot       *bot.Robot
+	addr        string
+	token       string
+	secret      []byte
+	tolerance   time.Duration
+	maxBodySize int64
+	mux         *http.ServeMux
+	httpServer  *http.Server
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAddr sets the address the plugin listens on, such as ":8080".
+// Without an address the server only serves requests passed to ServeHTTP.
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithBearerToken accepts requests with "Authorization: Bearer <token>".
+func WithBearerToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithHMACSecret accepts requests whose body is signed with secret; see
+// webhook.Verify. tolerance bounds the age of the signature timestamp.
+func WithHMACSecret(secret string, tolerance time.Duration) Option {
+	return func(s *Server) {
+		s.secret = []byte(secret)
+		s.tolerance = tolerance
+	}
+}
+
+// WithMaxBodySize limits the size of request bodies.
+func WithMaxBodySize(n int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = n
+	}
+}
+
+// New returns a server to be added with Robot.UsePlugins.
+func New(opts ...Option) *Server {
+	s := &Server{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewHandler returns a server for r, to be mounted in another HTTP server.
+func NewHandler(r *bot.Robot, opts ...Option) (*Server, error) {
+	s := New(opts...)
+	if err := s.Register(r); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns "httpapi".
+func (s *Server) Name() string { return "httpapi" }
+
+// Description describes the plugin.
+func (s *Server) Description() string {
+	return "HTTP API でトークへの送信やユーザーの検索を受け付けます"
+}
+
+// Configure reads the "addr", "token", "hmac_secret", "tolerance" and
+// "max_body_size" settings. Settings override options.
+func (s *Server) Configure(cfg bot.PluginConfig) error {
+	s.addr = cfg.String("addr", s.addr)
+	s.token = cfg.String("token", s.token)
+	if secret, ok := cfg.Lookup("hmac_secret"); ok {
+		s.secret = []byte(secret)
+	}
+	s.tolerance = cfg.Duration("tolerance", s.tolerance)
+	s.maxBodySize = int64(cfg.Int("max_body_size", int(s.maxBodySize)))
+	return nil
+}
+
+// Register binds the server to r. It fails if no authentication is configured.
+func (s *Server) Register(r *bot.Robot) error {
+	if s.token == "" && len(s.secret) == 0 {
+		return errors.New("httpapi: a bearer token or an HMAC secret is required")
+	}
+	s.robot = r
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/talks/{talkID}/text", s.handleText)
+	mux.HandleFunc("POST /v1/talks/{talkID}/stamp", s.handleStamp)
+	mux.HandleFunc("POST /v1/talks/{talkID}/file", s.handleFile)
+	mux.HandleFunc("POST /v1/talks/{talkID}/select", s.handleSelect)
+	mux.HandleFunc("POST /v1/talks/{talkID}/yesno", s.handleYesNo)
+	mux.HandleFunc("POST /v1/talks/{talkID}/task", s.handleTask)
+	mux.HandleFunc("GET /v1/talks", s.handleTalks)
+	mux.HandleFunc("GET /v1/domains/{domainID}/users/{userID}", s.handleUser)
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		writeError(w, http.StatusNotFound, webhook.ErrorCodeNotFound, "no such endpoint")
+	})
+	s.mux = mux
+	return nil
+}
+
+// Start listens on the configured address, if any.
+func (s *Server) Start(ctx context.Context) error {
+	if s.addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.httpServer = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("[DEBUG] HTTP API listening on %s", ln.Addr())
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: HTTP API server: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the listener down, waiting for requests in progress.
+func (s *Server) Stop(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+// ServeHTTP authenticates the request and dispatches it to an endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.mux == nil {
+		writeError(w, http.StatusServiceUnavailable, webhook.ErrorCodeNotConnected, "server is not registered with a robot")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, s.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, webhook.ErrorCodeTooLarge, fmt.Sprintf("body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeInvalidJSON, err.Error())
+		return
+	}
+	if !s.authenticate(req, body) {
+		writeError(w, http.StatusUnauthorized, webhook.ErrorCodeUnauthorized, "missing or invalid credentials")
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	s.mux.ServeHTTP(w, req)
+}
+
+// authenticate reports whether req carries the bearer token or a valid
+// signature of body.
+func (s *Server) authenticate(req *http.Request, body []byte) bool {
+	if s.token != "" {
+		if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return true
+		}
+	}
+	if len(s.secret) > 0 {
+		err := webhook.Verify(s.secret, req.Header.Get(webhook.SignatureHeader), req.Header.Get(webhook.TimestampHeader), body, s.tolerance)
+		if err == nil {
+			return true
+		}
+		log.Printf("[DEBUG] HTTP API: %v", err)
+	}
+	return false
+}
+
+// SendRequest is the body of the send endpoints. Each endpoint reads the
+// fields it needs.
+type SendRequest struct {
+	Text        string   `json:"text,omitempty"`
+	StampSet    string   `json:"stampSet,omitempty"`
+	StampIndex  string   `json:"stampIndex,omitempty"`
+	Question    string   `json:"question,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	ContentType string   `json:"contentType,omitempty"`
+	Data        []byte   `json:"data,omitempty"`
+}
+
+// Response is the body of every response. OK is false when ErrorCode and
+// Error describe a failure.
+type Response struct {
+	OK        bool              `json:"ok"`
+	MessageID string            `json:"messageId,omitempty"`
+	Talks     []TalkData        `json:"talks,omitempty"`
+	User      *webhook.UserData `json:"user,omitempty"`
+	ErrorCode webhook.ErrorCode `json:"errorCode,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// TalkData describes a talk in the talk list.
+type TalkData struct {
+	ID       string   `json:"id"`
+	DomainID string   `json:"domainId"`
+	Type     int      `json:"type"`
+	Name     string   `json:"name,omitempty"`
+	UserIDs  []string `json:"userIds"`
+}
+
+func (s *Server) handleText(w http.ResponseWriter, req *http.Request) {
+	body, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	if body.Text == "" {
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingText, "text is required")
+		return
+	}
+	id, err := s.robot.SendTextAsync(req.PathValue("talkID"), body.Text).Wait(req.Context())
+	writeSent(w, id, err)
+}
+
+func (s *Server) handleStamp(w http.ResponseWriter, req *http.Request) {
+	body, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	if body.StampSet == "" || body.StampIndex == "" {
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingStamp, "stampSet and stampIndex are required")
+		return
+	}
+	id, err := s.robot.SendStampContext(req.Context(), req.PathValue("talkID"), body.StampSet, body.StampIndex, body.Text)
+	writeSent(w, id, err)
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, req *http.Request) {
+	var body SendRequest
+	if mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		var err error
+		if body, err = readMultipartFile(req.Body, params["boundary"]); err != nil {
+			writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingFile, err.Error())
+			return
+		}
+	} else {
+		var ok bool
+		if body, ok = decode(w, req); !ok {
+			return
+		}
+	}
+	if body.Name == "" || len(body.Data) == 0 {
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingFile, "name and data are required")
+		return
+	}
+	if body.ContentType == "" {
+		body.ContentType = http.DetectContentType(body.Data)
+	}
+	id, err := s.robot.SendFile(req.Context(), req.PathValue("talkID"), body.Name, body.ContentType, body.Data)
+	writeSent(w, id, err)
+}
+
+func (s *Server) handleSelect(w http.ResponseWriter, req *http.Request) {
+	body, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	switch {
+	case body.Question == "":
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingQuestion, "question is required")
+		return
+	case len(body.Options) < 2:
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingOptions, "at least two options are required")
+		return
+	}
+	id, err := s.robot.SendSelectContext(req.Context(), req.PathValue("talkID"), body.Question, body.Options)
+	writeSent(w, id, err)
+}
+
+func (s *Server) handleYesNo(w http.ResponseWriter, req *http.Request) {
+	body, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	if body.Question == "" {
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingQuestion, "question is required")
+		return
+	}
+	id, err := s.robot.SendYesNoContext(req.Context(), req.PathValue("talkID"), body.Question)
+	writeSent(w, id, err)
+}
+
+func (s *Server) handleTask(w http.ResponseWriter, req *http.Request) {
+	body, ok := decode(w, req)
+	if !ok {
+		return
+	}
+	if body.Title == "" {
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeMissingTitle, "title is required")
+		return
+	}
+	id, err := s.robot.SendTaskContext(req.Context(), req.PathValue("talkID"), body.Title)
+	writeSent(w, id, err)
+}
+
+func (s *Server) handleTalks(w http.ResponseWriter, req *http.Request) {
+	talks, err := s.robot.Talks(req.Context())
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	resp := Response{OK: true, Talks: make([]TalkData, 0, len(talks))}
+	for _, talk := range talks {
+		data := TalkData{
+			ID:       fmt.Sprint(talk.ID),
+			DomainID: fmt.Sprint(talk.DomainID),
+			Type:     talk.Type,
+			Name:     talk.Name,
+			UserIDs:  make([]string, len(talk.UserIDs)),
+		}
+		for i, id := range talk.UserIDs {
+			data.UserIDs[i] = fmt.Sprint(id)
+		}
+		resp.Talks = append(resp.Talks, data)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, req *http.Request) {
+	user, err := s.robot.LookupUser(req.Context(), req.PathValue("domainID"), req.PathValue("userID"))
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{OK: true, User: &webhook.UserData{
+		ID:          fmt.Sprint(user.ID),
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Name:        user.Name,
+	}})
+}
+
+// decode reads the JSON body of req, writing an error response on failure.
+func decode(w http.ResponseWriter, req *http.Request) (SendRequest, bool) {
+	var body SendRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, webhook.ErrorCodeInvalidJSON, err.Error())
+		return body, false
+	}
+	return body, true
+}
+
+// readMultipartFile reads the "file" part of a multipart form, and the
+// optional "name" and "contentType" fields overriding its headers.
+func readMultipartFile(r io.Reader, boundary string) (SendRequest, error) {
+	var body SendRequest
+	mr := multipart.NewReader(r, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body, err
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return body, err
+		}
+		switch part.FormName() {
+		case "file":
+			body.Data = data
+			if body.Name == "" {
+				body.Name = part.FileName()
+			}
+			if body.ContentType == "" && part.Header.Get("Content-Type") != "application/octet-stream" {
+				body.ContentType = part.Header.Get("Content-Type")
+			}
+		case "name":
+			body.Name = string(data)
+		case "contentType":
+			body.ContentType = string(data)
+		}
+	}
+	if body.Data == nil {
+		return body, errors.New(`multipart form has no "file" part`)
+	}
+	return body, nil
+}
+
+func writeSent(w http.ResponseWriter, messageID string, err error) {
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{OK: true, MessageID: messageID})
+}
+
+// writeFailure maps an error from the robot to a response.
+func writeFailure(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, bot.ErrNotFound):
+		writeError(w, http.StatusNotFound, webhook.ErrorCodeNotFound, err.Error())
+	case errors.Is(err, bot.ErrNotConnected):
+		writeError(w, http.StatusServiceUnavailable, webhook.ErrorCodeNotConnected, err.Error())
+	default:
+		writeError(w, http.StatusBadGateway, webhook.ErrorCodeSendFailed, err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code webhook.ErrorCode, message string) {
+	writeJSON(w, status, Response{ErrorCode: code, Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Warning: HTTP API: writing response: %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// readyPlugin signals that the robot is connected.
+type readyPlugin struct {
+	started chan struct{}
+}
+
+func (p *readyPlugin) Name() string                    { return "ready" }
+func (p *readyPlugin) Register(r *bot.Robot) error     { return nil }
+func (p *readyPlugin) Start(ctx context.Context) error { close(p.started); return nil }
+
+// newTestServer runs a robot connected to a mock server and serves its API.
+func newTestServer(t *testing.T, opts ...Option) (*httptest.Server, *testutil.MockServer) {
+	t.Helper()
+
+	mockServer := testutil.NewMockServer()
+	t.Cleanup(mockServer.Close)
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m1"})
+
+	robot := bot.New(
+		bot.WithToken("token"),
+		bot.WithEndpoint(mockServer.URL()),
+		bot.WithGlobalRateLimit(0, 0),
+		bot.WithTalkRateLimit(0, 0),
+	)
+	server := New(opts...)
+	ready := &readyPlugin{started: make(chan struct{})}
+	if err := robot.UsePlugins(server, ready); err != nil {
+		t.Fatalf("UsePlugins failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	select {
+	case <-ready.started:
+	case err := <-done:
+		t.Fatalf("Run failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Robot did not start")
+	}
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer, mockServer
+}
+
+func do(t *testing.T, req *http.Request) (int, Response) {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body Response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.StatusCode, body
+}
+
+func request(t *testing.T, method, url, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// lastCreateMessage returns the parameters of the last create_message call.
+func lastCreateMessage(mockServer *testutil.MockServer) []interface{} {
+	var params []interface{}
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if msg[2] == "create_message" {
+			params = msg[3].([]interface{})
+		}
+	}
+	return params
+}
+
+func TestSendEndpoints(t *testing.T) {
+	httpServer, mockServer := newTestServer(t, WithBearerToken("secret"))
+
+	tests := []struct {
+		path    string
+		body    string
+		msgType string
+	}{
+		{"text", `{"text": "こんにちは"}`, "1"},
+		{"stamp", `{"stampSet": "3", "stampIndex": "1152921507291203198"}`, "2"},
+		{"select", `{"question": "どれ?", "options": ["A", "B"]}`, "502"},
+		{"yesno", `{"question": "OK?"}`, "500"},
+		{"task", `{"title": "レビュー"}`, "504"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			status, body := do(t, request(t, http.MethodPost, httpServer.URL+"/v1/talks/100/"+tt.path, tt.body))
+			if status != http.StatusOK || !body.OK || body.MessageID != "m1" {
+				t.Fatalf("Unexpected response %d %+v", status, body)
+			}
+			params := lastCreateMessage(mockServer)
+			if fmt.Sprint(params[0], " ", params[1]) != "100 "+tt.msgType {
+				t.Errorf("Expected a message of type %s to talk 100, got %v", tt.msgType, params)
+			}
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	httpServer, _ := newTestServer(t, WithBearerToken("secret"), WithMaxBodySize(64))
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		code   webhook.ErrorCode
+	}{
+		{"/v1/talks/100/text", `{}`, http.StatusBadRequest, webhook.ErrorCodeMissingText},
+		{"/v1/talks/100/text", `{"text":`, http.StatusBadRequest, webhook.ErrorCodeInvalidJSON},
+		{"/v1/talks/100/stamp", `{"stampSet": "3"}`, http.StatusBadRequest, webhook.ErrorCodeMissingStamp},
+		{"/v1/talks/100/select", `{"question": "どれ?", "options": ["A"]}`, http.StatusBadRequest, webhook.ErrorCodeMissingOptions},
+		{"/v1/talks/100/yesno", `{}`, http.StatusBadRequest, webhook.ErrorCodeMissingQuestion},
+		{"/v1/talks/100/task", `{}`, http.StatusBadRequest, webhook.ErrorCodeMissingTitle},
+		{"/v1/talks/100/file", `{"name": "a.txt"}`, http.StatusBadRequest, webhook.ErrorCodeMissingFile},
+		{"/v1/talks/100/text", `{"text": "` + strings.Repeat("a", 100) + `"}`, http.StatusRequestEntityTooLarge, webhook.ErrorCodeTooLarge},
+		{"/v1/unknown", `{}`, http.StatusNotFound, webhook.ErrorCodeNotFound},
+	}
+	for _, tt := range tests {
+		status, body := do(t, request(t, http.MethodPost, httpServer.URL+tt.path, tt.body))
+		if status != tt.status || body.OK || body.ErrorCode != tt.code || body.Error == "" {
+			t.Errorf("POST %s %s: expected %d %s, got %d %+v", tt.path, tt.body, tt.status, tt.code, status, body)
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	httpServer, _ := newTestServer(t, WithBearerToken("secret"), WithHMACSecret("hmac", time.Minute))
+	url := httpServer.URL + "/v1/talks/100/text"
+	body := `{"text": "hi"}`
+
+	req := request(t, http.MethodPost, url, body)
+	req.Header.Del("Authorization")
+	if status, resp := do(t, req); status != http.StatusUnauthorized || resp.ErrorCode != webhook.ErrorCodeUnauthorized {
+		t.Errorf("Expected an unauthenticated request to be rejected, got %d %+v", status, resp)
+	}
+
+	req.Header.Set("Authorization", "Bearer wrong")
+	if status, _ := do(t, req); status != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong token to be rejected, got %d", status)
+	}
+
+	sign := func(at time.Time) *http.Request {
+		req := request(t, http.MethodPost, url, body)
+		req.Header.Del("Authorization")
+		req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign([]byte("hmac"), at.Unix(), []byte(body)))
+		return req
+	}
+	if status, resp := do(t, sign(time.Now())); status != http.StatusOK {
+		t.Errorf("Expected a signed request to be accepted, got %d %+v", status, resp)
+	}
+	if status, _ := do(t, sign(time.Now().Add(-time.Hour))); status != http.StatusUnauthorized {
+		t.Errorf("Expected an old signature to be rejected, got %d", status)
+	}
+
+	if err := New().Register(bot.New()); err == nil {
+		t.Error("Expected Register to require authentication")
+	}
+}
+
+func TestTalksAndUsers(t *testing.T) {
+	httpServer, mockServer := newTestServer(t, WithBearerToken("secret"))
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{"talk_id": uint64(100), "domain_id": uint64(1), "type": int8(2), "name": "開発", "user_ids": []interface{}{uint64(42)}},
+	})
+	mockServer.OnSimple("get_users", []interface{}{
+		map[string]interface{}{"id": uint64(42), "display_name": "Alice"},
+	})
+
+	status, body := do(t, request(t, http.MethodGet, httpServer.URL+"/v1/talks", ""))
+	if status != http.StatusOK || len(body.Talks) != 1 {
+		t.Fatalf("Unexpected response %d %+v", status, body)
+	}
+	if talk := body.Talks[0]; talk.ID != "100" || talk.DomainID != "1" || talk.Name != "開発" || fmt.Sprint(talk.UserIDs) != "[42]" {
+		t.Errorf("Unexpected talk %+v", talk)
+	}
+
+	status, body = do(t, request(t, http.MethodGet, httpServer.URL+"/v1/domains/1/users/42", ""))
+	if status != http.StatusOK || body.User == nil || body.User.ID != "42" || body.User.DisplayName != "Alice" {
+		t.Errorf("Unexpected response %d %+v", status, body)
+	}
+
+	status, body = do(t, request(t, http.MethodGet, httpServer.URL+"/v1/domains/1/users/7", ""))
+	if status != http.StatusNotFound || body.ErrorCode != webhook.ErrorCodeNotFound {
+		t.Errorf("Expected an unknown user to be not found, got %d %+v", status, body)
+	}
+}
+
+func TestSendFile(t *testing.T) {
+	var uploaded string
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploaded = string(body)
+	}))
+	defer storage.Close()
+
+	httpServer, mockServer := newTestServer(t, WithBearerToken("secret"))
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{"talk_id": uint64(100), "domain_id": uint64(1), "type": int8(2)},
+	})
+	mockServer.OnSimple("create_upload_auth", map[string]interface{}{
+		"file_id": uint64(9),
+		"put_url": storage.URL,
+		"get_url": storage.URL + "/file",
+	})
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, _ := mw.CreateFormFile("file", "report.csv")
+	part.Write([]byte("a,b\n1,2\n"))
+	mw.Close()
+
+	req := request(t, http.MethodPost, httpServer.URL+"/v1/talks/100/file", form.String())
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if status, body := do(t, req); status != http.StatusOK || body.MessageID != "m1" {
+		t.Fatalf("Unexpected response %d %+v", status, body)
+	}
+	if uploaded != "a,b\n1,2\n" {
+		t.Errorf("Expected the file to be uploaded, got %q", uploaded)
+	}
+	content, ok := lastCreateMessage(mockServer)[2].(map[string]interface{})
+	if !ok || content["name"] != "report.csv" {
+		t.Errorf("Expected a file message, got %v", content)
+	}
+
+	// JSON bodies carry the data as base64.
+	req = request(t, http.MethodPost, httpServer.URL+"/v1/talks/100/file", `{"name": "a.txt", "data": "aGVsbG8="}`)
+	if status, body := do(t, req); status != http.StatusOK {
+		t.Fatalf("Unexpected response %d %+v", status, body)
+	}
+	if uploaded != "hello" {
+		t.Errorf("Expected the decoded data to be uploaded, got %q", uploaded)
+	}
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot/format"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// DefaultMaxMessageLength is the default number of characters per message
+// used by SendLongText.
+const DefaultMaxMessageLength = 1024
+
+// LongTextOption configures SendLongText.
+type LongTextOption func(*longTextConfig)
+
+type longTextConfig struct {
+	maxLength     int
+	fileThreshold int
+	fileName      string
+}
+
+// WithMaxLength sets the number of characters per message.
+func WithMaxLength(n int) LongTextOption {
+	return func(c *longTextConfig) {
+		c.maxLength = n
+	}
+}
+
+// WithFileFallback uploads the text as a file named name instead of sending
+// messages when it is longer than threshold characters.
+func WithFileFallback(threshold int, name string) LongTextOption {
+	return func(c *longTextConfig) {
+		c.fileThreshold = threshold
+		c.fileName = name
+	}
+}
+
+// SendLongText sends text to a room, split into messages at line boundaries
+// so that each stays within the message length limit. Code blocks cut by a
+// split are closed and reopened (see format.Split). With WithFileFallback,
+// text above the threshold is uploaded as a text file instead.
+func (r *Robot) SendLongText(ctx context.Context, roomID, text string, opts ...LongTextOption) error {
+	cfg := longTextConfig{maxLength: DefaultMaxMessageLength}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if r.client == nil {
+		return ErrNotConnected
+	}
+
+	if cfg.fileThreshold > 0 && utf8.RuneCountInString(text) > cfg.fileThreshold {
+		return r.sendTextFile(ctx, roomID, cfg.fileName, text)
+	}
+
+	var results []*SendResult
+	for _, chunk := range format.Split(text, cfg.maxLength) {
+		results = append(results, r.EnqueueContext(ctx, roomID, direct.MsgTypeText, chunk))
+	}
+	for i, result := range results {
+		if _, err := result.Wait(ctx); err != nil {
+			return fmt.Errorf("daab: sending part %d/%d: %w", i+1, len(results), err)
+		}
+	}
+	return nil
+}
+
+// SendLongText sends text to the same room; see Robot.SendLongText.
+func (r Response) SendLongText(ctx context.Context, text string, opts ...LongTextOption) error {
+	return r.Robot.SendLongText(ctx, r.Message.TalkID, text, opts...)
+}
+
+// sendTextFile uploads text as a file and posts it.
+func (r *Robot) sendTextFile(ctx context.Context, roomID, name, text string) error {
+	if name == "" {
+		name = "message.txt"
+	}
+	_, err := r.SendFile(ctx, roomID, name, "text/plain; charset=utf-8", []byte(text))
+	return err
+}
+
+// SendFile uploads data as a file in the talk's domain, posts it to the
+// room and returns the created message ID.
+func (r *Robot) SendFile(ctx context.Context, roomID, name, contentType string, data []byte) (string, error) {
+	if r.client == nil {
+		return "", ErrNotConnected
+	}
+	talk, err := r.LookupTalk(ctx, roomID)
+	if err != nil {
+		return "", fmt.Errorf("daab: looking up domain of talk %s: %w", roomID, err)
+	}
+	file, err := r.client.UploadFile(ctx, talk.DomainID, name, contentType, data)
+	if err != nil {
+		return "", err
+	}
+	return r.EnqueueContext(ctx, roomID, direct.MsgTypeFile, file.Content()).Wait(ctx)
+}
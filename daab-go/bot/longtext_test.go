@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSendLongText(t *testing.T) {
+	robot, mockServer := newTestRobot(t)
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, strings.Repeat("あ", 9))
+	}
+	text := strings.Join(lines, "\n")
+	if err := robot.SendLongText(context.Background(), "100", text, WithMaxLength(100)); err != nil {
+		t.Fatalf("SendLongText failed: %v", err)
+	}
+
+	texts := sentTexts(mockServer)
+	if len(texts) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(texts))
+	}
+	for _, sent := range texts {
+		if n := utf8.RuneCountInString(sent); n > 100 {
+			t.Errorf("Expected messages of at most 100 characters, got %d", n)
+		}
+	}
+	if strings.Join(texts, "\n") != text {
+		t.Error("Expected messages to join back to the original text")
+	}
+}
+
+func TestSendLongTextFileFallback(t *testing.T) {
+	var uploaded string
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploaded = string(body)
+	}))
+	defer storage.Close()
+
+	robot, mockServer := newTestRobot(t)
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{"talk_id": uint64(100), "domain_id": uint64(1), "type": int8(2)},
+	})
+	mockServer.OnSimple("create_upload_auth", map[string]interface{}{
+		"file_id": uint64(9),
+		"put_url": storage.URL,
+		"get_url": storage.URL + "/file",
+	})
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	text := strings.Repeat("log line\n", 50)
+	if err := robot.SendLongText(context.Background(), "100", text, WithFileFallback(200, "build.log")); err != nil {
+		t.Fatalf("SendLongText failed: %v", err)
+	}
+	if uploaded != text {
+		t.Errorf("Expected text to be uploaded, got %d bytes", len(uploaded))
+	}
+
+	msgs := mockServer.GetReceivedMessages()
+	var params []interface{}
+	for _, msg := range msgs {
+		if msg[2] == "create_message" {
+			params = msg[3].([]interface{})
+		}
+	}
+	content, ok := params[2].(map[string]interface{})
+	if !ok || content["name"] != "build.log" {
+		t.Errorf("Expected a file message, got %v", params)
+	}
+
+	// Short text is still sent as a message.
+	if err := robot.SendLongText(context.Background(), "100", "short", WithFileFallback(200, "build.log")); err != nil {
+		t.Fatalf("SendLongText failed: %v", err)
+	}
+	if texts := sentTexts(mockServer); len(texts) != 1 || texts[0] != "short" {
+		t.Errorf("Expected short text as a message, got %q", texts)
+	}
+}
@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestRespondMatchesMention(t *testing.T) {
+	robot := New(WithName("testbot"))
+	robot.setSelfID("999")
+	robot.selfName = "Daab Bot"
+
+	got := make(chan []string, 10)
+	robot.Respond(`ping (\w+)`, func(ctx context.Context, res Response) {
+		got <- res.Match
+	})
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"{@:999,9}@Daab Bot ping alpha", true},
+		{"  {@:999,9}@Daab Bot: ping alpha", true},
+		{"@Daab Bot ping alpha", true},
+		{"daab bot, ping alpha", true},
+		{"@testbot ping alpha", true},
+		{"{@:111,4}@bob ping alpha", false},
+		{"ping alpha {@:999,9}@Daab Bot", false},
+		{"ping alpha", false},
+		{"testbotping alpha", false},
+		{"@testbotty ping alpha", false},
+	}
+	for _, tt := range tests {
+		robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: tt.text})
+		select {
+		case match := <-got:
+			if !tt.want {
+				t.Errorf("%q: expected no match, got %v", tt.text, match)
+			} else if len(match) != 2 || match[1] != "alpha" {
+				t.Errorf("%q: unexpected match %v", tt.text, match)
+			}
+		case <-time.After(50 * time.Millisecond):
+			if tt.want {
+				t.Errorf("%q: expected handler to be called", tt.text)
+			}
+		}
+	}
+}
+
+func TestRespondNameWithMetacharacters(t *testing.T) {
+	robot := New(WithName("bot("))
+	robot.Respond("ping", func(ctx context.Context, res Response) {})
+	if _, ok := robot.listeners[0].matches(direct.ReceivedMessage{Text: "bot( ping"}); !ok {
+		t.Error("Expected a name with regexp metacharacters to address the robot")
+	}
+}
+
+func TestRespondNameBoundary(t *testing.T) {
+	robot := New(WithName("bot"))
+	robot.Respond("ping", func(ctx context.Context, res Response) {})
+	robot.Respond("tle", func(ctx context.Context, res Response) {})
+	for _, text := range []string{"botping", "bottle"} {
+		for _, l := range robot.listeners {
+			if _, ok := l.matches(direct.ReceivedMessage{Text: text}); ok {
+				t.Errorf("Expected %q not to address the robot", text)
+			}
+		}
+	}
+	for _, text := range []string{"bot ping", "bot,ping", "bot:ping", "@bot\tping"} {
+		if _, ok := robot.listeners[0].matches(direct.ReceivedMessage{Text: text}); !ok {
+			t.Errorf("Expected %q to address the robot", text)
+		}
+	}
+}
+
+func TestMentionsRobot(t *testing.T) {
+	robot := New()
+	robot.setSelfID("999")
+
+	res := Response{
+		Message: direct.ReceivedMessage{Text: "hi {@:999,4}@bot and {@:111,4}@bob"},
+		Robot:   robot,
+	}
+	if !res.MentionsRobot() {
+		t.Error("Expected message to mention the robot")
+	}
+	if len(res.Mentions()) != 2 {
+		t.Errorf("Expected 2 mentions, got %d", len(res.Mentions()))
+	}
+
+	res.Message.Text = "hi {@:111,4}@bob"
+	if res.MentionsRobot() {
+		t.Error("Expected message not to mention the robot")
+	}
+}
+
+func TestReplyMention(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("create_message", map[string]interface{}{
+		"id": "msg123",
+	})
+
+	client := direct.NewClient(direct.Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	robot := New()
+	robot.client = client
+	robot.directory.StoreUser(direct.UserInfo{ID: "789", DisplayName: "山田"})
+
+	res := Response{
+		Message: direct.ReceivedMessage{TalkID: "talk456", DomainID: "1", UserID: "789"},
+		Robot:   robot,
+	}
+	if err := res.Reply("Hello!"); err != nil {
+		t.Fatalf("Reply failed: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if len(msg) >= 4 && msg[2] == "create_message" {
+			params := msg[3].([]interface{})
+			if text, _ := params[2].(string); text == "{@:789,3}@山田 Hello!" {
+				return
+			}
+			t.Errorf("Unexpected reply params: %v", params)
+			return
+		}
+	}
+	t.Error("Expected reply message to be sent")
+}
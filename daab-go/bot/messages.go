@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Messages are the texts the robot sends to users on its own, such as the
+// help and usage errors of commands and the reasons of the OneOf and
+// MatchPattern validators. Fields with a %s are format strings taking one
+// argument, named by the comment of the field; an override without a %
+// verb is sent as is. Empty fields use the text of DefaultMessages.
+type Messages struct {
+	HelpDescription   string // description of the help command
+	HelpHeader        string // first line of the help text
+	NoCommands        string // help text when no command matches
+	PermissionDenied  string // reply to a command the user may not run
+	Usage             string // line following a usage error: the usage
+	UnknownFlag       string // the flag name
+	FlagValueRequired string // the flag name
+	ArgRequired       string // the argument name
+	TooManyArgs       string // the extra arguments
+	InvalidInt        string // the value
+	InvalidBool       string // the value
+	InvalidDuration   string // the value
+	OneOf             string // the choices joined with " / "
+	NoMatch           string // reply to an answer rejected by MatchPattern
+}
+
+// DefaultMessages are the texts used unless overridden with WithMessages.
+var DefaultMessages = Messages{
+	HelpDescription:   "コマンドの一覧を表示します",
+	HelpHeader:        "コマンド一覧:",
+	NoCommands:        "該当するコマンドはありません。",
+	PermissionDenied:  "このコマンドを実行する権限がありません。",
+	Usage:             "使い方: %s",
+	UnknownFlag:       "不明なオプションです: --%s",
+	FlagValueRequired: "--%s に値を指定してください",
+	ArgRequired:       "%s を指定してください",
+	TooManyArgs:       "引数が多すぎます: %s",
+	InvalidInt:        "整数を指定してください: %s",
+	InvalidBool:       "true または false を指定してください: %s",
+	InvalidDuration:   "時間を指定してください (例: 30s, 5m): %s",
+	OneOf:             "%s のいずれかで答えてください",
+	NoMatch:           "入力形式が正しくありません",
+}
+
+// WithMessages overrides the texts the robot sends, e.g. to translate
+// them. Fields left empty keep their default.
+func WithMessages(m Messages) Option {
+	return func(r *Robot) {
+		r.messages = m
+	}
+}
+
+// Messages returns the texts the robot sends, with the defaults filled in.
+func (r *Robot) Messages() Messages {
+	return r.messages.withDefaults()
+}
+
+func (m Messages) withDefaults() Messages {
+	d := DefaultMessages
+	fill := func(field *string, def string) {
+		if *field == "" {
+			*field = def
+		}
+	}
+	fill(&m.HelpDescription, d.HelpDescription)
+	fill(&m.HelpHeader, d.HelpHeader)
+	fill(&m.NoCommands, d.NoCommands)
+	fill(&m.PermissionDenied, d.PermissionDenied)
+	fill(&m.Usage, d.Usage)
+	fill(&m.UnknownFlag, d.UnknownFlag)
+	fill(&m.FlagValueRequired, d.FlagValueRequired)
+	fill(&m.ArgRequired, d.ArgRequired)
+	fill(&m.TooManyArgs, d.TooManyArgs)
+	fill(&m.InvalidInt, d.InvalidInt)
+	fill(&m.InvalidBool, d.InvalidBool)
+	fill(&m.InvalidDuration, d.InvalidDuration)
+	fill(&m.OneOf, d.OneOf)
+	fill(&m.NoMatch, d.NoMatch)
+	return m
+}
+
+// formatMessage formats a Messages field with its argument. Texts without
+// a verb are returned unchanged instead of getting a %!(EXTRA ...) suffix.
+func formatMessage(format, arg string) string {
+	if !strings.Contains(format, "%") {
+		return format
+	}
+	return fmt.Sprintf(format, arg)
+}
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/direct-go/metrics"
+)
+
+// WithMetrics records the metrics of the robot and its direct-go client in
+// reg: RPC calls, latency and errors, reconnects, notifications and dropped
+// messages, and listener matches, handler durations and handler panics.
+// Robots sharing a registry, such as the robots of a Fleet, are told apart
+// by the "bot" label.
+func WithMetrics(reg *metrics.Registry) Option {
+	return func(r *Robot) {
+		r.metrics = newRobotMetrics(reg, r)
+	}
+}
+
+// WithMetricsAddr serves the metrics at /metrics on addr, such as ":9090",
+// in the Prometheus text format while the robot runs. A registry is created
+// unless WithMetrics is also used. Robots of a Fleet should rather share a
+// registry served once, as in http.Handle("/metrics", reg).
+func WithMetricsAddr(addr string) Option {
+	return func(r *Robot) {
+		r.metricsAddr = addr
+	}
+}
+
+// Metrics returns the metrics registry, or nil if metrics are disabled.
+func (r *Robot) Metrics() *metrics.Registry {
+	if r.metrics == nil {
+		return nil
+	}
+	return r.metrics.registry
+}
+
+// serveMetrics serves the metrics on the configured address, if any, and
+// returns a function stopping the server.
+func (r *Robot) serveMetrics() (func(), error) {
+	if r.metricsAddr == "" {
+		return func() {}, nil
+	}
+	ln, err := net.Listen("tcp", r.metricsAddr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", r.Metrics())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("[DEBUG] metrics listening on %s", ln.Addr())
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: metrics server: %v", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}, nil
+}
+
+// robotMetrics records the metrics of a robot. A nil *robotMetrics records
+// nothing.
+type robotMetrics struct {
+	registry *metrics.Registry
+	robot    *Robot
+	matches  *metrics.Counter
+	duration *metrics.Histogram
+	panics   *metrics.Counter
+}
+
+func newRobotMetrics(reg *metrics.Registry, r *Robot) *robotMetrics {
+	return &robotMetrics{
+		registry: reg,
+		robot:    r,
+		matches:  reg.Counter("daab_listener_matches_total", "Messages matched by each listener and accepted by its filters.", "bot", "listener"),
+		duration: reg.Histogram("daab_handler_duration_seconds", "Time spent in the handler of each listener, including middleware.", nil, "bot", "listener"),
+		panics:   reg.Counter("daab_handler_panics_total", "Panics in the handler of each listener.", "bot", "listener"),
+	}
+}
+
+func (m *robotMetrics) matched(l *Listener) {
+	if m == nil {
+		return
+	}
+	m.matches.Inc(m.robot.Name, l.Name)
+}
+
+func (m *robotMetrics) handled(l *Listener, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.duration.Observe(time.Since(start).Seconds(), m.robot.Name, l.Name)
+}
+
+func (m *robotMetrics) panicked(l *Listener) {
+	if m == nil {
+		return
+	}
+	name := ""
+	if l != nil {
+		name = l.Name
+	}
+	m.panics.Inc(m.robot.Name, name)
+}
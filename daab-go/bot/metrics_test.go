@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	if New().Metrics() != nil {
+		t.Error("Expected metrics to be disabled by default")
+	}
+
+	reg := metrics.NewRegistry()
+	robot := New(WithName("alice"), WithMetrics(reg))
+	robot.Use(Recover())
+	robot.Hear("hello", func(ctx context.Context, res Response) {})
+	robot.Hear("boom", func(ctx context.Context, res Response) { panic("boom") }, Named("boom"))
+	robot.Hear("filtered", func(ctx context.Context, res Response) {}, InTalks("none"))
+
+	for _, text := range []string{"hello", "hello", "boom", "filtered"} {
+		robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: text})
+	}
+
+	matches := reg.Counter("daab_listener_matches_total", "", "bot", "listener")
+	duration := reg.Histogram("daab_handler_duration_seconds", "", nil, "bot", "listener")
+	panics := reg.Counter("daab_handler_panics_total", "", "bot", "listener")
+	deadline := time.Now().Add(time.Second)
+	for duration.Count("alice", "hear:hello") != 2 || panics.Value("alice", "boom") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected two handled messages and a panic, got %d %v",
+				duration.Count("alice", "hear:hello"), panics.Value("alice", "boom"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if matches.Value("alice", "hear:hello") != 2 || matches.Value("alice", "hear:filtered") != 0 {
+		t.Error("Expected only messages accepted by the filters to be counted as matches")
+	}
+	if duration.Count("alice", "hear:filtered") != 0 {
+		t.Error("Expected filtered messages not to be timed")
+	}
+
+	w := httptest.NewRecorder()
+	robot.Metrics().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), `daab_listener_matches_total{bot="alice",listener="hear:hello"} 2`) {
+		t.Errorf("Unexpected exposition:\n%s", w.Body.String())
+	}
+
+	if New(WithMetricsAddr(":0")).Metrics() == nil {
+		t.Error("Expected WithMetricsAddr to create a registry")
+	}
+}
@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a Handler to run code before or after it, or to stop
+// the message from reaching it by not calling next.
+type Middleware func(next Handler) Handler
+
+// Use adds listener middleware. Listener middleware runs around the handler
+// of every matched listener, in the order it was added.
+func (r *Robot) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// UseReceive adds receive middleware. Receive middleware runs once for each
+// incoming message before listeners are matched; Response.Match and
+// Response.Listener are not set yet. If it does not call next, no listener
+// sees the message.
+func (r *Robot) UseReceive(mw ...Middleware) {
+	r.receive = append(r.receive, mw...)
+}
+
+// chain wraps h with mws so that mws[0] is the outermost middleware.
+func chain(h Handler, mws []Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Recover returns middleware that recovers from panics in the wrapped
+// handler and logs them with a stack trace.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, res Response) {
+			defer func() {
+				if v := recover(); v != nil {
+					log.Printf("[ERROR] handler panic: %v (talk=%s user=%s)\n%s",
+						v, res.RoomID(), res.UserID(), debug.Stack())
+					if res.Robot != nil {
+						res.Robot.metrics.panicked(res.Listener)
+					}
+				}
+			}()
+			next(ctx, res)
+		}
+	}
+}
+
+// LogMessages returns middleware that logs each message and how long the
+// wrapped handler took.
+func LogMessages() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, res Response) {
+			start := time.Now()
+			next(ctx, res)
+			pattern := ""
+			if res.Listener != nil && res.Listener.Pattern != nil {
+				pattern = res.Listener.Pattern.String()
+			}
+			log.Printf("[INFO] talk=%s user=%s pattern=%q took=%s",
+				res.RoomID(), res.UserID(), pattern, time.Since(start))
+		}
+	}
+}
+
+// IgnoreUsers returns middleware that drops messages sent by the given users.
+func IgnoreUsers(userIDs ...string) Middleware {
+	ignored := toSet(userIDs)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, res Response) {
+			if ignored[res.UserID()] {
+				return
+			}
+			next(ctx, res)
+		}
+	}
+}
+
+// AllowDomains returns middleware that only passes messages from the given domains.
+func AllowDomains(domainIDs ...string) Middleware {
+	allowed := toSet(domainIDs)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, res Response) {
+			if !allowed[res.Message.DomainID] {
+				return
+			}
+			next(ctx, res)
+		}
+	}
+}
+
+// RateLimitPerUser returns middleware that lets each user through at most
+// limit times per interval. Messages over the limit are dropped; onLimit,
+// if not nil, is called for each dropped message.
+//
+// Each call of the middleware counts. Added with UseReceive, it counts every
+// incoming message once. Added with Use, it counts every matched listener,
+// so a message matching three listeners uses three of the user's slots and
+// may call onLimit three times.
+func RateLimitPerUser(limit int, interval time.Duration, onLimit Handler) Middleware {
+	limiter := &userLimiter{
+		limit:    limit,
+		interval: interval,
+		windows:  make(map[string]*userWindow),
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, res Response) {
+			if !limiter.allow(res.UserID(), time.Now()) {
+				if onLimit != nil {
+					onLimit(ctx, res)
+				}
+				return
+			}
+			next(ctx, res)
+		}
+	}
+}
+
+type userWindow struct {
+	start time.Time
+	count int
+}
+
+// userLimiter counts messages per user in fixed windows. Windows that
+// ended are swept at most once per interval, so users who stopped writing
+// do not stay in memory.
+type userLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	interval time.Duration
+	windows  map[string]*userWindow
+	swept    time.Time
+}
+
+func (l *userLimiter) allow(userID string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) >= l.interval {
+		l.sweep(now)
+	}
+
+	w, ok := l.windows[userID]
+	if !ok || now.Sub(w.start) >= l.interval {
+		l.windows[userID] = &userWindow{start: now, count: 1}
+		return l.limit > 0
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// sweep deletes the windows that ended before now.
+func (l *userLimiter) sweep(now time.Time) {
+	for userID, w := range l.windows {
+		if now.Sub(w.start) >= l.interval {
+			delete(l.windows, userID)
+		}
+	}
+	l.swept = now
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// waitCalls waits until ch has received n values or the timeout expires.
+func waitCalls(t *testing.T, ch <-chan string, n int) []string {
+	t.Helper()
+	var got []string
+	timeout := time.After(time.Second)
+	for len(got) < n {
+		select {
+		case v := <-ch:
+			got = append(got, v)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %d calls, got %v", n, got)
+		}
+	}
+	return got
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	robot := New()
+	calls := make(chan string, 10)
+
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, res Response) {
+				calls <- name
+				next(ctx, res)
+			}
+		}
+	}
+
+	robot.UseReceive(trace("receive"))
+	robot.Use(trace("first"), trace("second"))
+	robot.Hear("hello", func(ctx context.Context, res Response) {
+		if res.Listener == nil {
+			t.Error("Expected Listener to be set for listener handlers")
+		}
+		calls <- "handler"
+	})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: "hello"})
+
+	got := waitCalls(t, calls, 4)
+	want := []string{"receive", "first", "second", "handler"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("call %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReceiveMiddlewareStopsDispatch(t *testing.T) {
+	robot := New()
+	calls := make(chan string, 10)
+
+	robot.UseReceive(func(next Handler) Handler {
+		return func(ctx context.Context, res Response) {
+			if res.Listener != nil || res.Match != nil {
+				t.Error("Expected no listener or match in receive middleware")
+			}
+			if res.Text() == "blocked" {
+				return
+			}
+			next(ctx, res)
+		}
+	})
+	robot.Hear(".*", func(ctx context.Context, res Response) {
+		calls <- res.Text()
+	})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: "blocked"})
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: "allowed"})
+
+	got := waitCalls(t, calls, 1)
+	if got[0] != "allowed" {
+		t.Errorf("Expected only 'allowed' to pass, got %v", got)
+	}
+	select {
+	case v := <-calls:
+		t.Errorf("Unexpected call: %s", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestRecover(t *testing.T) {
+	done := make(chan struct{})
+	handler := chain(func(ctx context.Context, res Response) {
+		defer close(done)
+		panic("boom")
+	}, []Middleware{Recover()})
+
+	handler(context.Background(), Response{})
+	<-done
+}
+
+func TestIgnoreUsers(t *testing.T) {
+	var called []string
+	handler := IgnoreUsers("bot")(func(ctx context.Context, res Response) {
+		called = append(called, res.UserID())
+	})
+
+	handler(context.Background(), Response{Message: direct.ReceivedMessage{UserID: "bot"}})
+	handler(context.Background(), Response{Message: direct.ReceivedMessage{UserID: "alice"}})
+
+	if len(called) != 1 || called[0] != "alice" {
+		t.Errorf("Expected only alice, got %v", called)
+	}
+}
+
+func TestAllowDomains(t *testing.T) {
+	var called []string
+	handler := AllowDomains("d1")(func(ctx context.Context, res Response) {
+		called = append(called, res.Message.DomainID)
+	})
+
+	handler(context.Background(), Response{Message: direct.ReceivedMessage{DomainID: "d1"}})
+	handler(context.Background(), Response{Message: direct.ReceivedMessage{DomainID: "d2"}})
+
+	if len(called) != 1 || called[0] != "d1" {
+		t.Errorf("Expected only d1, got %v", called)
+	}
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	var mu sync.Mutex
+	passed := map[string]int{}
+	limited := map[string]int{}
+
+	mw := RateLimitPerUser(2, time.Hour, func(ctx context.Context, res Response) {
+		mu.Lock()
+		limited[res.UserID()]++
+		mu.Unlock()
+	})
+	handler := mw(func(ctx context.Context, res Response) {
+		mu.Lock()
+		passed[res.UserID()]++
+		mu.Unlock()
+	})
+
+	for i := 0; i < 3; i++ {
+		handler(context.Background(), Response{Message: direct.ReceivedMessage{UserID: "alice"}})
+	}
+	handler(context.Background(), Response{Message: direct.ReceivedMessage{UserID: "bob"}})
+
+	if passed["alice"] != 2 || limited["alice"] != 1 {
+		t.Errorf("Expected alice 2 passed / 1 limited, got %d / %d", passed["alice"], limited["alice"])
+	}
+	if passed["bob"] != 1 {
+		t.Errorf("Expected bob to pass, got %d", passed["bob"])
+	}
+}
+
+func TestUserLimiterWindow(t *testing.T) {
+	l := &userLimiter{limit: 1, interval: time.Minute, windows: map[string]*userWindow{}}
+	now := time.Now()
+
+	if !l.allow("alice", now) {
+		t.Error("Expected first message to pass")
+	}
+	if l.allow("alice", now.Add(time.Second)) {
+		t.Error("Expected second message in window to be limited")
+	}
+	if !l.allow("alice", now.Add(time.Minute)) {
+		t.Error("Expected message in next window to pass")
+	}
+}
+
+func TestUserLimiterSweep(t *testing.T) {
+	l := &userLimiter{limit: 1, interval: time.Minute, windows: map[string]*userWindow{}}
+	now := time.Now()
+
+	l.allow("alice", now)
+	l.allow("bob", now.Add(30*time.Second))
+	if len(l.windows) != 2 {
+		t.Fatalf("Expected two windows, got %d", len(l.windows))
+	}
+
+	// Alice's window has ended and is swept; Bob's is still open.
+	l.allow("carol", now.Add(70*time.Second))
+	if _, ok := l.windows["alice"]; ok || len(l.windows) != 2 {
+		t.Errorf("Expected alice's ended window to be swept, got %v", l.windows)
+	}
+	if l.allow("bob", now.Add(80*time.Second)) {
+		t.Error("Expected bob to stay limited in the open window")
+	}
+}
@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+	"log"
+)
+
+// NotifyHandler handles a server notification such as
+// direct.EventNotifyAddTalkers. data is the decoded notification parameter.
+type NotifyHandler func(ctx context.Context, event string, data interface{})
+
+// OnNotify registers a handler for the named server notification. The
+// handlers of a notification run in order in their own goroutine and are
+// drained on shutdown like message handlers. OnNotify must be called before Run.
+func (r *Robot) OnNotify(event string, handler NotifyHandler) {
+	if r.notifyHandlers == nil {
+		r.notifyHandlers = make(map[string][]NotifyHandler)
+	}
+	r.notifyHandlers[event] = append(r.notifyHandlers[event], handler)
+}
+
+// subscribeNotify registers the notification handlers with the client.
+func (r *Robot) subscribeNotify(ctx context.Context) {
+	for event, handlers := range r.notifyHandlers {
+		r.client.On(event, func(data interface{}) {
+			if !r.handlers.begin() {
+				log.Printf("[DEBUG] Dropping %s: shutting down", event)
+				return
+			}
+			defer r.handlers.done()
+			for _, handler := range handlers {
+				handler(ctx, event, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestOnNotify(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	robot := New(WithToken("token"), WithEndpoint(mockServer.URL()))
+	got := make(chan interface{}, 1)
+	robot.OnNotify(direct.EventNotifyAddTalkers, func(ctx context.Context, event string, data interface{}) {
+		if event == direct.EventNotifyAddTalkers {
+			got <- data
+		}
+	})
+	started := &testPlugin{name: "started"}
+	robot.UsePlugins(started)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, func() bool { return strings.Contains(started.Events(), "start") })
+
+	if err := mockServer.SendNotification(direct.EventNotifyAddTalkers, map[string]interface{}{"talk_id": uint64(100)}); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	data := <-got
+	if m, ok := data.(map[string]interface{}); !ok || m["talk_id"] == nil {
+		t.Errorf("Unexpected notification data %v", data)
+	}
+}
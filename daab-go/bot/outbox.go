@@ -0,0 +1,394 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Default limits for outgoing messages.
+const (
+	// DefaultGlobalRate is the default number of messages per second sent across all talks.
+	DefaultGlobalRate = 20
+	// DefaultGlobalBurst is the default number of messages sent at once across all talks.
+	DefaultGlobalBurst = 40
+	// DefaultTalkRate is the default number of messages per second sent to one talk.
+	DefaultTalkRate = 5
+	// DefaultTalkBurst is the default number of messages sent at once to one talk.
+	DefaultTalkBurst = 10
+	// DefaultSendRetries is how many times a failed send is retried by default.
+	DefaultSendRetries = 3
+	// DefaultSendBackoff is the delay before the first retry; it doubles on each attempt.
+	DefaultSendBackoff = 500 * time.Millisecond
+)
+
+// WithGlobalRateLimit limits outgoing messages across all talks to rate
+// messages per second, allowing bursts of up to burst messages.
+// A rate of zero or less disables the limit.
+func WithGlobalRateLimit(rate float64, burst int) Option {
+	return func(r *Robot) {
+		r.outbox.global = newTokenBucket(rate, burst)
+	}
+}
+
+// WithTalkRateLimit limits outgoing messages to each talk to rate messages
+// per second, allowing bursts of up to burst messages.
+// A rate of zero or less disables the limit.
+func WithTalkRateLimit(rate float64, burst int) Option {
+	return func(r *Robot) {
+		r.outbox.talkRate = rate
+		r.outbox.talkBurst = burst
+	}
+}
+
+// WithSendRetries sets how many times a send failing with an error that is
+// safe to resend (see direct.IsSafeToResend) is retried, and the delay before
+// the first retry. The delay doubles on each further attempt. Sends that time
+// out are not retried, as the server may have created the message.
+func WithSendRetries(max int, backoff time.Duration) Option {
+	return func(r *Robot) {
+		r.outbox.retries = max
+		r.outbox.backoff = backoff
+	}
+}
+
+// SendResult is the pending result of a queued message.
+type SendResult struct {
+	done      chan struct{}
+	messageID string
+	err       error
+}
+
+func newSendResult() *SendResult {
+	return &SendResult{done: make(chan struct{})}
+}
+
+func (s *SendResult) complete(messageID string, err error) {
+	s.messageID = messageID
+	s.err = err
+	close(s.done)
+}
+
+// Done returns a channel that is closed once the message was sent or failed.
+func (s *SendResult) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait blocks until the message was sent and returns its message ID.
+// It returns ctx.Err() if ctx is done first; the message stays queued.
+func (s *SendResult) Wait(ctx context.Context) (string, error) {
+	select {
+	case <-s.done:
+		return s.messageID, s.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// MessageID returns the ID of the sent message, or "" if it has not been sent.
+func (s *SendResult) MessageID() string {
+	select {
+	case <-s.done:
+		return s.messageID
+	default:
+		return ""
+	}
+}
+
+// Err returns the error that made the send fail, or nil if it succeeded or is still pending.
+func (s *SendResult) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Enqueue queues a message of the given wire type for roomID and returns
+// its pending result. Messages to the same talk are sent in order.
+func (r *Robot) Enqueue(roomID string, msgType int, content interface{}) *SendResult {
+	return r.EnqueueContext(context.Background(), roomID, msgType, content)
+}
+
+// EnqueueContext is like Enqueue, but traces the send as a child of the span
+// in ctx. Cancelling ctx does not remove the message from the queue.
+func (r *Robot) EnqueueContext(ctx context.Context, roomID string, msgType int, content interface{}) *SendResult {
+	result := newSendResult()
+	if r.client == nil {
+		result.complete("", ErrNotConnected)
+		return result
+	}
+	r.outbox.enqueue(&outgoing{
+		ctx:     context.WithoutCancel(ctx),
+		client:  r.client,
+		roomID:  roomID,
+		msgType: msgType,
+		content: content,
+		result:  result,
+	})
+	return result
+}
+
+// SendTextAsync queues a text message for roomID without waiting for it to be sent.
+func (r *Robot) SendTextAsync(roomID, text string) *SendResult {
+	return r.Enqueue(roomID, direct.MsgTypeText, text)
+}
+
+// SendAsync queues a text message for the same room without waiting for it to be sent.
+func (r Response) SendAsync(text string) *SendResult {
+	return r.Robot.EnqueueContext(r.Context(), r.Message.TalkID, direct.MsgTypeText, text)
+}
+
+// outgoing is a message waiting in the outbox.
+type outgoing struct {
+	ctx     context.Context
+	client  *direct.Client
+	roomID  string
+	msgType int
+	content interface{}
+	result  *SendResult
+}
+
+// outbox sends queued messages, one worker per talk, within the rate limits.
+type outbox struct {
+	global    *tokenBucket
+	talkRate  float64
+	talkBurst int
+	retries   int
+	backoff   time.Duration
+
+	mu      sync.Mutex
+	talks   map[string]*talkQueue
+	pruned  time.Time
+	stopped chan struct{}
+	wg      sync.WaitGroup
+}
+
+// pruneInterval is how often idle talk queues are removed from the outbox.
+const pruneInterval = time.Minute
+
+type talkQueue struct {
+	items   []*outgoing
+	bucket  *tokenBucket
+	running bool
+}
+
+func newOutbox() *outbox {
+	return &outbox{
+		global:    newTokenBucket(DefaultGlobalRate, DefaultGlobalBurst),
+		talkRate:  DefaultTalkRate,
+		talkBurst: DefaultTalkBurst,
+		retries:   DefaultSendRetries,
+		backoff:   DefaultSendBackoff,
+		talks:     make(map[string]*talkQueue),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// start reopens the outbox for a new connection.
+func (o *outbox) start() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	select {
+	case <-o.stopped:
+		o.stopped = make(chan struct{})
+	default:
+	}
+}
+
+func (o *outbox) enqueue(item *outgoing) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if time.Since(o.pruned) >= pruneInterval {
+		o.prune()
+	}
+	q := o.talks[item.roomID]
+	if q == nil {
+		q = &talkQueue{bucket: newTokenBucket(o.talkRate, o.talkBurst)}
+		o.talks[item.roomID] = q
+	}
+	q.items = append(q.items, item)
+	if !q.running {
+		q.running = true
+		o.wg.Add(1)
+		go o.run(q, o.stopped)
+	}
+}
+
+// prune removes the queues of talks with nothing to send whose rate limit
+// has recovered, so that a new queue behaves the same. o.mu must be held.
+func (o *outbox) prune() {
+	for roomID, q := range o.talks {
+		if !q.running && len(q.items) == 0 && q.bucket.full() {
+			delete(o.talks, roomID)
+		}
+	}
+	o.pruned = time.Now()
+}
+
+// run sends the talk's messages in order until its queue is empty.
+func (o *outbox) run(q *talkQueue, stopped <-chan struct{}) {
+	defer o.wg.Done()
+	for {
+		o.mu.Lock()
+		if len(q.items) == 0 {
+			q.running = false
+			o.mu.Unlock()
+			return
+		}
+		item := q.items[0]
+		q.items = q.items[1:]
+		o.mu.Unlock()
+
+		item.result.complete(o.send(item, q.bucket, stopped))
+	}
+}
+
+// send delivers a message, retrying retryable failures with exponential backoff.
+// Once the outbox is stopped, waiting messages fail with ErrNotConnected.
+func (o *outbox) send(item *outgoing, bucket *tokenBucket, stopped <-chan struct{}) (string, error) {
+	backoff := o.backoff
+	for attempt := 0; ; attempt++ {
+		if !bucket.wait(stopped) || !o.global.wait(stopped) {
+			return "", ErrNotConnected
+		}
+		result, err := item.client.CallContext(item.ctx, direct.MethodCreateMessage,
+			[]interface{}{normalizeRoomID(item.roomID), item.msgType, item.content})
+		if err == nil {
+			return extractMessageID(result), nil
+		}
+		if attempt >= o.retries || !direct.IsSafeToResend(err) {
+			return "", err
+		}
+		log.Printf("Warning: send to %s failed (attempt %d): %v; retrying in %v",
+			item.roomID, attempt+1, err, backoff)
+		if !sleep(backoff, stopped) {
+			return "", err
+		}
+		backoff *= 2
+	}
+}
+
+// flush waits up to timeout for queued messages to be sent, then stops the
+// outbox so that messages still waiting fail instead of being retried.
+func (o *outbox) flush(timeout time.Duration) {
+	idle := make(chan struct{})
+	go func() {
+		o.wg.Wait()
+		close(idle)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-idle:
+	case <-timer.C:
+		log.Printf("Warning: %d message(s) still queued after %v, dropping", o.pending(), timeout)
+	}
+
+	o.mu.Lock()
+	select {
+	case <-o.stopped:
+	default:
+		close(o.stopped)
+	}
+	o.mu.Unlock()
+	<-idle
+}
+
+func (o *outbox) pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for _, q := range o.talks {
+		n += len(q.items)
+		if q.running {
+			n++
+		}
+	}
+	return n
+}
+
+// tokenBucket is a token bucket rate limiter. A nil bucket never waits.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// full reports whether the bucket has refilled to its burst.
+func (b *tokenBucket) full() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens+time.Since(b.last).Seconds()*b.rate >= b.burst
+}
+
+// wait blocks until a token is available. It returns false if stopped is
+// closed first.
+func (b *tokenBucket) wait(stopped <-chan struct{}) bool {
+	if b == nil {
+		return true
+	}
+	return sleep(b.reserve(), stopped)
+}
+
+// sleep waits for d and returns false if stopped is closed first.
+func sleep(d time.Duration, stopped <-chan struct{}) bool {
+	if d <= 0 {
+		select {
+		case <-stopped:
+			return false
+		default:
+			return true
+		}
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stopped:
+		return false
+	}
+}
@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestOutboxOrdering(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithGlobalRateLimit(0, 0), WithTalkRateLimit(0, 0))
+	var n atomic.Int32
+	mockServer.On("create_message", func(params []interface{}) (interface{}, error) {
+		return map[string]interface{}{"message_id": fmt.Sprint(n.Add(1))}, nil
+	})
+
+	var results []*SendResult
+	for i := 0; i < 20; i++ {
+		results = append(results, robot.SendTextAsync("100", fmt.Sprint(i)))
+	}
+	for i, result := range results {
+		id, err := result.Wait(context.Background())
+		if err != nil {
+			t.Fatalf("Send %d failed: %v", i, err)
+		}
+		if id != fmt.Sprint(i+1) || result.MessageID() != id {
+			t.Errorf("Expected message %d to get ID %d, got %s", i, i+1, id)
+		}
+	}
+
+	texts := sentTexts(mockServer)
+	for i, text := range texts {
+		if text != fmt.Sprint(i) {
+			t.Fatalf("Expected messages in order, got %v", texts)
+		}
+	}
+}
+
+func TestOutboxTalkRateLimit(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithTalkRateLimit(50, 1))
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		robot.SendTextAsync("100", "a")
+	}
+	// Other talks have their own bucket.
+	if err := robot.SendText("200", "b"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected another talk not to be limited, took %v", elapsed)
+	}
+
+	if err := robot.SendText("100", "c"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("Expected 4 messages at 50/s to take at least 60ms, took %v", elapsed)
+	}
+}
+
+func TestOutboxGlobalRateLimit(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithGlobalRateLimit(50, 1))
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	start := time.Now()
+	var results []*SendResult
+	for i := 0; i < 3; i++ {
+		results = append(results, robot.SendTextAsync(fmt.Sprint(100+i), "a"))
+	}
+	for _, result := range results {
+		if _, err := result.Wait(context.Background()); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected 3 messages at 50/s to take at least 40ms, took %v", elapsed)
+	}
+}
+
+func TestOutboxRetries(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithSendRetries(2, time.Millisecond))
+	var calls atomic.Int32
+	mockServer.On("create_message", func(params []interface{}) (interface{}, error) {
+		if calls.Add(1) < 3 {
+			return nil, errors.New("rate limit exceeded")
+		}
+		return map[string]interface{}{"message_id": "m1"}, nil
+	})
+
+	id, err := robot.SendTextAsync("100", "hi").Wait(context.Background())
+	if err != nil || id != "m1" {
+		t.Fatalf("Expected send to succeed after retries, got %q, %v", id, err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+
+	// Non-retryable errors fail at once, and retries are bounded.
+	calls.Store(0)
+	mockServer.OnError("create_message", "invalid talk")
+	if err := robot.SendText("100", "hi"); err == nil {
+		t.Error("Expected send to fail")
+	}
+	mockServer.OnError("create_message", "rate limit exceeded")
+	if err := robot.SendText("100", "hi"); !direct.IsRetryable(err) {
+		t.Errorf("Expected the last retryable error, got %v", err)
+	}
+	if n := mockServer.GetCallCount("create_message"); n != 3+1+3 {
+		t.Errorf("Expected 7 create_message calls in total, got %d", n)
+	}
+}
+
+func TestOutboxNoRetryAfterTimeout(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	// The server creates the message but answers after the client gave up.
+	mockServer.On("create_message", func(params []interface{}) (interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return map[string]interface{}{"message_id": "m1"}, nil
+	})
+	client := direct.NewClient(direct.Options{Endpoint: mockServer.URL(), CallTimeout: 20 * time.Millisecond})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	robot := New(WithSendRetries(3, time.Millisecond))
+	robot.client = client
+	if err := robot.SendText("100", "hi"); !errors.Is(err, direct.ErrRPCTimeout) {
+		t.Fatalf("Expected the send to time out, got %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if n := mockServer.GetCallCount("create_message"); n != 1 {
+		t.Errorf("Expected a timed out send not to be retried, got %d calls", n)
+	}
+}
+
+func TestOutboxPrune(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithTalkRateLimit(1000, 1))
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	for i := 0; i < 3; i++ {
+		if err := robot.SendText(fmt.Sprint(100+i), "a"); err != nil {
+			t.Fatalf("SendText failed: %v", err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	o := robot.outbox
+	o.mu.Lock()
+	o.pruned = time.Time{}
+	o.mu.Unlock()
+	if err := robot.SendText("200", "b"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.talks) != 1 || o.talks["200"] == nil {
+		t.Errorf("Expected idle talk queues to be removed, got %d queues", len(o.talks))
+	}
+}
+
+func TestOutboxFlush(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithTalkRateLimit(20, 1))
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+
+	first := robot.SendTextAsync("100", "a")
+	second := robot.SendTextAsync("100", "b")
+	robot.outbox.flush(time.Second)
+	if first.Err() != nil || second.Err() != nil || second.MessageID() != "m" {
+		t.Errorf("Expected queued messages to be sent before flush returned: %v, %v", first.Err(), second.Err())
+	}
+
+	// Messages waiting when the timeout expires fail.
+	robot.outbox.start()
+	robot.SendTextAsync("100", "c")
+	robot.SendTextAsync("100", "d")
+	last := robot.SendTextAsync("100", "e")
+	robot.outbox.flush(10 * time.Millisecond)
+	if !errors.Is(last.Err(), ErrNotConnected) {
+		t.Errorf("Expected dropped message to fail with ErrNotConnected, got %v", last.Err())
+	}
+}
+
+func TestSendResultWait(t *testing.T) {
+	result := newSendResult()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := result.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Wait to return the context error, got %v", err)
+	}
+	if result.MessageID() != "" || result.Err() != nil {
+		t.Error("Expected pending result to be empty")
+	}
+
+	result.complete("m1", nil)
+	<-result.Done()
+	if id, err := result.Wait(context.Background()); id != "m1" || err != nil {
+		t.Errorf("Wait() = %q, %v", id, err)
+	}
+
+	if _, err := New().SendTextAsync("100", "hi").Wait(context.Background()); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected before Run, got %v", err)
+	}
+}
+
+func TestSendContext(t *testing.T) {
+	robot, mockServer := newTestRobot(t, WithTalkRateLimit(0.001, 1))
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "m"})
+	if err := robot.SendText("100", "a"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+
+	// The talk's bucket is empty, so the sends wait until ctx is done.
+	sends := map[string]func(ctx context.Context) (string, error){
+		"stamp": func(ctx context.Context) (string, error) {
+			return robot.SendStampContext(ctx, "100", "3", "1", "")
+		},
+		"select": func(ctx context.Context) (string, error) {
+			return robot.SendSelectContext(ctx, "100", "Which?", []string{"A", "B"})
+		},
+		"yesno": func(ctx context.Context) (string, error) { return robot.SendYesNoContext(ctx, "100", "OK?") },
+		"task":  func(ctx context.Context) (string, error) { return robot.SendTaskContext(ctx, "100", "Review") },
+		"reply select": func(ctx context.Context) (string, error) {
+			return robot.ReplySelectContext(ctx, "100", "42", 1)
+		},
+		"reply yesno": func(ctx context.Context) (string, error) {
+			return robot.ReplyYesNoContext(ctx, "100", "42", true)
+		},
+		"reply task": func(ctx context.Context) (string, error) {
+			return robot.ReplyTaskContext(ctx, "100", "42", true)
+		},
+		"close select": func(ctx context.Context) (string, error) {
+			return robot.CloseSelectContext(ctx, "100", "42")
+		},
+		"close yesno": func(ctx context.Context) (string, error) {
+			return robot.CloseYesNoContext(ctx, "100", "42")
+		},
+	}
+	for name, send := range sends {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if _, err := send(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected %s to stop waiting when ctx is done, got %v", name, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,322 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plugin is a reusable set of listeners, commands and jobs that can be
+// added to a robot with UsePlugins.
+//
+// A plugin may also implement PluginConfigurer, PluginStarter and
+// PluginStopper to receive its configuration and lifecycle events.
+type Plugin interface {
+	// Name identifies the plugin in configuration and logs.
+	Name() string
+
+	// Register adds the plugin's listeners, commands and jobs to the robot.
+	Register(r *Robot) error
+}
+
+// PluginConfigurer is implemented by plugins that read configuration.
+// Configure is called before Register.
+type PluginConfigurer interface {
+	Configure(cfg PluginConfig) error
+}
+
+// PluginStarter is implemented by plugins that need to run code once the
+// robot is connected. If Start fails, Run stops the plugins started before
+// and returns the error.
+type PluginStarter interface {
+	Start(ctx context.Context) error
+}
+
+// PluginStopper is implemented by plugins that need to clean up when the
+// robot shuts down. Stop is called after in-flight handlers have finished,
+// in reverse order of UsePlugins, while the robot can still send messages.
+// It is also called if Run fails after the plugin has started.
+type PluginStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// PluginDescriber is implemented by plugins that describe themselves,
+// for example in the help plugin's list of plugins.
+type PluginDescriber interface {
+	Description() string
+}
+
+// PluginSettings holds the configuration of plugins by plugin name.
+type PluginSettings map[string]map[string]interface{}
+
+// LoadPluginSettings reads plugin settings from a JSON file of the form
+//
+//	{"plugins": {"remind": {"location": "Asia/Tokyo"}, "ping": {"enabled": false}}}
+//
+// It is kept for projects with a plugins.json; new projects put the settings
+// in the plugins section of daabgo.yaml and read them with LoadConfig.
+func LoadPluginSettings(path string) (PluginSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Plugins PluginSettings `json:"plugins"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin settings %s: %w", path, err)
+	}
+	return file.Plugins, nil
+}
+
+// WithPluginSettings sets the configuration passed to plugins added with UsePlugins.
+func WithPluginSettings(s PluginSettings) Option {
+	return func(r *Robot) {
+		r.pluginSettings = s
+	}
+}
+
+// PluginConfig is the configuration of one plugin.
+//
+// A value is read from the environment variable DAABGO_<PLUGIN>_<KEY>
+// (upper-cased, with other characters than letters and digits replaced by
+// "_") if it is set, and otherwise from the plugin's section of the
+// PluginSettings.
+type PluginConfig struct {
+	name   string
+	values map[string]interface{}
+}
+
+// NewPluginConfig returns the configuration of the named plugin with the
+// given file values.
+func NewPluginConfig(name string, values map[string]interface{}) PluginConfig {
+	return PluginConfig{name: name, values: values}
+}
+
+// Name returns the plugin name.
+func (c PluginConfig) Name() string {
+	return c.name
+}
+
+// EnvName returns the environment variable that overrides key.
+func (c PluginConfig) EnvName(key string) string {
+	return "DAABGO_" + envKey(c.name) + "_" + envKey(key)
+}
+
+// Lookup returns the value of key and whether it is set.
+func (c PluginConfig) Lookup(key string) (string, bool) {
+	if v, ok := os.LookupEnv(c.EnvName(key)); ok {
+		return v, true
+	}
+	v, ok := c.values[key]
+	if !ok || v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v), true
+	}
+	return string(data), true
+}
+
+// String returns the value of key, or def if it is not set.
+func (c PluginConfig) String(key, def string) string {
+	if v, ok := c.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// Int returns the value of key as an int, or def if it is not set or invalid.
+func (c PluginConfig) Int(key string, def int) int {
+	v, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Warning: plugin %s: invalid %s %q: %v", c.name, key, v, err)
+		return def
+	}
+	return n
+}
+
+// Bool returns the value of key as a bool, or def if it is not set or invalid.
+func (c PluginConfig) Bool(key string, def bool) bool {
+	v, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("Warning: plugin %s: invalid %s %q: %v", c.name, key, v, err)
+		return def
+	}
+	return b
+}
+
+// Duration returns the value of key as a duration such as "30s", or def if
+// it is not set or invalid.
+func (c PluginConfig) Duration(key string, def time.Duration) time.Duration {
+	v, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Warning: plugin %s: invalid %s %q: %v", c.name, key, v, err)
+		return def
+	}
+	return d
+}
+
+// Strings returns the value of key as a list, or def if it is not set. The
+// value is a list in the settings file, or a comma separated string such as
+// "a, b" in the file or the environment; a JSON list like ["a","b"] is also
+// accepted. Empty items are dropped.
+func (c PluginConfig) Strings(key string, def []string) []string {
+	if _, env := os.LookupEnv(c.EnvName(key)); !env {
+		if items, ok := c.values[key].([]interface{}); ok {
+			list := make([]string, 0, len(items))
+			for _, item := range items {
+				if item == nil {
+					continue
+				}
+				if s := fmt.Sprint(item); s != "" {
+					list = append(list, s)
+				}
+			}
+			return list
+		}
+	}
+	v, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+	var list []string
+	for _, item := range strings.Split(strings.Trim(v, "[]"), ",") {
+		if item = strings.Trim(strings.TrimSpace(item), `"`); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Enabled reports whether the plugin is enabled (key "enabled", default true).
+func (c PluginConfig) Enabled() bool {
+	return c.Bool("enabled", true)
+}
+
+// Decode stores the plugin's file section in the value pointed to by v,
+// using encoding/json. Environment variables are not applied.
+func (c PluginConfig) Decode(v interface{}) error {
+	data, err := json.Marshal(c.values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func envKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// UsePlugins configures and registers plugins. Plugins disabled in their
+// configuration are skipped. UsePlugins must be called before Run.
+func (r *Robot) UsePlugins(plugins ...Plugin) error {
+	for _, p := range plugins {
+		name := p.Name()
+		for _, existing := range r.plugins {
+			if existing.Name() == name {
+				return fmt.Errorf("daab: plugin %s already registered", name)
+			}
+		}
+
+		cfg := NewPluginConfig(name, r.pluginSettings[name])
+		if !cfg.Enabled() {
+			log.Printf("[DEBUG] Plugin %s is disabled", name)
+			continue
+		}
+		if c, ok := p.(PluginConfigurer); ok {
+			if err := c.Configure(cfg); err != nil {
+				return fmt.Errorf("daab: plugin %s: %w", name, err)
+			}
+		}
+		if err := p.Register(r); err != nil {
+			return fmt.Errorf("daab: plugin %s: %w", name, err)
+		}
+		r.plugins = append(r.plugins, p)
+	}
+	return nil
+}
+
+// Plugins returns the registered plugins in the order they were added.
+func (r *Robot) Plugins() []Plugin {
+	return append([]Plugin(nil), r.plugins...)
+}
+
+// Commands returns the robot's shared command router, creating it on first
+// use. Plugins add their commands here. The help command is registered on
+// this router only, and lists the commands of every router of the robot.
+func (r *Robot) Commands() *Commands {
+	r.commandsOnce.Do(func() {
+		r.commands = newCommands(r)
+		r.commands.Command("help [command...]", r.Messages().HelpDescription, r.commands.help)
+	})
+	return r.commands
+}
+
+// routerList returns the command routers of the robot in creation order.
+func (r *Robot) routerList() []*Commands {
+	r.routersMu.Lock()
+	defer r.routersMu.Unlock()
+	return append([]*Commands(nil), r.routers...)
+}
+
+// startPlugins starts the plugins implementing PluginStarter and returns
+// the plugins that were started, in order, even if one of them failed.
+func (r *Robot) startPlugins(ctx context.Context) ([]Plugin, error) {
+	for i, p := range r.plugins {
+		if s, ok := p.(PluginStarter); ok {
+			if err := s.Start(ctx); err != nil {
+				return r.plugins[:i], fmt.Errorf("daab: plugin %s: start: %w", p.Name(), err)
+			}
+		}
+	}
+	return r.plugins, nil
+}
+
+// stopPlugins stops the plugins implementing PluginStopper in reverse order,
+// giving them up to the grace period.
+func (r *Robot) stopPlugins(plugins []Plugin) {
+	ctx := context.Background()
+	if r.gracePeriod > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.gracePeriod)
+		defer cancel()
+	}
+	for i := len(plugins) - 1; i >= 0; i-- {
+		if s, ok := plugins[i].(PluginStopper); ok {
+			if err := s.Stop(ctx); err != nil {
+				log.Printf("Warning: plugin %s: stop: %v", plugins[i].Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+type testPlugin struct {
+	name      string
+	mu        sync.Mutex
+	events    []string
+	greeting  string
+	startErr  error
+	configErr error
+}
+
+func (p *testPlugin) Name() string { return p.name }
+
+func (p *testPlugin) record(event string) {
+	p.mu.Lock()
+	p.events = append(p.events, event)
+	p.mu.Unlock()
+}
+
+func (p *testPlugin) Events() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.Join(p.events, ",")
+}
+
+func (p *testPlugin) Configure(cfg PluginConfig) error {
+	p.record("configure")
+	p.greeting = cfg.String("greeting", "hello")
+	return p.configErr
+}
+
+func (p *testPlugin) Register(r *Robot) error {
+	p.record("register")
+	return nil
+}
+
+func (p *testPlugin) Start(ctx context.Context) error {
+	p.record("start")
+	return p.startErr
+}
+
+func (p *testPlugin) Stop(ctx context.Context) error {
+	p.record("stop")
+	return nil
+}
+
+func TestUsePlugins(t *testing.T) {
+	robot := New(WithPluginSettings(PluginSettings{
+		"greeter":  {"greeting": "こんにちは"},
+		"disabled": {"enabled": false},
+	}))
+	greeter := &testPlugin{name: "greeter"}
+	disabled := &testPlugin{name: "disabled"}
+	if err := robot.UsePlugins(greeter, disabled); err != nil {
+		t.Fatalf("UsePlugins failed: %v", err)
+	}
+
+	if greeter.Events() != "configure,register" || greeter.greeting != "こんにちは" {
+		t.Errorf("Unexpected plugin state: events %s, greeting %q", greeter.Events(), greeter.greeting)
+	}
+	if disabled.Events() != "" {
+		t.Errorf("Expected disabled plugin not to be registered, got %s", disabled.Events())
+	}
+	if plugins := robot.Plugins(); len(plugins) != 1 || plugins[0] != greeter {
+		t.Errorf("Unexpected plugins %v", plugins)
+	}
+
+	if err := robot.UsePlugins(&testPlugin{name: "greeter"}); err == nil {
+		t.Error("Expected duplicate plugin to fail")
+	}
+	if err := robot.UsePlugins(&testPlugin{name: "broken", configErr: errors.New("bad")}); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected configuration error naming the plugin, got %v", err)
+	}
+}
+
+func TestPluginConfig(t *testing.T) {
+	t.Setenv("DAABGO_MY_PLUGIN_RETRY_COUNT", "5")
+	cfg := NewPluginConfig("my-plugin", map[string]interface{}{
+		"retry_count": 3,
+		"interval":    "30s",
+		"verbose":     true,
+		"limit":       "many",
+		"rooms":       []interface{}{"a", "b"},
+	})
+
+	if name := cfg.EnvName("retry_count"); name != "DAABGO_MY_PLUGIN_RETRY_COUNT" {
+		t.Errorf("EnvName() = %s", name)
+	}
+	if n := cfg.Int("retry_count", 0); n != 5 {
+		t.Errorf("Expected environment to override the file, got %d", n)
+	}
+	if d := cfg.Duration("interval", 0); d != 30*time.Second {
+		t.Errorf("Duration() = %v", d)
+	}
+	if !cfg.Bool("verbose", false) || !cfg.Enabled() {
+		t.Error("Expected verbose and enabled to be true")
+	}
+	if n := cfg.Int("limit", 10); n != 10 {
+		t.Errorf("Expected default for an invalid value, got %d", n)
+	}
+	if s := cfg.String("missing", "def"); s != "def" {
+		t.Errorf("String() = %q", s)
+	}
+
+	if rooms := cfg.Strings("rooms", nil); strings.Join(rooms, ",") != "a,b" {
+		t.Errorf("Strings() = %q", rooms)
+	}
+	t.Setenv("DAABGO_MY_PLUGIN_ROOMS", "c, d,,")
+	if rooms := cfg.Strings("rooms", nil); strings.Join(rooms, ",") != "c,d" {
+		t.Errorf("Expected a comma separated environment variable, got %q", rooms)
+	}
+	if list := cfg.Strings("missing", []string{"x"}); len(list) != 1 || list[0] != "x" {
+		t.Errorf("Strings() = %q", list)
+	}
+
+	var decoded struct {
+		Rooms []string `json:"rooms"`
+	}
+	if err := cfg.Decode(&decoded); err != nil || len(decoded.Rooms) != 2 {
+		t.Errorf("Decode() = %v, %v", decoded, err)
+	}
+}
+
+func TestLoadPluginSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins.json")
+	data := `{"plugins": {"remind": {"location": "Asia/Tokyo"}, "ping": {"enabled": false}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadPluginSettings(path)
+	if err != nil {
+		t.Fatalf("LoadPluginSettings failed: %v", err)
+	}
+	if settings["remind"]["location"] != "Asia/Tokyo" || NewPluginConfig("ping", settings["ping"]).Enabled() {
+		t.Errorf("Unexpected settings %v", settings)
+	}
+}
+
+func TestPluginLifecycle(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	robot := New(WithToken("token"), WithEndpoint(mockServer.URL()))
+	first := &testPlugin{name: "first"}
+	second := &testPlugin{name: "second"}
+	if err := robot.UsePlugins(first, second); err != nil {
+		t.Fatalf("UsePlugins failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := robot.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if first.Events() != "configure,register,start,stop" || second.Events() != "configure,register,start,stop" {
+		t.Errorf("Unexpected lifecycle: first %s, second %s", first.Events(), second.Events())
+	}
+
+	failing := New(WithToken("token"), WithEndpoint(mockServer.URL()))
+	started := &testPlugin{name: "started"}
+	broken := &testPlugin{name: "broken", startErr: errors.New("no database")}
+	later := &testPlugin{name: "later"}
+	failing.UsePlugins(started, broken, later)
+	if err := failing.Run(ctx); err == nil || !strings.Contains(err.Error(), "no database") {
+		t.Errorf("Expected Run to return the start error, got %v", err)
+	}
+	if started.Events() != "configure,register,start,stop" || broken.Events() != "configure,register,start" || later.Events() != "configure,register" {
+		t.Errorf("Expected only the started plugin to be stopped: started %s, broken %s, later %s",
+			started.Events(), broken.Events(), later.Events())
+	}
+
+	// A metrics address that cannot be listened on stops the started plugins.
+	noMetrics := New(WithToken("token"), WithEndpoint(mockServer.URL()), WithMetricsAddr("256.0.0.1:0"))
+	plugin := &testPlugin{name: "plugin"}
+	noMetrics.UsePlugins(plugin)
+	if err := noMetrics.Run(ctx); err == nil {
+		t.Error("Expected Run to fail to serve metrics")
+	}
+	if plugin.Events() != "configure,register,start,stop" {
+		t.Errorf("Expected the plugin to be stopped, got %s", plugin.Events())
+	}
+}
+
+func TestSharedCommands(t *testing.T) {
+	robot := New()
+	if robot.Commands() != robot.Commands() {
+		t.Error("Expected Commands to return the same router")
+	}
+}
@@ -0,0 +1,52 @@
+// Package help provides the help command and a "plugins" command listing
+// the plugins the bot runs.
+//
+// The help command itself comes with the robot's command router
+// (see bot.Robot.Commands); this plugin makes sure the router exists even
+// if no other plugin declares commands.
+package help
+
+import (
+	"context"
+	"strings"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// New returns the help commands as a plugin named "help".
+func New() bot.Plugin {
+	return &plugin{}
+}
+
+type plugin struct{}
+
+func (p *plugin) Name() string { return "help" }
+
+func (p *plugin) Description() string {
+	return "コマンドとプラグインの一覧を表示します"
+}
+
+func (p *plugin) Register(r *bot.Robot) error {
+	r.Commands().Command("plugins", "プラグインの一覧を表示します", func(ctx context.Context, res bot.Response, args bot.Args) {
+		res.Send(List(r))
+	})
+	return nil
+}
+
+// List returns one line per plugin registered on r, with its description
+// if the plugin implements bot.PluginDescriber.
+func List(r *bot.Robot) string {
+	plugins := r.Plugins()
+	if len(plugins) == 0 {
+		return "プラグインはありません。"
+	}
+	lines := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		line := p.Name()
+		if d, ok := p.(bot.PluginDescriber); ok {
+			line += " - " + d.Description()
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,36 @@
+package help
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot/plugins/ping"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot/plugins/remind"
+)
+
+func TestList(t *testing.T) {
+	robot := bot.New()
+	if got := List(robot); got != "プラグインはありません。" {
+		t.Errorf("List() = %q", got)
+	}
+
+	if err := robot.UsePlugins(New(), ping.New(), remind.New()); err != nil {
+		t.Fatalf("UsePlugins failed: %v", err)
+	}
+	lines := strings.Split(List(robot), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "help - ") || !strings.HasPrefix(lines[2], "remind - ") {
+		t.Errorf("Unexpected plugin list %q", lines)
+	}
+
+	var specs []string
+	for _, cmd := range robot.Commands().Commands() {
+		specs = append(specs, strings.Join(cmd.Path, " "))
+	}
+	got := strings.Join(specs, ",")
+	for _, want := range []string{"help", "plugins", "ping", "remind me in"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected command %q, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package ping provides a "ping" command that answers "PONG", to check
+// that a bot is alive.
+package ping
+
+import (
+	"context"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// New returns the ping command as a plugin named "ping".
+// The "reply" setting changes the answer.
+func New() bot.Plugin {
+	return &plugin{reply: "PONG"}
+}
+
+type plugin struct {
+	reply string
+}
+
+func (p *plugin) Name() string { return "ping" }
+
+func (p *plugin) Description() string {
+	return "ping に応答して、ボットが動いていることを確認します"
+}
+
+func (p *plugin) Configure(cfg bot.PluginConfig) error {
+	p.reply = cfg.String("reply", p.reply)
+	return nil
+}
+
+func (p *plugin) Register(r *bot.Robot) error {
+	r.Commands().Command("ping", "ボットの応答を確認します", func(ctx context.Context, res bot.Response, args bot.Args) {
+		res.Send(p.reply)
+	})
+	return nil
+}
@@ -0,0 +1,339 @@
+// Package remind provides reminder commands backed by direct's server-side
+// scheduled messages, so reminders are sent even if the bot restarts.
+//
+//	remind me at 15:00 <message>
+//	remind me at 2026-10-20 9:30 <message>
+//	remind me in 30m <message>
+//	list reminders
+//	cancel reminder <n>
+//
+// The IDs of the scheduled messages are recorded in the brain of the user
+// who created them, so list and cancel only show that user's reminders in
+// the talk, not other scheduled messages of the bot. The robot's default
+// brain is in memory, so to list and cancel reminders after a restart, use
+// a persistent brain such as bot.WithBrain(bot.NewFileBrain(path)) or
+// brain_file in daabgo.yaml.
+//
+// Use Register to add the commands to a command router, or New to add them
+// to a robot as a plugin.
+package remind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Option configures the reminder commands.
+type Option func(*reminder)
+
+// WithLocation sets the time zone used to read and show reminder times.
+// The default is the local time zone.
+func WithLocation(loc *time.Location) Option {
+	return func(r *reminder) {
+		r.loc = loc
+	}
+}
+
+type reminder struct {
+	loc *time.Location
+	now func() time.Time
+	mu  sync.Mutex // guards the recorded IDs in the brain
+}
+
+// Register adds the reminder commands to commands.
+func Register(commands *bot.Commands, opts ...Option) {
+	r := &reminder{
+		loc: time.Local,
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	commands.Command("remind me at <when...>", "指定した日時にリマインドします (例: remind me at 15:00 会議)", r.remindAt)
+	commands.Command("remind me in <after:duration> <message...>", "指定した時間後にリマインドします (例: remind me in 30m 休憩)", r.remindIn)
+	commands.Command("list reminders", "このトークで自分が登録したリマインダーを表示します", r.list)
+	commands.Command("cancel reminder <n:int>", "リマインダーを取り消します", r.cancel)
+}
+
+// New returns the reminder commands as a plugin named "remind".
+// The "location" setting names the time zone, e.g. "Asia/Tokyo", and
+// overrides WithLocation.
+func New(opts ...Option) bot.Plugin {
+	return &plugin{opts: opts}
+}
+
+type plugin struct {
+	opts []Option
+}
+
+func (p *plugin) Name() string { return "remind" }
+
+func (p *plugin) Description() string {
+	return "指定した日時にメッセージでリマインドします"
+}
+
+func (p *plugin) Configure(cfg bot.PluginConfig) error {
+	name, ok := cfg.Lookup("location")
+	if !ok {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid location %q: %w", name, err)
+	}
+	p.opts = append(p.opts, WithLocation(loc))
+	return nil
+}
+
+func (p *plugin) Register(r *bot.Robot) error {
+	Register(r.Commands(), p.opts...)
+	return nil
+}
+
+func (r *reminder) remindAt(ctx context.Context, res bot.Response, args bot.Args) {
+	at, message, err := parseWhen(args.Strings("when"), r.now().In(r.loc))
+	if err != nil {
+		res.Send(err.Error())
+		return
+	}
+	r.schedule(ctx, res, at, message)
+}
+
+func (r *reminder) remindIn(ctx context.Context, res bot.Response, args bot.Args) {
+	at := r.now().In(r.loc).Add(args.Duration("after"))
+	r.schedule(ctx, res, at, strings.Join(args.Strings("message"), " "))
+}
+
+func (r *reminder) schedule(ctx context.Context, res bot.Response, at time.Time, message string) {
+	if message == "" {
+		res.Send("リマインドする内容を指定してください。")
+		return
+	}
+	if err := checkTime(at, r.now()); err != nil {
+		res.Send(err.Error())
+		return
+	}
+
+	text := res.SenderMention() + " リマインダー: " + message
+	m, err := res.Robot.ScheduleText(ctx, res.RoomID(), text, at)
+	if err != nil {
+		res.Send(fmt.Sprintf("リマインダーを登録できませんでした: %v", err))
+		return
+	}
+	if err := r.record(res, m.ID); err != nil {
+		res.Robot.DeleteScheduledMessage(ctx, m.ID)
+		res.Send(fmt.Sprintf("リマインダーを登録できませんでした: %v", err))
+		return
+	}
+	res.Send(fmt.Sprintf("%s にリマインドします。", r.format(at)))
+}
+
+// checkTime reports an error if at is not at least a minute after now.
+func checkTime(at, now time.Time) error {
+	if !at.After(now) {
+		return errors.New("過去の日時は指定できません。")
+	}
+	if at.Sub(now) < time.Minute {
+		return errors.New("1分以上先の時間を指定してください。")
+	}
+	return nil
+}
+
+func (r *reminder) list(ctx context.Context, res bot.Response, args bot.Args) {
+	reminders, err := r.reminders(ctx, res)
+	if err != nil {
+		res.Send(fmt.Sprintf("リマインダーを取得できませんでした: %v", err))
+		return
+	}
+	if len(reminders) == 0 {
+		res.Send("リマインダーはありません。")
+		return
+	}
+
+	lines := []string{"リマインダー一覧:"}
+	for i, m := range reminders {
+		lines = append(lines, fmt.Sprintf("%d. %s %s", i+1, r.format(m.ScheduledAt), direct.StripMentions(m.Text())))
+	}
+	res.Send(strings.Join(lines, "\n"))
+}
+
+func (r *reminder) cancel(ctx context.Context, res bot.Response, args bot.Args) {
+	reminders, err := r.reminders(ctx, res)
+	if err != nil {
+		res.Send(fmt.Sprintf("リマインダーを取得できませんでした: %v", err))
+		return
+	}
+	n := args.Int("n")
+	if n < 1 || n > len(reminders) {
+		res.Send(fmt.Sprintf("リマインダー %d はありません。list reminders で番号を確認してください。", n))
+		return
+	}
+
+	id := reminders[n-1].ID
+	if err := res.Robot.DeleteScheduledMessage(ctx, id); err != nil {
+		res.Send(fmt.Sprintf("リマインダーを取り消せませんでした: %v", err))
+		return
+	}
+	if err := r.forget(res, id); err != nil {
+		res.Send(fmt.Sprintf("リマインダーを取り消しましたが、記録を削除できませんでした: %v", err))
+		return
+	}
+	res.Send(fmt.Sprintf("リマインダー %d を取り消しました。", n))
+}
+
+// reminders returns the sender's reminders in the talk, soonest first.
+// The numbering shown by list reminders follows this order. IDs of
+// reminders that were already sent or deleted are dropped from the brain.
+func (r *reminder) reminders(ctx context.Context, res bot.Response) ([]direct.ScheduledMessage, error) {
+	all, err := res.Robot.ScheduledMessages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids, err := r.ids(res)
+	if err != nil {
+		return nil, err
+	}
+	own, alive := ownReminders(all, ids, res.RoomID())
+	if len(alive) != len(ids) {
+		if err := r.setIDs(res, alive); err != nil {
+			return nil, err
+		}
+	}
+	return own, nil
+}
+
+// ownReminders returns the messages of all that are in talkID and whose
+// IDs are in ids, soonest first, and the IDs that were found.
+func ownReminders(all []direct.ScheduledMessage, ids []string, talkID string) ([]direct.ScheduledMessage, []string) {
+	recorded := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		recorded[id] = true
+	}
+
+	var own []direct.ScheduledMessage
+	var alive []string
+	for _, m := range all {
+		id := fmt.Sprint(m.ID)
+		if recorded[id] && fmt.Sprint(m.TalkID) == talkID {
+			own = append(own, m)
+			alive = append(alive, id)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool {
+		return own[i].ScheduledAt.Before(own[j].ScheduledAt)
+	})
+	return own, alive
+}
+
+// record adds id to the reminders of the sender in the talk.
+func (r *reminder) record(res bot.Response, id interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids, err := r.ids(res)
+	if err != nil {
+		return err
+	}
+	return r.setIDs(res, append(ids, fmt.Sprint(id)))
+}
+
+// forget removes id from the reminders of the sender in the talk.
+func (r *reminder) forget(res bot.Response, id interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids, err := r.ids(res)
+	if err != nil {
+		return err
+	}
+	kept := ids[:0]
+	for _, v := range ids {
+		if v != fmt.Sprint(id) {
+			kept = append(kept, v)
+		}
+	}
+	return r.setIDs(res, kept)
+}
+
+func (r *reminder) ids(res bot.Response) ([]string, error) {
+	var ids []string
+	_, err := bot.GetJSON(res.UserBrain(), brainKey(res.RoomID()), &ids)
+	return ids, err
+}
+
+func (r *reminder) setIDs(res bot.Response, ids []string) error {
+	if len(ids) == 0 {
+		return res.UserBrain().Delete(brainKey(res.RoomID()))
+	}
+	return bot.SetJSON(res.UserBrain(), brainKey(res.RoomID()), ids, 0)
+}
+
+// brainKey is the key of the reminder IDs of a talk in a user's brain.
+func brainKey(talkID string) string {
+	return "remind:" + talkID
+}
+
+func (r *reminder) format(t time.Time) string {
+	return t.In(r.loc).Format("2006-01-02 15:04")
+}
+
+var errBadTime = errors.New("日時は 15:00、2026-10-20 15:00、10/20 15:00、明日 15:00 のように指定してください。")
+
+// parseWhen reads a time from the start of words and returns it with the
+// remaining words joined as the message. Times without a date are today,
+// or tomorrow if already past.
+func parseWhen(words []string, now time.Time) (time.Time, string, error) {
+	if len(words) == 0 {
+		return time.Time{}, "", errBadTime
+	}
+	loc := now.Location()
+	year, month, day := now.Date()
+	explicitDate := false
+
+	switch strings.ToLower(words[0]) {
+	case "today", "今日":
+		words = words[1:]
+		explicitDate = true
+	case "tomorrow", "明日":
+		year, month, day = now.AddDate(0, 0, 1).Date()
+		words = words[1:]
+		explicitDate = true
+	default:
+		if d, err := time.ParseInLocation("2006-01-02", words[0], loc); err == nil {
+			year, month, day = d.Date()
+			words = words[1:]
+			explicitDate = true
+		} else if d, err := time.ParseInLocation("1/2", words[0], loc); err == nil {
+			month, day = d.Month(), d.Day()
+			if time.Date(year, month, day, 23, 59, 0, 0, loc).Before(now) {
+				year++
+			}
+			words = words[1:]
+			explicitDate = true
+		}
+	}
+
+	if len(words) == 0 {
+		return time.Time{}, "", errBadTime
+	}
+	clock, err := time.ParseInLocation("15:04", words[0], loc)
+	if err != nil {
+		return time.Time{}, "", errBadTime
+	}
+
+	at := time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, loc)
+	if !explicitDate && !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, strings.Join(words[1:], " "), nil
+}
@@ -0,0 +1,91 @@
+package remind
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+func TestParseWhen(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2026, 10, 18, 10, 30, 0, 0, jst)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		message string
+	}{
+		{"15:00 会議 の 準備", time.Date(2026, 10, 18, 15, 0, 0, 0, jst), "会議 の 準備"},
+		{"9:00 朝会", time.Date(2026, 10, 19, 9, 0, 0, 0, jst), "朝会"},
+		{"明日 9:00 朝会", time.Date(2026, 10, 19, 9, 0, 0, 0, jst), "朝会"},
+		{"tomorrow 18:30 飲み会", time.Date(2026, 10, 19, 18, 30, 0, 0, jst), "飲み会"},
+		{"2026-12-24 20:00 プレゼント", time.Date(2026, 12, 24, 20, 0, 0, 0, jst), "プレゼント"},
+		{"1/5 9:00 仕事始め", time.Date(2027, 1, 5, 9, 0, 0, 0, jst), "仕事始め"},
+		{"10/20 9:00 定例", time.Date(2026, 10, 20, 9, 0, 0, 0, jst), "定例"},
+	}
+	for _, tt := range tests {
+		at, message, err := parseWhen(strings.Fields(tt.input), now)
+		if err != nil {
+			t.Errorf("parseWhen(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !at.Equal(tt.want) || message != tt.message {
+			t.Errorf("parseWhen(%q) = %v, %q; want %v, %q", tt.input, at, message, tt.want, tt.message)
+		}
+	}
+
+	for _, input := range []string{"", "soon 会議", "明日", "2026-13-01 9:00 x", "25:00 x"} {
+		if _, _, err := parseWhen(strings.Fields(input), now); err == nil {
+			t.Errorf("Expected parseWhen(%q) to fail", input)
+		}
+	}
+}
+
+func TestCheckTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 30, 50, 0, time.UTC)
+	for _, at := range []time.Time{now.Add(-time.Hour), now, now.Add(10 * time.Second)} {
+		if checkTime(at, now) == nil {
+			t.Errorf("Expected %v to be rejected", at)
+		}
+	}
+	if err := checkTime(now.Add(time.Minute), now); err != nil {
+		t.Errorf("Expected a minute ahead to be accepted, got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	commands := bot.NewCommands(bot.New())
+	Register(commands)
+
+	var specs []string
+	for _, cmd := range commands.Commands() {
+		specs = append(specs, strings.Join(cmd.Path, " "))
+	}
+	got := strings.Join(specs, ",")
+	for _, want := range []string{"remind me at", "remind me in", "list reminders", "cancel reminder"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected command %q to be registered, got %s", want, got)
+		}
+	}
+}
+
+func TestOwnReminders(t *testing.T) {
+	base := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	all := []direct.ScheduledMessage{
+		{ID: uint64(1), TalkID: uint64(100), ScheduledAt: base.Add(2 * time.Hour)},
+		{ID: uint64(2), TalkID: uint64(100), ScheduledAt: base.Add(time.Hour)},
+		{ID: uint64(3), TalkID: uint64(100), ScheduledAt: base},
+		{ID: uint64(4), TalkID: uint64(200), ScheduledAt: base},
+	}
+
+	own, alive := ownReminders(all, []string{"1", "2", "4", "9"}, "100")
+	if len(own) != 2 || own[0].ID != uint64(2) || own[1].ID != uint64(1) {
+		t.Errorf("Expected the recorded reminders of the talk soonest first, got %+v", own)
+	}
+	if strings.Join(alive, ",") != "1,2" {
+		t.Errorf("Expected IDs 1 and 2 to be kept, got %v", alive)
+	}
+}
@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// ScheduleText schedules a text message to be sent to a room by the server.
+func (r *Robot) ScheduleText(ctx context.Context, roomID, text string, at time.Time) (*direct.ScheduledMessage, error) {
+	if r.client == nil {
+		return nil, ErrNotConnected
+	}
+	return r.client.ScheduleText(ctx, normalizeRoomID(roomID), text, at)
+}
+
+// ScheduledMessages returns the messages scheduled by the robot.
+func (r *Robot) ScheduledMessages(ctx context.Context) ([]direct.ScheduledMessage, error) {
+	if r.client == nil {
+		return nil, ErrNotConnected
+	}
+	return r.client.GetScheduledMessages(ctx)
+}
+
+// DeleteScheduledMessage cancels a scheduled message.
+func (r *Robot) DeleteScheduledMessage(ctx context.Context, messageID interface{}) error {
+	if r.client == nil {
+		return ErrNotConnected
+	}
+	return r.client.DeleteScheduledMessage(ctx, messageID)
+}
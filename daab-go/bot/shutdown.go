@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultGracePeriod is how long Run waits for in-flight handlers by default.
+const DefaultGracePeriod = 10 * time.Second
+
+// WithGracePeriod sets how long Run waits for in-flight handlers to finish
+// when shutting down. Handlers still running afterwards have their context
+// cancelled and are abandoned.
+func WithGracePeriod(d time.Duration) Option {
+	return func(r *Robot) {
+		r.gracePeriod = d
+	}
+}
+
+// handlerTracker counts in-flight message handling so shutdown can drain it.
+type handlerTracker struct {
+	mu       sync.Mutex
+	n        int
+	stopping bool
+	idle     chan struct{}
+}
+
+// begin registers a new message unless shutdown has started.
+func (t *handlerTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopping {
+		return false
+	}
+	t.n++
+	return true
+}
+
+// add registers work spawned by already accepted work.
+func (t *handlerTracker) add() {
+	t.mu.Lock()
+	t.n++
+	t.mu.Unlock()
+}
+
+func (t *handlerTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.n--
+	if t.n == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// stop rejects new messages and returns a channel closed once all
+// accepted work has finished.
+func (t *handlerTracker) stop() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopping = true
+	idle := make(chan struct{})
+	if t.n == 0 {
+		close(idle)
+	} else {
+		t.idle = idle
+	}
+	return idle
+}
+
+func (t *handlerTracker) running() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n
+}
+
+// drain stops accepting messages and waits up to the grace period for
+// in-flight handlers. cancelHandlers is called if the grace period expires.
+func (r *Robot) drain(cancelHandlers context.CancelFunc) {
+	idle := r.handlers.stop()
+	if r.gracePeriod <= 0 {
+		cancelHandlers()
+		return
+	}
+
+	timer := time.NewTimer(r.gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-idle:
+	case <-timer.C:
+		log.Printf("Warning: %s: %d handler(s) still running after %v, cancelling",
+			r.Name, r.handlers.running(), r.gracePeriod)
+		cancelHandlers()
+	}
+}
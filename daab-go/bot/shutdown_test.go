@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestDrainWaitsForHandlers(t *testing.T) {
+	robot := New()
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	var finished atomic.Int32
+	robot.Hear("work", func(ctx context.Context, res Response) {
+		started <- struct{}{}
+		<-release
+		finished.Add(1)
+	})
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: "work"})
+	<-started
+
+	drained := make(chan struct{})
+	go func() {
+		robot.drain(func() {})
+		close(drained)
+	}()
+
+	// Messages arriving during shutdown are dropped.
+	waitFor(t, func() bool {
+		robot.handlers.mu.Lock()
+		defer robot.handlers.mu.Unlock()
+		return robot.handlers.stopping
+	})
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: "work"})
+
+	select {
+	case <-drained:
+		t.Fatal("Expected drain to wait for the running handler")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("Expected drain to finish after the handler returned")
+	}
+	if n := finished.Load(); n != 1 {
+		t.Errorf("Expected only the accepted message to be handled, got %d", n)
+	}
+}
+
+func TestDrainGracePeriod(t *testing.T) {
+	robot := New(WithGracePeriod(20 * time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelled := make(chan struct{})
+	robot.Hear("work", func(ctx context.Context, res Response) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+	robot.handleMessage(ctx, direct.ReceivedMessage{Text: "work"})
+
+	start := time.Now()
+	robot.drain(cancel)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected drain to wait for the grace period, returned after %v", elapsed)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected handler context to be cancelled after the grace period")
+	}
+}
+
+func TestRunDrainsBeforeReturning(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	robot := New(WithToken("token"), WithEndpoint(mockServer.URL()))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	robot.Hear("work", func(ctx context.Context, res Response) {
+		close(started)
+		<-release
+	})
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{Text: "work"})
+	<-started
+
+	// A cancelled context stops Run right after connecting, without
+	// installing a signal handler; Run still waits for the handler.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected Run to wait for the in-flight handler, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the handler finished")
+	}
+}
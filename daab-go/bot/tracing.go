@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"context"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Attribute keys set on the spans of received messages and handlers.
+const (
+	AttrMessageID = "direct.message_id"
+	AttrTalkID    = "direct.talk_id"
+	AttrUserID    = "direct.user_id"
+	AttrListener  = "daab.listener" // the Listener.Name
+)
+
+// WithTracer traces the robot with t. Each received message is traced in a
+// "daab/receive" span, and each handler run for it in a child span named
+// "daab/handler <listener>". The handler's ctx and Response.Context() carry
+// its span, so RPC calls made through them, such as Response.Send,
+// Robot.CallContext or LookupUser, are traced as its children. The client
+// traces each RPC call in a "direct/<method>" span.
+//
+// See the directotel module for an OpenTelemetry Tracer.
+func WithTracer(t direct.Tracer) Option {
+	return func(r *Robot) {
+		r.tracer = t
+	}
+}
+
+// startSpan starts a span with the robot's tracer. Without a tracer it
+// returns ctx and a span that does nothing.
+func (r *Robot) startSpan(ctx context.Context, name string, attrs ...direct.Attribute) (context.Context, direct.Span) {
+	if r.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return r.tracer.Start(ctx, name, attrs...)
+}
+
+func handlerSpanName(l *Listener) string {
+	if l.Name == "" {
+		return "daab/handler"
+	}
+	return "daab/handler " + l.Name
+}
+
+func messageAttributes(msg direct.ReceivedMessage) []direct.Attribute {
+	return []direct.Attribute{
+		{Key: AttrMessageID, Value: msg.ID},
+		{Key: AttrTalkID, Value: msg.TalkID},
+		{Key: AttrUserID, Value: msg.UserID},
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...direct.Attribute) {}
+func (noopSpan) End(error)                         {}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// recordingTracer records the spans it starts.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	tracer *recordingTracer
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+type spanKey struct{}
+
+func (tr *recordingTracer) Start(ctx context.Context, name string, attrs ...direct.Attribute) (context.Context, direct.Span) {
+	span := &recordedSpan{tracer: tr, name: name, attrs: map[string]interface{}{}}
+	span.parent, _ = ctx.Value(spanKey{}).(*recordedSpan)
+	span.SetAttributes(attrs...)
+	tr.mu.Lock()
+	tr.spans = append(tr.spans, span)
+	tr.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (tr *recordingTracer) find(name string) *recordedSpan {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for _, span := range tr.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...direct.Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+	s.ended = true
+}
+
+func TestTracer(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": "1"})
+	mockServer.OnSimple("get_me", map[string]interface{}{"user_id": "bot"})
+
+	tracer := &recordingTracer{}
+	client := direct.NewClient(direct.Options{Endpoint: mockServer.URL(), Tracer: tracer})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	robot := New(WithTracer(tracer), WithGlobalRateLimit(0, 0), WithTalkRateLimit(0, 0))
+	robot.client = client
+	done := make(chan struct{})
+	robot.Hear("ping", func(ctx context.Context, res Response) {
+		defer close(done)
+		if err := res.Send("pong"); err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+		if _, err := res.Robot.CallContext(ctx, direct.MethodGetMe, []interface{}{}); err != nil {
+			t.Errorf("CallContext failed: %v", err)
+		}
+	})
+	robot.Hear("boom", func(ctx context.Context, res Response) { panic("boom") }, Named("boom"))
+	robot.Use(Recover())
+
+	robot.handleMessage(context.Background(), direct.ReceivedMessage{ID: "10", TalkID: "20", UserID: "30", Text: "ping"})
+	<-done
+	deadline := time.Now().Add(time.Second)
+	for robot.handlers.running() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the handler to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	receive := tracer.find("daab/receive")
+	handler := tracer.find("daab/handler hear:ping")
+	if receive == nil || handler == nil {
+		t.Fatalf("Expected receive and handler spans, got %d spans", len(tracer.spans))
+	}
+	if receive.attrs[AttrMessageID] != "10" || receive.attrs[AttrTalkID] != "20" || receive.attrs[AttrUserID] != "30" {
+		t.Errorf("Unexpected receive attributes: %v", receive.attrs)
+	}
+	if handler.parent != receive || handler.attrs[AttrListener] != "hear:ping" || !handler.ended {
+		t.Errorf("Unexpected handler span: %+v", handler)
+	}
+	for _, name := range []string{"direct/create_message", "direct/get_me"} {
+		if span := tracer.find(name); span == nil || span.parent != handler {
+			t.Errorf("Expected %s to be traced as a child of the handler, got %+v", name, span)
+		}
+	}
+	if tracer.find("daab/handler boom") != nil {
+		t.Error("Expected no span for listeners that did not match")
+	}
+}
+
+func TestResponseContext(t *testing.T) {
+	if (Response{}).Context() == nil {
+		t.Error("Expected a background context for a Response without one")
+	}
+}
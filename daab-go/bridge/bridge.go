@@ -0,0 +1,282 @@
+// Package bridge mirrors direct talks to other chat services through their
+// incoming webhooks: Slack and Slack-compatible services (Mattermost,
+// Rocket.Chat, ...) with Slack, and Microsoft Teams connectors with Teams.
+//
+// A Bridge is a bot.Plugin. Each route mirrors the messages accepted by its
+// listener options, usually bot.InTalks, to its targets:
+//
+//	b := bridge.New(
+//		bridge.MirrorTalks([]string{"123456"}, bridge.NewSlack(slackURL)),
+//		bridge.Route([]bridge.Target{bridge.NewTeams(teamsURL)}, bot.InPairTalks()),
+//	)
+//	robot.UsePlugins(b)
+//
+// Mentions, stamps and files are translated into each service's format.
+// To prevent loops, messages sent by the robot itself and messages carrying
+// the bridge marker (see Mark) are never mirrored.
+//
+// Connectors relay in both directions. The bridge links external threads to
+// talks in a Mapping, correlates message IDs so that replies land in the
+// right talk, and receives messages on POST /bridge/{connector}:
+//
+//	b := bridge.New(
+//		bridge.Connect(bridge.NewJSON("teams", n8nURL, bridge.WithSecret(secret)), bot.InPairTalks()),
+//		bridge.WithAddr(":8080"),
+//	)
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Marker is appended by Mark to text relayed into direct from other
+// services. It is invisible in the direct clients.
+const Marker = "\u2060\u2060"
+
+// Mark appends Marker to text so that a bridge does not mirror it back to
+// where it came from. Text sent into direct by other relays should be marked.
+func Mark(text string) string {
+	return text + Marker
+}
+
+// IsMarked reports whether text was marked with Mark.
+func IsMarked(text string) bool {
+	return strings.Contains(text, Marker)
+}
+
+// File is a file attached to a mirrored message.
+type File struct {
+	Name        string `json:"name"`
+	URL         string `json:"url,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// Message is a direct message to be mirrored.
+type Message struct {
+	ID       string
+	TalkID   string
+	TalkName string
+	UserID   string
+	UserName string
+
+	// Text is the message text with direct mention markup; targets render
+	// it with Mentions. For stamps it is the stamp text, if any.
+	Text string
+
+	// Stamp is set for stamp messages.
+	Stamp bool
+
+	// Files are the attached files.
+	Files []File
+}
+
+// Target is a service messages are mirrored to.
+type Target interface {
+	// Name identifies the target in logs.
+	Name() string
+
+	// Post sends msg to the service.
+	Post(ctx context.Context, msg *Message) error
+}
+
+// mirroredTypes are the message types a bridge mirrors.
+var mirroredTypes = []direct.MessageType{
+	direct.MessageTypeText,
+	direct.MessageTypeStamp,
+	direct.MessageTypeOriginalStamp,
+	direct.MessageTypeFile,
+	direct.MessageTypeTextMultipleFile,
+}
+
+// route mirrors the messages accepted by opts to targets.
+type route struct {
+	targets []Target
+	opts    []bot.ListenerOption
+}
+
+// Bridge is a bot.Plugin named "bridge" mirroring direct talks to targets
+// and relaying messages in both directions through connectors.
+type Bridge struct {
+	routes      []route
+	connections []connection
+	mapping     *Mapping
+	robot       *bot.Robot
+
+	addr            string
+	unauthenticated bool
+	mux             *http.ServeMux
+	httpServer      *http.Server
+
+	// seen holds the IDs of recently mirrored messages, so that a message
+	// delivered twice is mirrored once.
+	mu   sync.Mutex
+	seen map[string]bool
+	ids  []string
+}
+
+// Option configures a Bridge.
+type Option func(*Bridge)
+
+// Route mirrors the messages accepted by the listener options, such as
+// bot.InTalks or bot.InPairTalks, to targets.
+func Route(targets []Target, opts ...bot.ListenerOption) Option {
+	return func(b *Bridge) {
+		b.routes = append(b.routes, route{targets: targets, opts: opts})
+	}
+}
+
+// MirrorTalks mirrors the talks with the given IDs to targets.
+func MirrorTalks(talkIDs []string, targets ...Target) Option {
+	return Route(targets, bot.InTalks(talkIDs...))
+}
+
+// maxSeen is the number of message IDs remembered for deduplication.
+const maxSeen = 1000
+
+// New returns a bridge with the given routes.
+func New(opts ...Option) *Bridge {
+	b := &Bridge{seen: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Name returns "bridge".
+func (b *Bridge) Name() string { return "bridge" }
+
+// Description describes the plugin.
+func (b *Bridge) Description() string {
+	return "トークのメッセージを Slack や Teams に転送します"
+}
+
+// Configure adds a route from the "talks" (comma separated), "slack_url"
+// and "teams_url" settings, if "talks" is set.
+func (b *Bridge) Configure(cfg bot.PluginConfig) error {
+	if _, ok := cfg.Lookup("talks"); !ok {
+		return nil
+	}
+	talkIDs := cfg.Strings("talks", nil)
+
+	var targets []Target
+	if url := cfg.String("slack_url", ""); url != "" {
+		targets = append(targets, NewSlack(url))
+	}
+	if url := cfg.String("teams_url", ""); url != "" {
+		targets = append(targets, NewTeams(url))
+	}
+	if len(talkIDs) == 0 || len(targets) == 0 {
+		return errors.New("bridge: talks and slack_url or teams_url are required")
+	}
+	MirrorTalks(talkIDs, targets...)(b)
+	return nil
+}
+
+// Register adds a listener for each route and connection.
+func (b *Bridge) Register(r *bot.Robot) error {
+	if len(b.routes) == 0 && len(b.connections) == 0 {
+		return errors.New("bridge: no routes or connections")
+	}
+	if err := b.checkAuth(); err != nil {
+		return err
+	}
+	b.robot = r
+	if b.mapping == nil {
+		b.mapping = NewMapping(r.Brain(), 0)
+	}
+	for i, rt := range b.routes {
+		opts := append([]bot.ListenerOption{bot.OfTypes(mirroredTypes...)}, rt.opts...)
+		r.Hear(".*", func(ctx context.Context, res bot.Response) {
+			b.mirror(ctx, res, i)
+		}, opts...)
+	}
+	for i, c := range b.connections {
+		opts := append([]bot.ListenerOption{bot.OfTypes(mirroredTypes...)}, c.opts...)
+		r.Hear(".*", func(ctx context.Context, res bot.Response) {
+			b.relay(ctx, res, i)
+		}, opts...)
+	}
+
+	b.mux = http.NewServeMux()
+	b.mux.HandleFunc("POST /bridge/{connector}", b.handleInbound)
+	return nil
+}
+
+// mirror posts the message of res to the targets of the i-th route.
+func (b *Bridge) mirror(ctx context.Context, res bot.Response, i int) {
+	msg := res.Message
+	if msg.UserID != "" && msg.UserID == b.robot.SelfID() || IsMarked(msg.Text) {
+		return
+	}
+	if !b.firstSeen(msg.ID, i) {
+		return
+	}
+
+	m := b.message(ctx, res)
+	for _, target := range b.routes[i].targets {
+		if err := target.Post(ctx, m); err != nil {
+			log.Printf("Warning: bridge: mirroring message %s to %s: %v", msg.ID, target.Name(), err)
+		}
+	}
+}
+
+// firstSeen reports whether the message with id is mirrored for the first
+// time by route, a route index or, for connections, -1 minus the connection
+// index. Messages matching several routes are mirrored by each.
+func (b *Bridge) firstSeen(id string, route int) bool {
+	if id == "" {
+		return true
+	}
+	key := fmt.Sprintf("%s/%d", id, route)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.seen[key] {
+		return false
+	}
+	b.seen[key] = true
+	b.ids = append(b.ids, key)
+	if len(b.ids) > maxSeen {
+		delete(b.seen, b.ids[0])
+		b.ids = b.ids[1:]
+	}
+	return true
+}
+
+// message converts the message of res, looking up the names of the sender
+// and the talk. Names that cannot be looked up are left empty.
+func (b *Bridge) message(ctx context.Context, res bot.Response) *Message {
+	msg := res.Message
+	m := &Message{
+		ID:     msg.ID,
+		TalkID: msg.TalkID,
+		UserID: msg.UserID,
+		Text:   msg.Text,
+	}
+	if user, err := b.robot.LookupUser(ctx, msg.DomainID, msg.UserID); err == nil {
+		m.UserName = user.DisplayName
+		if m.UserName == "" {
+			m.UserName = user.Name
+		}
+	}
+	if talk, err := b.robot.LookupTalk(ctx, msg.TalkID); err == nil {
+		m.TalkName = talk.Name
+	}
+	if stamp := res.Stamp(); stamp != nil {
+		m.Stamp = true
+		m.Text = stamp.Text
+	}
+	for _, f := range res.Files() {
+		m.Files = append(m.Files, File{Name: f.Name, URL: f.URL, ContentType: f.ContentType, Size: f.ContentSize})
+	}
+	return m
+}
@@ -0,0 +1,177 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// readyPlugin signals that the robot is connected.
+type readyPlugin struct {
+	started chan struct{}
+}
+
+func (p *readyPlugin) Name() string                    { return "ready" }
+func (p *readyPlugin) Register(r *bot.Robot) error     { return nil }
+func (p *readyPlugin) Start(ctx context.Context) error { close(p.started); return nil }
+
+// receiver is a local incoming webhook recording the posted bodies.
+func receiver(t *testing.T) (*httptest.Server, chan map[string]interface{}) {
+	t.Helper()
+	bodies := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		bodies <- body
+	}))
+	t.Cleanup(server.Close)
+	return server, bodies
+}
+
+func receive(t *testing.T, bodies chan map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	select {
+	case body := <-bodies:
+		return body
+	case <-time.After(time.Second):
+		t.Fatal("Expected a message to be mirrored")
+		return nil
+	}
+}
+
+func TestFormat(t *testing.T) {
+	m := &Message{
+		UserName: "Alice",
+		TalkName: "開発",
+		Text:     direct.MentionMarkup("42", "Bob") + " see <this> & *that*\nthanks",
+		Files:    []File{{Name: "a|b.pdf", URL: "https://files.example.com/1"}},
+	}
+
+	slack := NewSlack("").Payload(m)
+	if slack.Text != "*@Bob* see &lt;this&gt; &amp; *that*\nthanks\n<https://files.example.com/1|a¦b.pdf>" {
+		t.Errorf("Unexpected Slack text %q", slack.Text)
+	}
+	if slack.Username != "Alice (開発)" {
+		t.Errorf("Unexpected Slack username %q", slack.Username)
+	}
+
+	card := NewTeams("").Card(m)
+	if card.Text != `**@Bob** see &lt;this&gt; & \*that\*`+"\n\nthanks\n\n[a|b.pdf](https://files.example.com/1)" {
+		t.Errorf("Unexpected Teams text %q", card.Text)
+	}
+	if card.Type != "MessageCard" || card.Title != "Alice (開発)" || card.Summary != "@Bob see <this> & *that*\nthanks" {
+		t.Errorf("Unexpected Teams card %+v", card)
+	}
+
+	stamp := &Message{UserID: "42", Stamp: true}
+	if text := NewSlack("").Payload(stamp).Text; text != DefaultStampText {
+		t.Errorf("Expected the default stamp text, got %q", text)
+	}
+	stamp.Text = "了解"
+	if card := NewTeams("").Card(stamp); card.Text != "(了解)" || card.Summary != "42" {
+		t.Errorf("Unexpected stamp card %+v", card)
+	}
+}
+
+func TestBridge(t *testing.T) {
+	slackServer, slackBodies := receiver(t)
+	teamsServer, teamsBodies := receiver(t)
+
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{"talk_id": uint64(100), "domain_id": uint64(1), "type": int8(2), "name": "開発"},
+	})
+	mockServer.OnSimple("get_users", []interface{}{
+		map[string]interface{}{"id": uint64(42), "display_name": "Alice"},
+	})
+
+	robot := bot.New(
+		bot.WithToken("token"),
+		bot.WithEndpoint(mockServer.URL()),
+	)
+	b := New(
+		MirrorTalks([]string{"100"}, NewSlack(slackServer.URL)),
+		Route([]Target{NewTeams(teamsServer.URL)}, bot.InTalks("100")),
+	)
+	ready := &readyPlugin{started: make(chan struct{})}
+	if err := robot.UsePlugins(b, ready); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	select {
+	case <-ready.started:
+	case err := <-done:
+		t.Fatalf("Run failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Robot did not start")
+	}
+
+	send := func(id, talkID string, msgType direct.MessageType, content interface{}) {
+		mockServer.SendNotification("notify_create_message", map[string]interface{}{
+			"message_id": id, "talk_id": talkID, "user_id": uint64(42), "domain_id": uint64(1),
+			"type": int(msgType), "content": content,
+		})
+	}
+	send("1", "200", direct.MessageTypeText, "other talk")
+	send("2", "100", direct.MessageTypeText, Mark("relayed from Slack"))
+	send("3", "100", direct.MessageTypeText, "hello")
+	send("3", "100", direct.MessageTypeText, "hello")
+	send("4", "100", direct.MessageTypeStamp, map[string]interface{}{"stamp_set": "3", "stamp_index": "1", "text": "OK"})
+
+	// Messages are handled concurrently, so they may arrive in any order.
+	texts := func(bodies chan map[string]interface{}, header string) map[string]interface{} {
+		got := make(map[string]interface{})
+		for i := 0; i < 2; i++ {
+			body := receive(t, bodies)
+			got[body["text"].(string)] = body[header]
+		}
+		return got
+	}
+	if got := texts(slackBodies, "username"); len(got) != 2 || got["hello"] != "Alice (開発)" || got["(OK)"] == nil {
+		t.Errorf("Unexpected Slack messages %v", got)
+	}
+	if got := texts(teamsBodies, "title"); len(got) != 2 || got["hello"] != "Alice (開発)" || got["(OK)"] == nil {
+		t.Errorf("Unexpected Teams messages %v", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if len(slackBodies) != 0 || len(teamsBodies) != 0 {
+		t.Errorf("Expected other talks, marked and duplicate messages not to be mirrored, got %v %v", <-slackBodies, len(teamsBodies))
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	b := New()
+	if err := b.Configure(bot.NewPluginConfig("bridge", map[string]interface{}{
+		"talks":     []interface{}{"100", "200"},
+		"slack_url": "https://hooks.slack.com/services/x",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.routes) != 1 || b.routes[0].targets[0].Name() != "slack" {
+		t.Errorf("Unexpected routes %+v", b.routes)
+	}
+	if err := b.Configure(bot.NewPluginConfig("bridge", map[string]interface{}{"talks": "100"})); err == nil {
+		t.Error("Expected a route without targets to be rejected")
+	}
+	if err := New().Register(bot.New()); err == nil {
+		t.Error("Expected Register to require a route")
+	}
+}
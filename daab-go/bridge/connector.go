@@ -0,0 +1,329 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// Connector connects a bridge to an external service in both directions.
+// Connectors only convert between the service's formats and Message and
+// Inbound; the bridge keeps the mapping of threads to talks and correlates
+// messages, so that replies land in the right talk.
+type Connector interface {
+	// Name identifies the connector. It is part of the inbound endpoint
+	// path, /bridge/{name}, and of the mapping keys.
+	Name() string
+
+	// Send posts msg to an external thread, or starts a new thread if
+	// thread is "". It returns the thread and the ID of the posted message,
+	// as far as the service reports them.
+	Send(ctx context.Context, thread string, msg *Message) (Sent, error)
+
+	// Parse converts a request received on the inbound endpoint into
+	// messages. It returns an error wrapping ErrUnauthorized if the request
+	// does not authenticate.
+	Parse(req *http.Request, body []byte) ([]Inbound, error)
+}
+
+// Authenticator is implemented by connectors that can authenticate inbound
+// requests. Register fails for connectors that do not authenticate, as
+// anyone reaching the inbound endpoint could post into the linked talks,
+// unless WithUnauthenticatedInbound is used.
+type Authenticator interface {
+	// Authenticates reports whether Parse rejects requests that do not
+	// authenticate, for example because a secret is configured.
+	Authenticates() bool
+}
+
+// Sent is the result of Connector.Send.
+type Sent struct {
+	Thread    string
+	MessageID string
+}
+
+// Inbound is a message received from an external service.
+type Inbound struct {
+	// Thread is the external thread or conversation of the message.
+	Thread string
+
+	// MessageID is the external ID of the message.
+	MessageID string
+
+	// ReplyTo is the external ID of the message replied to, if any.
+	ReplyTo string
+
+	// UserName is the sender's name, shown in direct.
+	UserName string
+
+	// Text is the message text, already converted to plain text.
+	Text string
+
+	// Bot is set for messages sent by bots or integrations, including the
+	// bridge itself. They are not relayed, to prevent loops.
+	Bot bool
+}
+
+// Errors of the inbound endpoint.
+var (
+	ErrUnauthorized = errors.New("bridge: unauthorized")
+	ErrNoTalk       = errors.New("bridge: no talk for thread")
+)
+
+// maxInboundBody is the largest request accepted on the inbound endpoint.
+const maxInboundBody = 1 << 20
+
+// connection is a connector and the messages it relays from direct.
+type connection struct {
+	connector Connector
+	opts      []bot.ListenerOption
+}
+
+// Connect relays the messages accepted by the listener options, such as
+// bot.InPairTalks, through connector, and the connector's inbound messages
+// back to the linked talks.
+func Connect(connector Connector, opts ...bot.ListenerOption) Option {
+	return func(b *Bridge) {
+		b.connections = append(b.connections, connection{connector: connector, opts: opts})
+	}
+}
+
+// WithMapping sets the mapping table. The default is stored in the
+// robot's brain.
+func WithMapping(m *Mapping) Option {
+	return func(b *Bridge) {
+		b.mapping = m
+	}
+}
+
+// WithAddr serves the inbound endpoint on addr, such as ":8080", while the
+// robot runs. Without an address the Bridge can be mounted as an
+// http.Handler on another server.
+func WithAddr(addr string) Option {
+	return func(b *Bridge) {
+		b.addr = addr
+	}
+}
+
+// WithUnauthenticatedInbound accepts inbound requests for connectors that do
+// not authenticate them, such as a JSON connector without a secret. Use it
+// only when the endpoint is protected otherwise, for example by a reverse
+// proxy.
+func WithUnauthenticatedInbound() Option {
+	return func(b *Bridge) {
+		b.unauthenticated = true
+	}
+}
+
+// checkAuth returns an error if a connector does not authenticate inbound
+// requests and the bridge does not accept unauthenticated ones.
+func (b *Bridge) checkAuth() error {
+	if b.unauthenticated {
+		return nil
+	}
+	for _, c := range b.connections {
+		if a, ok := c.connector.(Authenticator); !ok || !a.Authenticates() {
+			return fmt.Errorf("bridge: connector %s does not authenticate inbound requests; set a secret or use WithUnauthenticatedInbound", c.connector.Name())
+		}
+	}
+	return nil
+}
+
+// Mapping returns the mapping table, once the bridge is registered.
+func (b *Bridge) Mapping() *Mapping {
+	return b.mapping
+}
+
+// relay sends the message of res through the i-th connection.
+func (b *Bridge) relay(ctx context.Context, res bot.Response, i int) {
+	msg := res.Message
+	if msg.UserID != "" && msg.UserID == b.robot.SelfID() || IsMarked(msg.Text) {
+		return
+	}
+	if !b.firstSeen(msg.ID, -1-i) {
+		return
+	}
+
+	conn := b.connections[i].connector
+	name := conn.Name()
+	link, linked, err := b.mapping.ByTalk(name, msg.TalkID)
+	if err != nil {
+		log.Printf("Warning: bridge: looking up the thread of talk %s: %v", msg.TalkID, err)
+	}
+	thread := ""
+	if linked {
+		thread = link.Thread
+	}
+
+	sent, err := conn.Send(ctx, thread, b.message(ctx, res))
+	if err != nil {
+		log.Printf("Warning: bridge: relaying message %s to %s: %v", msg.ID, name, err)
+		return
+	}
+	if sent.Thread == "" {
+		sent.Thread = thread
+	}
+	if !linked && sent.Thread != "" {
+		if err := b.mapping.Link(Link{Connector: name, Thread: sent.Thread, TalkID: msg.TalkID, UserID: msg.UserID}); err != nil {
+			log.Printf("Warning: bridge: linking thread %s: %v", sent.Thread, err)
+		}
+	}
+	if sent.MessageID != "" {
+		ml := MessageLink{Connector: name, ExternalID: sent.MessageID, DirectID: msg.ID, TalkID: msg.TalkID, Thread: sent.Thread}
+		if err := b.mapping.Correlate(ml); err != nil {
+			log.Printf("Warning: bridge: correlating message %s: %v", msg.ID, err)
+		}
+	}
+}
+
+// Receive relays an inbound message of the named connector to the talk
+// linked to the message it replies to or to its thread, and returns the
+// ID of the direct message. Messages from bots are skipped and return "".
+func (b *Bridge) Receive(ctx context.Context, connector string, in Inbound) (string, error) {
+	if in.Bot {
+		return "", nil
+	}
+
+	talkID := ""
+	if in.ReplyTo != "" {
+		if ml, ok, err := b.mapping.ByExternalMessage(connector, in.ReplyTo); err != nil {
+			return "", err
+		} else if ok {
+			talkID = ml.TalkID
+			if in.Thread == "" {
+				in.Thread = ml.Thread
+			}
+		}
+	}
+	if talkID == "" && in.Thread != "" {
+		if link, ok, err := b.mapping.ByThread(connector, in.Thread); err != nil {
+			return "", err
+		} else if ok {
+			talkID = link.TalkID
+		}
+	}
+	if talkID == "" {
+		return "", fmt.Errorf("%w %q", ErrNoTalk, in.Thread)
+	}
+
+	text := in.Text
+	if in.UserName != "" {
+		text = in.UserName + ": " + text
+	}
+	id, err := b.robot.SendTextAsync(talkID, Mark(text)).Wait(ctx)
+	if err != nil {
+		return "", err
+	}
+	if in.MessageID != "" {
+		ml := MessageLink{Connector: connector, ExternalID: in.MessageID, DirectID: id, TalkID: talkID, Thread: in.Thread}
+		if err := b.mapping.Correlate(ml); err != nil {
+			log.Printf("Warning: bridge: correlating message %s: %v", in.MessageID, err)
+		}
+	}
+	return id, nil
+}
+
+// InboundResponse is the JSON response of the inbound endpoint.
+type InboundResponse struct {
+	OK         bool     `json:"ok"`
+	MessageIDs []string `json:"messageIds,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ServeHTTP serves the inbound endpoint, POST /bridge/{connector}.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if b.mux == nil {
+		writeJSON(w, http.StatusServiceUnavailable, InboundResponse{Error: "bridge is not registered with a robot"})
+		return
+	}
+	b.mux.ServeHTTP(w, req)
+}
+
+// handleInbound parses a request with its connector and relays the messages.
+func (b *Bridge) handleInbound(w http.ResponseWriter, req *http.Request) {
+	var conn Connector
+	for _, c := range b.connections {
+		if c.connector.Name() == req.PathValue("connector") {
+			conn = c.connector
+		}
+	}
+	if conn == nil {
+		writeJSON(w, http.StatusNotFound, InboundResponse{Error: "no such connector"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxInboundBody))
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, InboundResponse{Error: err.Error()})
+		return
+	}
+	inbound, err := conn.Parse(req, body)
+	if errors.Is(err, ErrUnauthorized) {
+		writeJSON(w, http.StatusUnauthorized, InboundResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, InboundResponse{Error: err.Error()})
+		return
+	}
+
+	resp := InboundResponse{OK: true}
+	for _, in := range inbound {
+		id, err := b.Receive(req.Context(), conn.Name(), in)
+		if err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, ErrNoTalk) {
+				status = http.StatusNotFound
+			}
+			writeJSON(w, status, InboundResponse{MessageIDs: resp.MessageIDs, Error: err.Error()})
+			return
+		}
+		if id != "" {
+			resp.MessageIDs = append(resp.MessageIDs, id)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Start serves the inbound endpoint on the configured address, if any.
+func (b *Bridge) Start(ctx context.Context) error {
+	if b.addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", b.addr)
+	if err != nil {
+		return err
+	}
+	b.httpServer = &http.Server{Handler: b, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("[DEBUG] bridge listening on %s", ln.Addr())
+	go func() {
+		if err := b.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: bridge server: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop shuts the inbound endpoint down, waiting for requests in progress.
+func (b *Bridge) Stop(ctx context.Context) error {
+	if b.httpServer == nil {
+		return nil
+	}
+	return b.httpServer.Shutdown(ctx)
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp InboundResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Warning: bridge: writing response: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestJSONConnector(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []JSONMessage
+	)
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m JSONMessage
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Errorf("Failed to decode message: %v", err)
+		}
+		mu.Lock()
+		received = append(received, m)
+		mu.Unlock()
+		thread := m.Thread
+		if thread == "" {
+			thread = "thread-" + m.TalkID
+		}
+		json.NewEncoder(w).Encode(JSONSent{Thread: thread, MessageID: "ext-" + m.MessageID})
+	}))
+	defer service.Close()
+
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	var n atomic.Int32
+	mockServer.On("create_message", func(params []interface{}) (interface{}, error) {
+		return map[string]interface{}{"message_id": fmt.Sprint("d", n.Add(1))}, nil
+	})
+
+	robot := bot.New(
+		bot.WithToken("token"),
+		bot.WithEndpoint(mockServer.URL()),
+		bot.WithGlobalRateLimit(0, 0),
+		bot.WithTalkRateLimit(0, 0),
+	)
+	const secret = "s3cret"
+	b := New(Connect(NewJSON("n8n", service.URL, WithSecret(secret)), bot.InTalks("100")))
+	ready := &readyPlugin{started: make(chan struct{})}
+	if err := robot.UsePlugins(b, ready); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	select {
+	case <-ready.started:
+	case err := <-done:
+		t.Fatalf("Run failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Robot did not start")
+	}
+
+	// A message from direct starts a thread and is correlated.
+	mockServer.SendNotification("notify_create_message", map[string]interface{}{
+		"message_id": "1", "talk_id": "100", "user_id": uint64(42), "domain_id": uint64(1),
+		"type": int(direct.MessageTypeText), "content": "hello",
+	})
+	deadline := time.Now().Add(time.Second)
+	for {
+		if ml, ok, _ := b.Mapping().ByExternalMessage("n8n", "ext-1"); ok {
+			if ml.DirectID != "1" || ml.TalkID != "100" || ml.Thread != "thread-100" {
+				t.Errorf("Unexpected correlation %+v", ml)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the message to be relayed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	if len(received) != 1 || received[0].Text != "hello" || received[0].Thread != "" || received[0].UserID != "42" {
+		t.Errorf("Unexpected messages %+v", received)
+	}
+	mu.Unlock()
+	if l, ok, _ := b.Mapping().ByThread("n8n", "thread-100"); !ok || l.TalkID != "100" || l.UserID != "42" {
+		t.Errorf("Expected the thread to be linked, got %+v", l)
+	}
+
+	post := func(connector string, body interface{}, sign bool) (int, InboundResponse) {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/bridge/"+connector, bytes.NewReader(data))
+		if sign {
+			ts := time.Now().Unix()
+			req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(ts, 10))
+			req.Header.Set(webhook.SignatureHeader, webhook.Sign([]byte(secret), ts, data))
+		}
+		w := httptest.NewRecorder()
+		b.ServeHTTP(w, req)
+		var resp InboundResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+
+	// Replies are routed by the message they reply to, threads by the link.
+	code, resp := post("n8n", []JSONInbound{
+		{MessageID: "ext-2", ReplyTo: "ext-1", UserName: "Bob", Text: "hi"},
+		{Thread: "thread-100", MessageID: "ext-3", UserName: "Carol", Text: "hey"},
+		{Thread: "thread-100", MessageID: "ext-4", Text: "echo", Bot: true},
+	}, true)
+	if code != http.StatusOK || !resp.OK || len(resp.MessageIDs) != 2 {
+		t.Fatalf("Unexpected response %d %+v", code, resp)
+	}
+	if ml, ok, _ := b.Mapping().ByExternalMessage("n8n", "ext-2"); !ok || ml.TalkID != "100" || ml.DirectID != resp.MessageIDs[0] {
+		t.Errorf("Unexpected correlation of the reply %+v", ml)
+	}
+	var texts []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if len(msg) > 3 && msg[2] == "create_message" {
+			params := msg[3].([]interface{})
+			texts = append(texts, params[2].(string))
+		}
+	}
+	if len(texts) != 2 || texts[0] != Mark("Bob: hi") || texts[1] != Mark("Carol: hey") {
+		t.Errorf("Unexpected messages sent to direct %q", texts)
+	}
+
+	if code, _ := post("n8n", JSONInbound{Thread: "thread-100", Text: "forged"}, false); code != http.StatusUnauthorized {
+		t.Errorf("Expected unsigned requests to be rejected, got %d", code)
+	}
+	if code, _ := post("n8n", JSONInbound{Thread: "unknown", Text: "lost"}, true); code != http.StatusNotFound {
+		t.Errorf("Expected unknown threads to be rejected, got %d", code)
+	}
+	if code, _ := post("slack", JSONInbound{Thread: "thread-100"}, true); code != http.StatusNotFound {
+		t.Errorf("Expected unknown connectors to be rejected, got %d", code)
+	}
+}
+
+func TestConnectorRequiresAuth(t *testing.T) {
+	robot := bot.New()
+	err := New(Connect(NewJSON("n8n", "http://localhost"))).Register(robot)
+	if err == nil || !strings.Contains(err.Error(), "does not authenticate") {
+		t.Errorf("Expected a connector without a secret to be refused, got %v", err)
+	}
+	if err := New(Connect(NewJSON("n8n", "http://localhost")), WithUnauthenticatedInbound()).Register(robot); err != nil {
+		t.Errorf("Expected WithUnauthenticatedInbound to accept it, got %v", err)
+	}
+	if err := New(Connect(NewJSON("n8n", "http://localhost", WithSecret("s")))).Register(robot); err != nil {
+		t.Errorf("Expected a connector with a secret to be accepted, got %v", err)
+	}
+}
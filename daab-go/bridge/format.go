@@ -0,0 +1,59 @@
+package bridge
+
+import (
+	"strings"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// DefaultStampText is shown for stamps without text.
+const DefaultStampText = "(スタンプ)"
+
+// formatter renders messages for a service.
+type formatter struct {
+	// escape escapes plain text.
+	escape func(string) string
+
+	// mention renders a mention of the user with the given display name.
+	mention func(name string) string
+
+	// link renders a link to a file.
+	link func(name, url string) string
+
+	// newline separates lines.
+	newline string
+}
+
+// format renders the text, stamp and files of m.
+func (f formatter) format(m *Message) string {
+	var lines []string
+	switch {
+	case m.Stamp && m.Text == "":
+		lines = append(lines, f.escape(DefaultStampText))
+	case m.Stamp:
+		lines = append(lines, f.escape("("+m.Text+")"))
+	case m.Text != "":
+		lines = append(lines, f.text(m.Text))
+	}
+	for _, file := range m.Files {
+		if file.URL == "" {
+			lines = append(lines, f.escape(file.Name))
+			continue
+		}
+		lines = append(lines, f.link(file.Name, file.URL))
+	}
+	return strings.ReplaceAll(strings.Join(lines, "\n"), "\n", f.newline)
+}
+
+// text renders text with direct mention markup.
+func (f formatter) text(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range direct.ParseMentions(text) {
+		b.WriteString(f.escape(text[last:m.Start]))
+		b.WriteString(f.mention(m.Name))
+		last = m.End
+	}
+	b.WriteString(f.escape(text[last:]))
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+)
+
+// JSON is a Connector exchanging plain JSON with a service or a workflow
+// tool such as n8n, which converts it to the format of the final service.
+//
+// Messages from direct are posted to the URL as JSONMessage, and the
+// response may be a JSONSent naming the thread and message created.
+// Inbound requests carry a JSONInbound, or an array of them.
+type JSON struct {
+	webhookTarget
+}
+
+// NewJSON returns a connector named name posting to url.
+func NewJSON(name, url string, opts ...TargetOption) *JSON {
+	return &JSON{newWebhookTarget(name, url, opts)}
+}
+
+// JSONMessage is the body posted for a message from direct.
+type JSONMessage struct {
+	Thread    string `json:"thread,omitempty"`
+	MessageID string `json:"messageId"`
+	TalkID    string `json:"talkId"`
+	TalkName  string `json:"talkName,omitempty"`
+	UserID    string `json:"userId"`
+	UserName  string `json:"userName,omitempty"`
+	Text      string `json:"text"`
+	Files     []File `json:"files,omitempty"`
+}
+
+// JSONSent is the optional response to a JSONMessage.
+type JSONSent struct {
+	Thread    string `json:"thread"`
+	MessageID string `json:"messageId"`
+}
+
+// JSONInbound is a message received on the inbound endpoint.
+type JSONInbound struct {
+	Thread    string `json:"thread"`
+	MessageID string `json:"messageId"`
+	ReplyTo   string `json:"replyTo,omitempty"`
+	UserName  string `json:"userName"`
+	Text      string `json:"text"`
+	Bot       bool   `json:"bot,omitempty"`
+}
+
+// plainFormat renders messages as plain text.
+var plainFormat = formatter{
+	escape:  func(s string) string { return s },
+	mention: func(name string) string { return "@" + name },
+	link:    func(name, url string) string { return name + " " + url },
+	newline: "\n",
+}
+
+// Authenticates reports whether inbound requests must be signed, which is
+// the case if the connector has a secret.
+func (j *JSON) Authenticates() bool {
+	return j.secret != ""
+}
+
+// Message returns the body posted for m to thread.
+func (j *JSON) Message(thread string, m *Message) *JSONMessage {
+	return &JSONMessage{
+		Thread:    thread,
+		MessageID: m.ID,
+		TalkID:    m.TalkID,
+		TalkName:  m.TalkName,
+		UserID:    m.UserID,
+		UserName:  m.UserName,
+		Text:      plainFormat.format(m),
+		Files:     m.Files,
+	}
+}
+
+// Send posts m to the URL.
+func (j *JSON) Send(ctx context.Context, thread string, m *Message) (Sent, error) {
+	var sent JSONSent
+	if err := j.post(ctx, j.Message(thread, m), &sent); err != nil {
+		return Sent{}, err
+	}
+	return Sent{Thread: sent.Thread, MessageID: sent.MessageID}, nil
+}
+
+// Parse verifies the signature of an inbound request, if the connector has
+// a secret, and decodes its messages.
+func (j *JSON) Parse(req *http.Request, body []byte) ([]Inbound, error) {
+	if j.secret != "" {
+		err := webhook.Verify([]byte(j.secret), req.Header.Get(webhook.SignatureHeader), req.Header.Get(webhook.TimestampHeader), body, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
+	}
+
+	var messages []JSONInbound
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return nil, err
+		}
+	} else {
+		var m JSONInbound
+		if err := json.Unmarshal(body, &m); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	inbound := make([]Inbound, 0, len(messages))
+	for _, m := range messages {
+		inbound = append(inbound, Inbound(m))
+	}
+	return inbound, nil
+}
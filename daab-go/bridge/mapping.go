@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// DefaultMessageTTL is how long message correlations are kept.
+const DefaultMessageTTL = 30 * 24 * time.Hour
+
+// Link maps a thread of an external service to a direct talk.
+type Link struct {
+	Connector string `json:"connector"`
+	Thread    string `json:"thread"`
+	TalkID    string `json:"talkId"`
+
+	// UserID is the direct user who started the thread, if any.
+	UserID string `json:"userId,omitempty"`
+}
+
+// MessageLink correlates a message on an external service with the direct
+// message it was mirrored from or relayed to.
+type MessageLink struct {
+	Connector  string `json:"connector"`
+	ExternalID string `json:"externalId"`
+	DirectID   string `json:"directId"`
+	TalkID     string `json:"talkId"`
+	Thread     string `json:"thread,omitempty"`
+}
+
+// Mapping is the table of links between external threads and direct talks,
+// and of correlated messages, kept in a bot.Brain so that it survives
+// restarts when the brain is persistent (see bot.NewFileBrain).
+type Mapping struct {
+	brain      bot.Brain
+	messageTTL time.Duration
+}
+
+// NewMapping returns a mapping stored in b under keys prefixed with
+// "bridge:". Message correlations expire after messageTTL, or
+// DefaultMessageTTL if it is zero or less; links do not expire.
+func NewMapping(b bot.Brain, messageTTL time.Duration) *Mapping {
+	if messageTTL <= 0 {
+		messageTTL = DefaultMessageTTL
+	}
+	return &Mapping{brain: bot.NewNamespace(b, "bridge"), messageTTL: messageTTL}
+}
+
+// Link adds or replaces the link of l.Thread, and makes it the thread of
+// l.TalkID. The brain is saved right away as links are not recreated.
+func (m *Mapping) Link(l Link) error {
+	if err := bot.SetJSON(m.brain, l.Connector+":thread:"+l.Thread, l, 0); err != nil {
+		return err
+	}
+	if err := bot.SetJSON(m.brain, l.Connector+":talk:"+l.TalkID, l, 0); err != nil {
+		return err
+	}
+	return m.brain.Save()
+}
+
+// ByThread returns the link of an external thread.
+func (m *Mapping) ByThread(connector, thread string) (*Link, bool, error) {
+	var l Link
+	ok, err := bot.GetJSON(m.brain, connector+":thread:"+thread, &l)
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	return &l, true, nil
+}
+
+// ByTalk returns the link of a direct talk.
+func (m *Mapping) ByTalk(connector, talkID string) (*Link, bool, error) {
+	var l Link
+	ok, err := bot.GetJSON(m.brain, connector+":talk:"+talkID, &l)
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	return &l, true, nil
+}
+
+// Unlink removes the link of an external thread.
+func (m *Mapping) Unlink(connector, thread string) error {
+	l, ok, err := m.ByThread(connector, thread)
+	if !ok || err != nil {
+		return err
+	}
+	if err := m.brain.Delete(connector + ":thread:" + thread); err != nil {
+		return err
+	}
+	// The talk may have been linked to another thread since.
+	if current, ok, _ := m.ByTalk(connector, l.TalkID); ok && current.Thread == thread {
+		return m.brain.Delete(connector + ":talk:" + l.TalkID)
+	}
+	return nil
+}
+
+// Correlate records that the external and the direct message of ml are the same.
+func (m *Mapping) Correlate(ml MessageLink) error {
+	if err := bot.SetJSON(m.brain, ml.Connector+":external:"+ml.ExternalID, ml, m.messageTTL); err != nil {
+		return err
+	}
+	return bot.SetJSON(m.brain, ml.Connector+":direct:"+ml.DirectID, ml, m.messageTTL)
+}
+
+// ByExternalMessage returns the correlation of an external message.
+func (m *Mapping) ByExternalMessage(connector, externalID string) (*MessageLink, bool, error) {
+	var ml MessageLink
+	ok, err := bot.GetJSON(m.brain, connector+":external:"+externalID, &ml)
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	return &ml, true, nil
+}
+
+// ByDirectMessage returns the correlation of a direct message.
+func (m *Mapping) ByDirectMessage(connector, directID string) (*MessageLink, bool, error) {
+	var ml MessageLink
+	ok, err := bot.GetJSON(m.brain, connector+":direct:"+directID, &ml)
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	return &ml, true, nil
+}
@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+func TestMapping(t *testing.T) {
+	m := NewMapping(bot.NewMemoryBrain(), time.Hour)
+
+	if err := m.Link(Link{Connector: "n8n", Thread: "t1", TalkID: "100", UserID: "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if l, ok, err := m.ByThread("n8n", "t1"); err != nil || !ok || l.TalkID != "100" || l.UserID != "42" {
+		t.Errorf("Unexpected link by thread %+v %v %v", l, ok, err)
+	}
+	if l, ok, _ := m.ByTalk("n8n", "100"); !ok || l.Thread != "t1" {
+		t.Errorf("Unexpected link by talk %+v", l)
+	}
+	if _, ok, _ := m.ByThread("other", "t1"); ok {
+		t.Error("Expected links to be per connector")
+	}
+
+	// Relinking the talk keeps the old thread routed to it.
+	if err := m.Link(Link{Connector: "n8n", Thread: "t2", TalkID: "100"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Unlink("n8n", "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := m.ByThread("n8n", "t1"); ok {
+		t.Error("Expected t1 to be unlinked")
+	}
+	if l, ok, _ := m.ByTalk("n8n", "100"); !ok || l.Thread != "t2" {
+		t.Errorf("Expected the talk to stay linked to t2, got %+v", l)
+	}
+
+	ml := MessageLink{Connector: "n8n", ExternalID: "e1", DirectID: "d1", TalkID: "100", Thread: "t2"}
+	if err := m.Correlate(ml); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, _ := m.ByExternalMessage("n8n", "e1"); !ok || *got != ml {
+		t.Errorf("Unexpected message by external ID %+v", got)
+	}
+	if got, ok, _ := m.ByDirectMessage("n8n", "d1"); !ok || *got != ml {
+		t.Errorf("Unexpected message by direct ID %+v", got)
+	}
+}
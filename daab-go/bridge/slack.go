@@ -0,0 +1,156 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+)
+
+// DefaultTimeout is the timeout of requests to targets.
+const DefaultTimeout = 10 * time.Second
+
+// TargetOption configures a Slack or Teams target or a JSON connector.
+type TargetOption func(*webhookTarget)
+
+// WithName sets the name of the target in logs.
+func WithName(name string) TargetOption {
+	return func(t *webhookTarget) {
+		t.name = name
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to post messages.
+func WithHTTPClient(client *http.Client) TargetOption {
+	return func(t *webhookTarget) {
+		t.client = client
+	}
+}
+
+// WithSecret signs requests with secret like webhook.Client does. The JSON
+// connector also requires inbound requests to be signed with it.
+func WithSecret(secret string) TargetOption {
+	return func(t *webhookTarget) {
+		t.secret = secret
+	}
+}
+
+// webhookTarget posts JSON to an incoming webhook.
+type webhookTarget struct {
+	name   string
+	url    string
+	secret string
+	client *http.Client
+}
+
+func newWebhookTarget(name, url string, opts []TargetOption) webhookTarget {
+	t := webhookTarget{
+		name:   name,
+		url:    url,
+		client: &http.Client{Timeout: DefaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return t
+}
+
+// Name returns the name of the target.
+func (t *webhookTarget) Name() string { return t.name }
+
+// post sends body as JSON and decodes the response into out, unless nil.
+func (t *webhookTarget) post(ctx context.Context, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.secret != "" {
+		ts := time.Now().Unix()
+		req.Header.Set(webhook.TimestampHeader, strconv.FormatInt(ts, 10))
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign([]byte(t.secret), ts, data))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", t.name, resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s returned invalid JSON: %w", t.name, err)
+	}
+	return nil
+}
+
+// Slack is a Target posting to a Slack incoming webhook, or to any service
+// accepting Slack's incoming webhook payload.
+type Slack struct {
+	webhookTarget
+}
+
+// NewSlack returns a target posting to the incoming webhook at url.
+func NewSlack(url string, opts ...TargetOption) *Slack {
+	return &Slack{newWebhookTarget("slack", url, opts)}
+}
+
+// SlackPayload is the payload of a Slack incoming webhook.
+type SlackPayload struct {
+	Text     string `json:"text"`
+	Username string `json:"username,omitempty"`
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackFormat renders messages in Slack's mrkdwn.
+var slackFormat = formatter{
+	escape:  slackEscaper.Replace,
+	mention: func(name string) string { return "*@" + slackEscaper.Replace(name) + "*" },
+	link: func(name, url string) string {
+		return "<" + url + "|" + strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "|", "¦").Replace(name) + ">"
+	},
+	newline: "\n",
+}
+
+// Payload returns the payload posted for m. The sender and talk are shown
+// as the username.
+func (s *Slack) Payload(m *Message) *SlackPayload {
+	return &SlackPayload{
+		Text:     slackFormat.format(m),
+		Username: sender(m),
+	}
+}
+
+// Post posts m to the webhook.
+func (s *Slack) Post(ctx context.Context, m *Message) error {
+	return s.post(ctx, s.Payload(m), nil)
+}
+
+// sender returns the sender and talk of m, such as "Alice (開発)".
+func sender(m *Message) string {
+	name := m.UserName
+	if name == "" {
+		name = m.UserID
+	}
+	if m.TalkName != "" {
+		name += " (" + m.TalkName + ")"
+	}
+	return name
+}
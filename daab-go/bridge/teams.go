@@ -0,0 +1,72 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Teams is a Target posting message cards to a Microsoft Teams incoming
+// webhook (Office 365 connector).
+type Teams struct {
+	webhookTarget
+}
+
+// NewTeams returns a target posting to the connector at url.
+func NewTeams(url string, opts ...TargetOption) *Teams {
+	return &Teams{newWebhookTarget("teams", url, opts)}
+}
+
+// TeamsCard is a connector message card.
+type TeamsCard struct {
+	Type    string `json:"@type"`
+	Context string `json:"@context"`
+	Summary string `json:"summary"`
+	Title   string `json:"title,omitempty"`
+	Text    string `json:"text"`
+}
+
+var teamsEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`",
+	"<", "&lt;", ">", "&gt;",
+)
+
+// teamsFormat renders messages in the Markdown of message cards, where
+// lines are separated by blank lines.
+var teamsFormat = formatter{
+	escape:  teamsEscaper.Replace,
+	mention: func(name string) string { return "**@" + teamsEscaper.Replace(name) + "**" },
+	link: func(name, url string) string {
+		return "[" + teamsEscaper.Replace(name) + "](" + strings.ReplaceAll(url, ")", "%29") + ")"
+	},
+	newline: "\n\n",
+}
+
+// summaryLength is the number of characters of the text used as the
+// summary of a card, shown in notifications.
+const summaryLength = 50
+
+// Card returns the card posted for m, titled with the sender and talk.
+func (t *Teams) Card(m *Message) *TeamsCard {
+	summary := direct.StripMentions(m.Text)
+	if m.Stamp || summary == "" {
+		summary = sender(m)
+	}
+	if utf8.RuneCountInString(summary) > summaryLength {
+		summary = string([]rune(summary)[:summaryLength]) + "…"
+	}
+	return &TeamsCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Summary: summary,
+		Title:   sender(m),
+		Text:    teamsFormat.format(m),
+	}
+}
+
+// Post posts m to the connector.
+func (t *Teams) Post(ctx context.Context, m *Message) error {
+	return t.post(ctx, t.Card(m), nil)
+}
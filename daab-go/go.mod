@@ -5,9 +5,10 @@ go 1.22
 toolchain go1.22.5
 
 require (
-	github.com/f4ah6o/direct-go-sdk/direct-go v0.0.0-20251215153455-bd7b7d48fdd6
+	github.com/f4ah6o/direct-go-sdk/direct-go v0.0.0-20261018135017-9669f4b13f00
 	github.com/spf13/cobra v1.8.0
 	golang.org/x/term v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -19,3 +20,4 @@ require (
 	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 )
+
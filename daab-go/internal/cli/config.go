@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/spf13/cobra"
+)
+
+// configFlags holds the flags that override the configuration file.
+var configFlags struct {
+	path     string
+	name     string
+	endpoint string
+	proxy    string
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the bot configuration",
+	Long: `Inspect the bot configuration.
+
+Settings are resolved with the precedence flags > environment variables >
+config file (daabgo.yaml, or the file given with --config or DAABGO_CONFIG) > defaults.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "Check the configuration for errors",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, path, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			path = "(no config file)"
+		}
+		fmt.Printf("%s: OK\n", path)
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:          "show",
+	Short:        "Show the effective configuration",
+	SilenceUsage: true,
+	Long:         `Show the configuration after applying the config file, environment variables and flags. The access token is masked.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		data, err := cfg.Redacted().YAML()
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			fmt.Printf("# config file: %s\n", path)
+		}
+		fmt.Print(string(data))
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	addConfigFlags(configValidateCmd)
+	addConfigFlags(configShowCmd)
+}
+
+// addConfigFlags adds the flags read by loadConfig to cmd.
+func addConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&configFlags.path, "config", "", "config file (default "+bot.DefaultConfigFile+" if it exists)")
+	cmd.Flags().StringVar(&configFlags.name, "name", "", "bot name")
+	cmd.Flags().StringVar(&configFlags.endpoint, "endpoint", "", "API endpoint")
+	cmd.Flags().StringVar(&configFlags.proxy, "proxy", "", "proxy URL")
+}
+
+// configPath returns the config file to read: the --config flag, then
+// DAABGO_CONFIG, then daabgo.yaml if it exists.
+func configPath() string {
+	if configFlags.path != "" {
+		return configFlags.path
+	}
+	if path := os.Getenv("DAABGO_CONFIG"); path != "" {
+		return path
+	}
+	if _, err := os.Stat(bot.DefaultConfigFile); err == nil {
+		return bot.DefaultConfigFile
+	}
+	return ""
+}
+
+// loadConfig resolves the configuration for cmd and returns it with the
+// path of the config file used, if any.
+func loadConfig(cmd *cobra.Command) (*bot.Config, string, error) {
+	// Variables in .env count as environment variables.
+	if err := direct.NewAuth().LoadEnv(); err != nil {
+		log.Printf("Warning: could not load .env: %v", err)
+	}
+
+	cfg := bot.DefaultConfig()
+	path := configPath()
+	if path != "" {
+		if err := cfg.LoadConfigFile(path); err != nil {
+			return nil, path, err
+		}
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, path, err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		cfg.Name = configFlags.name
+	}
+	if flags.Changed("endpoint") {
+		cfg.Endpoint = configFlags.endpoint
+	}
+	if flags.Changed("proxy") {
+		cfg.ProxyURL = configFlags.proxy
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, path, err
+	}
+	return cfg, path, nil
+}
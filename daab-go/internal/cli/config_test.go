@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.yaml")
+	content := "name: filebot\nendpoint: wss://file.example.com/api\nproxy_url: http://file-proxy:8080\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DAABGO_NAME", "")
+	t.Setenv("HUBOT_DIRECT_PROXY_URL", "")
+	t.Setenv("HUBOT_DIRECT_ENDPOINT", "wss://env.example.com/api")
+
+	cmd := &cobra.Command{}
+	addConfigFlags(cmd)
+	t.Cleanup(func() { configFlags.path, configFlags.name = "", "" })
+	if err := cmd.Flags().Parse([]string{"--config", path, "--name", "flagbot"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, used, err := loadConfig(cmd)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if used != path {
+		t.Errorf("Expected config file %s, got %s", path, used)
+	}
+	if cfg.Name != "flagbot" {
+		t.Errorf("Expected flag to override the file, got %s", cfg.Name)
+	}
+	if cfg.Endpoint != "wss://env.example.com/api" {
+		t.Errorf("Expected environment to override the file, got %s", cfg.Endpoint)
+	}
+	if cfg.ProxyURL != "http://file-proxy:8080" {
+		t.Errorf("Expected file value without overrides, got %s", cfg.ProxyURL)
+	}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot/hooks"
+	"github.com/spf13/cobra"
+)
+
+// hooksFlags holds the flags of the hooks commands.
+var hooksFlags struct {
+	file       string
+	talk       string
+	template   string
+	rate       int
+	showTokens bool
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage incoming webhooks",
+	Long: `Manage the incoming webhooks served by "daabgo serve-webhooks".
+
+Each hook has a secret URL, /hooks/<token>, and posts the payloads it
+receives to one talk. Hooks are kept in plugins.hooks.file (default
+` + hooks.DefaultFile + `); changes take effect without restarting the server.`,
+}
+
+var hooksCreateCmd = &cobra.Command{
+	Use:          "create [name]",
+	Short:        "Create an incoming webhook for a talk",
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	Long: `Create an incoming webhook posting to the talk given with --talk. The
+optional name identifies the hook in "list" and "revoke".
+
+The payload may be simple JSON such as {"text": "..."} or a Slack incoming
+webhook payload. --template formats it with Go's text/template, for example
+'{{.status}}: {{text .}}'. --rate limits the requests per minute (default ` + fmt.Sprint(hooks.DefaultRateLimit) + `).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := hooksStore(cmd)
+		if err != nil {
+			return err
+		}
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		hook, err := store.Create(hooks.Hook{
+			Name:      name,
+			TalkID:    hooksFlags.talk,
+			Template:  hooksFlags.template,
+			RateLimit: hooksFlags.rate,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created hook %s for talk %s.\n", hookName(hook), hook.TalkID)
+		fmt.Printf("URL: /hooks/%s\n", hook.Token)
+		fmt.Println("Keep the URL secret: anyone who knows it can post to the talk.")
+		return nil
+	},
+}
+
+var hooksListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the incoming webhooks",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := hooksStore(cmd)
+		if err != nil {
+			return err
+		}
+		list, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			fmt.Printf("No hooks in %s.\n", store.Path())
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTALK\tRATE\tTEMPLATE\tCREATED\tTOKEN")
+		for _, hook := range list {
+			token := hook.Token
+			if !hooksFlags.showTokens {
+				token = token[:8] + "..."
+			}
+			rate := hook.RateLimit
+			if rate == 0 {
+				rate = hooks.DefaultRateLimit
+			}
+			template := "-"
+			if hook.Template != "" {
+				template = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d/min\t%s\t%s\t%s\n",
+				hookName(&hook), hook.TalkID, rate, template, hook.CreatedAt.Format("2006-01-02 15:04"), token)
+		}
+		return w.Flush()
+	},
+}
+
+var hooksRevokeCmd = &cobra.Command{
+	Use:          "revoke <name|token>",
+	Short:        "Revoke an incoming webhook",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := hooksStore(cmd)
+		if err != nil {
+			return err
+		}
+		hook, err := store.Revoke(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Revoked hook %s for talk %s.\n", hookName(hook), hook.TalkID)
+		return nil
+	},
+}
+
+func init() {
+	hooksCmd.AddCommand(hooksCreateCmd)
+	hooksCmd.AddCommand(hooksListCmd)
+	hooksCmd.AddCommand(hooksRevokeCmd)
+	for _, cmd := range []*cobra.Command{hooksCreateCmd, hooksListCmd, hooksRevokeCmd} {
+		addConfigFlags(cmd)
+		addHooksFileFlag(cmd)
+	}
+	hooksCreateCmd.Flags().StringVar(&hooksFlags.talk, "talk", "", "talk ID to post to (required)")
+	hooksCreateCmd.Flags().StringVar(&hooksFlags.template, "template", "", "text/template formatting the payload")
+	hooksCreateCmd.Flags().IntVar(&hooksFlags.rate, "rate", 0, "requests per minute (default "+fmt.Sprint(hooks.DefaultRateLimit)+")")
+	hooksCreateCmd.MarkFlagRequired("talk")
+	hooksListCmd.Flags().BoolVar(&hooksFlags.showTokens, "show-tokens", false, "show the full tokens")
+}
+
+func addHooksFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&hooksFlags.file, "file", "", "hook store (default plugins.hooks.file or "+hooks.DefaultFile+")")
+}
+
+// hooksStore returns the hook store configured for cmd.
+func hooksStore(cmd *cobra.Command) (*hooks.Store, error) {
+	cfg, _, err := loadConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPluginFlag(cmd, "file", "hooks", "file"); err != nil {
+		return nil, err
+	}
+	file := bot.NewPluginConfig("hooks", cfg.Plugins["hooks"]).String("file", "")
+	if file == "" {
+		file = hooks.DefaultFile
+	}
+	return hooks.NewStore(file), nil
+}
+
+// setPluginFlag applies a flag, if it was given, to a plugin setting. The
+// setting is passed through its environment variable, which takes
+// precedence over the configuration file.
+func setPluginFlag(cmd *cobra.Command, flag, plugin, key string) error {
+	f := cmd.Flags().Lookup(flag)
+	if f == nil || !f.Changed {
+		return nil
+	}
+	return os.Setenv(bot.NewPluginConfig(plugin, nil).EnvName(key), f.Value.String())
+}
+
+func hookName(hook *hooks.Hook) string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return hook.Token[:8]
+}
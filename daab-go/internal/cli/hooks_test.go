@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot/hooks"
+)
+
+func TestHooksCommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hooks.json")
+	t.Setenv("DAABGO_CONFIG", "")
+	t.Setenv("DAABGO_HOOKS_FILE", "")
+	t.Cleanup(func() { hooksFlags.file, hooksFlags.talk, hooksFlags.rate = "", "", 0 })
+
+	hooksCreateCmd.Flags().Set("file", file)
+	hooksCreateCmd.Flags().Set("talk", "100")
+	hooksCreateCmd.Flags().Set("rate", "10")
+	if err := hooksCreateCmd.RunE(hooksCreateCmd, []string{"ci"}); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if err := hooksCreateCmd.RunE(hooksCreateCmd, []string{"ci"}); err == nil {
+		t.Error("Expected a duplicate name to be rejected")
+	}
+
+	list, err := hooks.NewStore(file).List()
+	if err != nil || len(list) != 1 || list[0].Name != "ci" || list[0].TalkID != "100" || list[0].RateLimit != 10 {
+		t.Fatalf("Unexpected hooks %+v %v", list, err)
+	}
+
+	hooksListCmd.Flags().Set("file", file)
+	if err := hooksListCmd.RunE(hooksListCmd, nil); err != nil {
+		t.Errorf("list failed: %v", err)
+	}
+
+	hooksRevokeCmd.Flags().Set("file", file)
+	if err := hooksRevokeCmd.RunE(hooksRevokeCmd, []string{list[0].Token}); err != nil {
+		t.Errorf("revoke failed: %v", err)
+	}
+	if err := hooksRevokeCmd.RunE(hooksRevokeCmd, []string{"ci"}); err == nil {
+		t.Error("Expected revoking a revoked hook to fail")
+	}
+}
@@ -3,27 +3,60 @@ package cli
 import (
 	"embed"
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
 	"github.com/spf13/cobra"
 )
 
 //go:embed templates/*
 var templateFS embed.FS
 
+var initPlugins []string
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Setup a new daabgo bot project",
-	Long:  `Initialize a new daabgo bot project in the current directory.`,
+	Long: `Initialize a new daabgo bot project in the current directory.
+
+Use --with to include built-in plugins, e.g. daabgo init --with help,ping,remind.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runInit()
 	},
 }
 
+func init() {
+	initCmd.Flags().StringSliceVar(&initPlugins, "with", nil,
+		"built-in plugins to include ("+strings.Join(builtinPluginNames(), ", ")+")")
+}
+
+// builtinPlugins maps the built-in plugin names to their packages.
+var builtinPlugins = map[string]string{
+	"help":   "github.com/f4ah6o/direct-go-sdk/daab-go/bot/plugins/help",
+	"ping":   "github.com/f4ah6o/direct-go-sdk/daab-go/bot/plugins/ping",
+	"remind": "github.com/f4ah6o/direct-go-sdk/daab-go/bot/plugins/remind",
+}
+
+func builtinPluginNames() []string {
+	names := make([]string, 0, len(builtinPlugins))
+	for name := range builtinPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func runInit() error {
+	plugins, err := selectPlugins(initPlugins)
+	if err != nil {
+		return err
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current directory: %w", err)
@@ -44,44 +77,21 @@ func runInit() error {
 	}
 
 	// Create main.go
-	mainContent := `package main
-
-import (
-	"context"
-	"log"
-
-	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
-)
-
-func main() {
-	// Create a new bot instance
-	robot := bot.New(
-		bot.WithName("mybot"),
-	)
-
-	// Register a handler that responds when the bot is directly mentioned
-	robot.Respond("ping", func(ctx context.Context, res bot.Response) {
-		if err := res.Send("PONG"); err != nil {
-			log.Printf("Failed to send response: %v", err)
-		}
-	})
-
-	// Register a handler that listens to all messages
-	robot.Hear(".*", func(ctx context.Context, res bot.Response) {
-		log.Printf("[%s] %s: %s", res.RoomID(), res.UserID(), res.Text())
-	})
-
-	// Run the bot
-	if err := robot.Run(context.Background()); err != nil {
-		log.Fatalf("Failed to run bot: %v", err)
+	mainContent, err := renderMain(MainData{Name: "mybot", Plugins: plugins})
+	if err != nil {
+		return fmt.Errorf("failed to render main.go: %w", err)
 	}
-}
-`
 
 	if err := os.WriteFile("main.go", []byte(mainContent), 0644); err != nil {
 		return fmt.Errorf("failed to create main.go: %w", err)
 	}
 
+	// Create daabgo.yaml
+	configContent := renderConfig(MainData{Name: "mybot", Plugins: plugins})
+	if err := os.WriteFile(bot.DefaultConfigFile, []byte(configContent), 0644); err != nil {
+		return fmt.Errorf("failed to create %s: %w", bot.DefaultConfigFile, err)
+	}
+
 	// Create go.mod
 	goModContent := fmt.Sprintf(`module %s
 
@@ -115,11 +125,73 @@ require github.com/f4ah6o/direct-go-sdk/daab-go v0.0.0
 	fmt.Println("")
 	fmt.Println("The generated bot uses the high-level daab-go/bot framework.")
 	fmt.Println("Customize main.go to add your bot logic (Respond, Hear handlers).")
+	fmt.Println("Settings, including the plugin settings, are read from daabgo.yaml.")
 	fmt.Println("")
 
 	return nil
 }
 
+// PluginImport is a built-in plugin included in a generated project.
+type PluginImport struct {
+	Name       string
+	ImportPath string
+}
+
+// MainData is the template data for main.go and daabgo.yaml.
+type MainData struct {
+	Name    string
+	Plugins []PluginImport
+}
+
+// selectPlugins resolves plugin names given with --with, keeping their order.
+func selectPlugins(names []string) ([]PluginImport, error) {
+	var plugins []PluginImport
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		path, ok := builtinPlugins[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown plugin %q (available: %s)", name, strings.Join(builtinPluginNames(), ", "))
+		}
+		seen[name] = true
+		plugins = append(plugins, PluginImport{Name: name, ImportPath: path})
+	}
+	return plugins, nil
+}
+
+// renderMain renders main.go from the embedded template and formats it.
+func renderMain(data MainData) (string, error) {
+	tmpl, err := templateFS.ReadFile("templates/main.go.tmpl")
+	if err != nil {
+		return "", err
+	}
+	content, err := renderTemplate(string(tmpl), data)
+	if err != nil {
+		return "", err
+	}
+	src, err := format.Source([]byte(content))
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// renderConfig returns a daabgo.yaml naming the bot and enabling its plugins.
+func renderConfig(data MainData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "name: %s\n", data.Name)
+	if len(data.Plugins) > 0 {
+		b.WriteString("plugins:\n")
+		for _, p := range data.Plugins {
+			fmt.Fprintf(&b, "  %s:\n    enabled: true\n", p.Name)
+		}
+	}
+	return b.String()
+}
+
 // Template data for project generation
 type ProjectData struct {
 	Name        string
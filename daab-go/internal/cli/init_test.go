@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+func TestRenderMain(t *testing.T) {
+	plugins, err := selectPlugins([]string{"remind", "help", "remind", " ping"})
+	if err != nil {
+		t.Fatalf("selectPlugins failed: %v", err)
+	}
+	if len(plugins) != 3 || plugins[0].Name != "remind" || plugins[2].Name != "ping" {
+		t.Fatalf("Unexpected plugins %v", plugins)
+	}
+
+	for _, data := range []MainData{
+		{Name: "mybot"},
+		{Name: "mybot", Plugins: plugins},
+	} {
+		src, err := renderMain(data)
+		if err != nil {
+			t.Fatalf("renderMain failed: %v", err)
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), "main.go", src, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("Generated main.go does not parse: %v\n%s", err, src)
+		}
+		imports := make(map[string]bool)
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			imports[path] = true
+		}
+		for _, p := range data.Plugins {
+			if !imports[p.ImportPath] || !strings.Contains(src, p.Name+".New(),") {
+				t.Errorf("Expected plugin %s to be imported and used:\n%s", p.Name, src)
+			}
+		}
+		if !strings.Contains(src, "bot.LoadConfig(bot.DefaultConfigFile)") {
+			t.Errorf("Expected main.go to load daabgo.yaml:\n%s", src)
+		}
+		if len(data.Plugins) == 0 && strings.Contains(src, "UsePlugins") {
+			t.Errorf("Expected no plugin code without --with:\n%s", src)
+		}
+	}
+
+	if _, err := selectPlugins([]string{"weather"}); err == nil || !strings.Contains(err.Error(), "help, ping, remind") {
+		t.Errorf("Expected unknown plugin error listing the plugins, got %v", err)
+	}
+}
+
+func TestRenderConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), bot.DefaultConfigFile)
+	content := renderConfig(MainData{Name: "mybot", Plugins: []PluginImport{{Name: "help"}, {Name: "remind"}}})
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := bot.DefaultConfig()
+	if err := cfg.LoadConfigFile(path); err != nil {
+		t.Fatalf("Invalid daabgo.yaml: %v\n%s", err, content)
+	}
+	if cfg.Name != "mybot" || len(cfg.Plugins) != 2 || cfg.Plugins["remind"]["enabled"] != true {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+
+	if content := renderConfig(MainData{Name: "mybot"}); content != "name: mybot\n" {
+		t.Errorf("Expected no plugins section without --with, got %q", content)
+	}
+}
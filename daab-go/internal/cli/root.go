@@ -15,11 +15,15 @@ var rootCmd = &cobra.Command{
 It allows you to create and run bots for the direct chat service.
 
 Available Commands:
-  init      Setup a new daabgo bot project
-  login     Login to direct as a bot account
-  logout    Logout from the service
-  run       Run the bot
-  version   Show version information`,
+  config          Inspect the bot configuration
+  hooks           Manage incoming webhooks
+  init            Setup a new daabgo bot project
+  login           Login to direct as a bot account
+  logout          Logout from the service
+  run             Run the bot
+  serve-webhooks  Serve incoming webhooks that post to talks
+  version         Show version information
+  webhook         Manage webhook deliveries`,
 }
 
 // Execute runs the root command.
@@ -31,12 +35,16 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(hooksCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(logoutCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(serveWebhooksCmd)
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(stopCmd)
 	rootCmd.AddCommand(invitesCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(webhookCmd)
 }
@@ -15,29 +15,29 @@ var runCmd = &cobra.Command{
 	Short: "Run the daabgo bot",
 	Long:  `Run the bot using the high-level daab-go framework. Press Ctrl+C to stop.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runBot()
+		return runBot(cmd)
 	},
 }
 
-func runBot() error {
-	auth := direct.NewAuth()
+func init() {
+	addConfigFlags(runCmd)
+}
 
-	// Load environment
-	if err := auth.LoadEnv(); err != nil {
-		log.Printf("Warning: could not load .env: %v", err)
+func runBot(cmd *cobra.Command) error {
+	cfg, _, err := loadConfig(cmd)
+	if err != nil {
+		return err
 	}
 
 	// Check if logged in
-	if !auth.HasToken() {
+	if cfg.Token == "" && cfg.TokenEnv == "" && !direct.NewAuth().HasToken() {
 		fmt.Println("Not logged in. Run 'daabgo login' first.")
 		return nil
 	}
 
 	// Create a new bot instance
-	robot := bot.New(
-		bot.WithName("daabgo"),
-	)
-
+	cfg.ApplyDebug()
+	robot := bot.New(cfg.Options()...)
 	// Register a handler that responds when the bot is directly mentioned with "ping"
 	robot.Respond("ping", func(ctx context.Context, res bot.Response) {
 		if err := res.Send("PONG"); err != nil {
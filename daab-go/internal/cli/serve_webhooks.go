@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot/hooks"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/spf13/cobra"
+)
+
+// serveWebhooksFlags holds the flags of the serve-webhooks command.
+var serveWebhooksFlags struct {
+	addr string
+}
+
+var serveWebhooksCmd = &cobra.Command{
+	Use:          "serve-webhooks",
+	Short:        "Serve incoming webhooks that post to talks",
+	SilenceUsage: true,
+	Long: `Run the bot with only the incoming webhook server, which accepts
+POST /hooks/<token> and posts the payload to the talk of the hook.
+
+Create hooks with "daabgo hooks create". The address is --addr, then
+plugins.hooks.addr, then :8080. Press Ctrl+C to stop.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := setPluginFlag(cmd, "file", "hooks", "file"); err != nil {
+			return err
+		}
+		if err := setPluginFlag(cmd, "addr", "hooks", "addr"); err != nil {
+			return err
+		}
+		if cfg.Token == "" && cfg.TokenEnv == "" && !direct.NewAuth().HasToken() {
+			fmt.Println("Not logged in. Run 'daabgo login' first.")
+			return nil
+		}
+
+		cfg.ApplyDebug()
+		robot := bot.New(cfg.Options()...)
+		if err := robot.UsePlugins(hooks.New(hooks.WithAddr(serveWebhooksFlags.addr))); err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := robot.Run(ctx); err != nil {
+			return fmt.Errorf("failed to run bot: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addConfigFlags(serveWebhooksCmd)
+	addHooksFileFlag(serveWebhooksCmd)
+	serveWebhooksCmd.Flags().StringVar(&serveWebhooksFlags.addr, "addr", ":8080", "address to listen on")
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+	"github.com/spf13/cobra"
+)
+
+// webhookFlags holds the flags of the webhook commands.
+var webhookFlags struct {
+	dir    string
+	url    string
+	dryRun bool
+}
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Manage webhook deliveries",
+	Long: `Manage the delivery of events to the webhook (webhook.url).
+
+Events that could not be delivered after retrying are kept in the
+dead-letter directory (webhook.dead_letter_dir) if it is configured.`,
+}
+
+var webhookReplayCmd = &cobra.Command{
+	Use:          "replay",
+	Short:        "Send undelivered events again",
+	SilenceUsage: true,
+	Long: `Send the events in the dead-letter directory to the webhook again.
+
+Events are sent with their original idempotency key, signed with
+webhook.secret, to webhook.url (or --url), or to the URL they were first sent
+to if none is configured. Delivered events are removed from the directory;
+the actions in the responses are not executed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if webhookFlags.dir != "" {
+			cfg.Webhook.DeadLetterDir = webhookFlags.dir
+		}
+		if webhookFlags.url != "" {
+			cfg.Webhook.URL = webhookFlags.url
+		}
+		if cfg.Webhook.DeadLetterDir == "" {
+			return fmt.Errorf("no dead-letter directory: set webhook.dead_letter_dir or --dir")
+		}
+
+		client := webhook.NewClientFromConfig(cfg.Webhook, cfg.Name)
+		queue := client.DeadLetters
+		letters, err := queue.List()
+		if err != nil {
+			return err
+		}
+		if len(letters) == 0 {
+			fmt.Printf("No undelivered events in %s.\n", queue.Dir())
+			return nil
+		}
+
+		if webhookFlags.dryRun {
+			for _, letter := range letters {
+				fmt.Printf("%s  %-20s  %s  (%d attempts) %s\n",
+					letter.ID, letter.EventType, letter.FailedAt.Format("2006-01-02 15:04:05"), letter.Attempts, letter.Error)
+			}
+			fmt.Printf("%d undelivered events\n", len(letters))
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		delivered, err := client.Replay(ctx, queue)
+		fmt.Printf("Delivered %d of %d events.\n", delivered, len(letters))
+		return err
+	},
+}
+
+func init() {
+	webhookCmd.AddCommand(webhookReplayCmd)
+	addConfigFlags(webhookReplayCmd)
+	webhookReplayCmd.Flags().StringVar(&webhookFlags.dir, "dir", "", "dead-letter directory (default webhook.dead_letter_dir)")
+	webhookReplayCmd.Flags().StringVar(&webhookFlags.url, "url", "", "webhook URL (default webhook.url)")
+	webhookReplayCmd.Flags().BoolVar(&webhookFlags.dryRun, "dry-run", false, "list the undelivered events without sending them")
+}
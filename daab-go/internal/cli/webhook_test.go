@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/webhook"
+)
+
+func TestWebhookReplay(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "dlq")
+	queue := webhook.NewDeadLetterQueue(dir)
+	if err := queue.Put(&webhook.DeadLetter{URL: "http://127.0.0.1:1/down", EventType: "message_created", Payload: []byte(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DAABGO_CONFIG", "")
+	t.Setenv("N8N_WEBHOOK_URL", "")
+	t.Setenv("N8N_WEBHOOK_DEAD_LETTER_DIR", dir)
+	t.Cleanup(func() { webhookFlags.url, webhookFlags.dryRun = "", false })
+
+	webhookReplayCmd.Flags().Set("dry-run", "true")
+	if err := webhookReplayCmd.RunE(webhookReplayCmd, nil); err != nil || received.Load() != 0 {
+		t.Fatalf("Expected a dry run not to send, got %d %v", received.Load(), err)
+	}
+
+	webhookReplayCmd.Flags().Set("dry-run", "false")
+	webhookReplayCmd.Flags().Set("url", server.URL)
+	if err := webhookReplayCmd.RunE(webhookReplayCmd, nil); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if letters, _ := queue.List(); received.Load() != 1 || len(letters) != 0 {
+		t.Errorf("Expected the event to be delivered to --url, got %d %v", received.Load(), letters)
+	}
+}
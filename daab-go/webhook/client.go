@@ -2,53 +2,285 @@ package webhook
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
 )
 
+// Defaults of NewClient.
+const (
+	DefaultTimeout = 10 * time.Second
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+
+	// maxBackoff caps the delay between retries.
+	maxBackoff = 30 * time.Second
+)
+
+// IdempotencyKeyHeader carries the idempotency key of a payload, which stays
+// the same when the payload is retried or replayed so that the receiver can
+// drop duplicates.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// StatusError is returned when the webhook responds with a status other than 2xx.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook returned status %d", e.StatusCode)
+}
+
 // Client handles webhook HTTP requests to external services like n8n.
 // It sends message events and receives action responses via HTTP webhooks.
 type Client struct {
 	WebhookURL string
 	HTTPClient *http.Client
 	BotName    string
+
+	// Secret signs requests with SignatureHeader and TimestampHeader if set.
+	Secret []byte
+
+	// Retries is how many times a request failing with a network error or
+	// a 5xx status is retried.
+	Retries int
+
+	// Backoff is the delay before the first retry. It doubles with every
+	// retry, up to 30 seconds.
+	Backoff time.Duration
+
+	// DeadLetters keeps the payloads that could not be delivered, if set.
+	// They can be sent again with Replay.
+	DeadLetters *DeadLetterQueue
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithSecret signs requests with secret; see Sign.
+func WithSecret(secret string) ClientOption {
+	return func(c *Client) {
+		if secret != "" {
+			c.Secret = []byte(secret)
+		}
+	}
+}
+
+// WithTimeout sets the timeout of each request.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient.Timeout = d
+	}
+}
+
+// WithRetries sets how many times failed requests are retried and the
+// delay before the first retry. 0 retries disables retrying.
+func WithRetries(retries int, backoff time.Duration) ClientOption {
+	return func(c *Client) {
+		c.Retries = retries
+		c.Backoff = backoff
+	}
+}
+
+// WithDeadLetterQueue keeps undeliverable payloads in q.
+func WithDeadLetterQueue(q *DeadLetterQueue) ClientOption {
+	return func(c *Client) {
+		c.DeadLetters = q
+	}
 }
 
 // NewClient creates a new webhook client with the specified URL and bot name.
-// The client has a default timeout of 10 seconds for HTTP requests.
-func NewClient(webhookURL, botName string) *Client {
-	return &Client{
+// By default requests time out after 10 seconds, are retried 3 times and
+// are not signed.
+func NewClient(webhookURL, botName string, opts ...ClientOption) *Client {
+	c := &Client{
 		WebhookURL: webhookURL,
 		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: DefaultTimeout,
 		},
 		BotName: botName,
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewClientFromConfig creates a client from the webhook section of a
+// robot configuration.
+func NewClientFromConfig(cfg bot.WebhookConfig, botName string) *Client {
+	opts := []ClientOption{
+		WithSecret(cfg.Secret),
+		WithTimeout(cfg.Timeout),
+		WithRetries(cfg.Retries, cfg.Backoff),
+	}
+	if cfg.DeadLetterDir != "" {
+		opts = append(opts, WithDeadLetterQueue(NewDeadLetterQueue(cfg.DeadLetterDir)))
+	}
+	return NewClient(cfg.URL, botName, opts...)
 }
 
 // Send sends a webhook payload to the configured webhook URL and returns the response.
 // The payload contains message data and bot information.
 // Returns WebhookResponse with action instructions, or an error if the request fails.
 func (c *Client) Send(payload *WebhookPayload) (*WebhookResponse, error) {
+	return c.SendContext(context.Background(), payload)
+}
+
+// SendContext is like Send with a context. Requests failing with a network
+// error or a 5xx status are retried with exponential backoff. If the
+// payload cannot be delivered, it is added to DeadLetters.
+func (c *Client) SendContext(ctx context.Context, payload *WebhookPayload) (*WebhookResponse, error) {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	resp, err := c.HTTPClient.Post(c.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	key := payload.IdempotencyKey()
+	body, attempts, err := c.deliver(ctx, c.WebhookURL, jsonData, key)
+	if err != nil {
+		if c.DeadLetters != nil {
+			letter := &DeadLetter{
+				URL:            c.WebhookURL,
+				IdempotencyKey: key,
+				EventType:      payload.EventType,
+				Payload:        jsonData,
+				Error:          err.Error(),
+				Attempts:       attempts,
+				FailedAt:       time.Now(),
+			}
+			if dlErr := c.DeadLetters.Put(letter); dlErr != nil {
+				log.Printf("Warning: webhook: failed to keep undelivered %s: %v", payload.EventType, dlErr)
+			}
+		}
+		return nil, err
+	}
+	return parseResponse(body)
+}
+
+// Replay sends the payloads in q again, to WebhookURL or, if it is empty,
+// to the URL they were sent to. Delivered payloads are removed from q;
+// the responses are ignored. It returns the number of delivered payloads
+// and the errors of the others, which stay in q.
+func (c *Client) Replay(ctx context.Context, q *DeadLetterQueue) (int, error) {
+	letters, err := q.List()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	var errs []error
+	for _, letter := range letters {
+		url := c.WebhookURL
+		if url == "" {
+			url = letter.URL
+		}
+		_, attempts, err := c.deliver(ctx, url, letter.Payload, letter.IdempotencyKey)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", letter.ID, err))
+			letter.Attempts += attempts
+			letter.Error = err.Error()
+			letter.FailedAt = time.Now()
+			if err := q.Put(letter); err != nil {
+				errs = append(errs, err)
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if err := q.Remove(letter.ID); err != nil {
+			errs = append(errs, err)
+		}
+		delivered++
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// deliver posts body to url, retrying on network errors and 5xx statuses.
+// It returns the response body and the number of attempts made.
+func (c *Client) deliver(ctx context.Context, url string, body []byte, key string) ([]byte, int, error) {
+	backoff := c.Backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := c.post(ctx, url, body, key)
+		if err == nil {
+			return resp, attempt, nil
+		}
+		if attempt > c.Retries || !retryable(err) || ctx.Err() != nil {
+			return nil, attempt, err
+		}
+
+		log.Printf("[DEBUG] webhook: %v, retrying in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil, attempt, err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// post makes a single signed request.
+func (c *Client) post(ctx context.Context, url string, body []byte, key string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if len(c.Secret) > 0 {
+		ts := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+		req.Header.Set(SignatureHeader, Sign(c.Secret, ts, body))
+	}
+
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to post to webhook: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return respBody, nil
+}
+
+// retryable reports whether a failed request may succeed when retried.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// parseResponse decodes the response body of a webhook.
+func parseResponse(body []byte) (*WebhookResponse, error) {
+	// An empty response means there is nothing to do.
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &WebhookResponse{Action: "none"}, nil
 	}
 
 	var webhookResp WebhookResponse
-	if err := json.NewDecoder(resp.Body).Decode(&webhookResp); err != nil {
+	if err := json.Unmarshal(body, &webhookResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -58,7 +290,16 @@ func (c *Client) Send(payload *WebhookPayload) (*WebhookResponse, error) {
 // Validate validates the webhook response fields based on the action type.
 // Returns ErrorCodeOK if valid, or a specific error code indicating what's missing.
 // Supported actions include: reply, send, send_select, send_yesno, send_task, and close actions.
+// A response with a list of actions is valid if every action is.
 func (r *WebhookResponse) Validate() ErrorCode {
+	if len(r.Actions) > 0 {
+		for _, action := range r.List() {
+			if code := action.Validate(); code != ErrorCodeOK {
+				return code
+			}
+		}
+		return ErrorCodeOK
+	}
 	if r.Action == "" {
 		return ErrorCodeMissingAction
 	}
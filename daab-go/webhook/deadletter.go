@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeadLetter is a payload that could not be delivered.
+type DeadLetter struct {
+	ID             string          `json:"id"`
+	URL            string          `json:"url"`
+	IdempotencyKey string          `json:"idempotencyKey,omitempty"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Error          string          `json:"error"`
+	Attempts       int             `json:"attempts"`
+	FailedAt       time.Time       `json:"failedAt"`
+}
+
+// DeadLetterQueue keeps undelivered payloads as JSON files in a directory,
+// one file per payload, so that they survive restarts.
+type DeadLetterQueue struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDeadLetterQueue returns a queue in dir. The directory is created when
+// the first payload is added.
+func NewDeadLetterQueue(dir string) *DeadLetterQueue {
+	return &DeadLetterQueue{dir: dir}
+}
+
+// Dir returns the directory of the queue.
+func (q *DeadLetterQueue) Dir() string {
+	return q.dir
+}
+
+// Put adds letter to the queue, or replaces the letter with the same ID.
+// An ID is assigned if letter has none.
+func (q *DeadLetterQueue) Put(letter *DeadLetter) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if letter.ID == "" {
+		sum := sha256.Sum256([]byte(letter.IdempotencyKey))
+		letter.ID = fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(sum[:4]))
+	}
+	data, err := json.MarshalIndent(letter, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(q.dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash never leaves a
+	// partial letter behind.
+	path := q.path(letter.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// List returns the letters in the queue, in the order they were first added.
+func (q *DeadLetterQueue) List() ([]*DeadLetter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := os.ReadDir(q.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var letters []*DeadLetter
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(q.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var letter DeadLetter
+		if err := json.Unmarshal(data, &letter); err != nil {
+			return nil, fmt.Errorf("webhook: invalid dead letter %s: %w", entry.Name(), err)
+		}
+		letters = append(letters, &letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].ID < letters[j].ID
+	})
+	return letters, nil
+}
+
+// Remove removes the letter with the given ID.
+func (q *DeadLetterQueue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return os.Remove(q.path(id))
+}
+
+// path returns the file of the letter with the given ID.
+func (q *DeadLetterQueue) path(id string) string {
+	return filepath.Join(q.dir, filepath.Base(id)+".json")
+}
@@ -2,7 +2,14 @@
 // Direct4B events to external workflow engines such as n8n via HTTP webhooks.
 // It pairs incoming chat data with bot metadata, posts it to a configured
 // endpoint using Client.Send, and parses structured actions (reply, send,
-// send_select, etc.) back from the workflow in WebhookResponse. Helper types
-// like WebhookPayload and MessageTypeToName keep payloads consistent with the
-// rest of daab-go while remaining framework-agnostic for custom integrations.
+// send_select, etc.) back from the workflow in WebhookResponse. Requests can
+// be signed, failed requests are retried, and payloads that still cannot be
+// delivered are kept in a DeadLetterQueue to be sent again with Replay.
+// Executor performs the actions through a bot.Robot, one or a list per
+// response, and returns an ActionResult for each. Forwarder is a bot.Plugin
+// that forwards notify_* notifications, such as talkers being added or notes
+// created, as typed event payloads with a versioned schema. Enricher adds the
+// sender profile, talk and domain to payloads, field by field. Helper types like
+// WebhookPayload and MessageTypeToName keep payloads consistent with the rest
+// of daab-go while remaining framework-agnostic for custom integrations.
 package webhook
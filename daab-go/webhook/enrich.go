@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// Fields added to payloads by an Enricher. User fields contain personal
+// information and are only sent when selected.
+const (
+	FieldUserName        = "user.name"
+	FieldUserDisplayName = "user.displayName"
+	FieldUserEmail       = "user.email"
+	FieldTalkName        = "talk.name"
+	FieldTalkType        = "talk.type"
+	FieldTalkMemberCount = "talk.memberCount"
+	FieldDomainName      = "domain.name"
+)
+
+// DefaultEnrichFields are the fields added when none are selected: the
+// sender's display name and everything but personal information.
+var DefaultEnrichFields = []string{
+	FieldUserDisplayName,
+	FieldTalkName,
+	FieldTalkType,
+	FieldTalkMemberCount,
+	FieldDomainName,
+}
+
+var enrichFields = map[string]bool{
+	FieldUserName:        true,
+	FieldUserDisplayName: true,
+	FieldUserEmail:       true,
+	FieldTalkName:        true,
+	FieldTalkType:        true,
+	FieldTalkMemberCount: true,
+	FieldDomainName:      true,
+}
+
+// Enricher adds the sender profile, talk and domain to payloads before they
+// are sent. Lookups go through the robot's Directory, so they are cached.
+type Enricher struct {
+	robot  *bot.Robot
+	fields map[string]bool
+}
+
+// NewEnricher returns an enricher adding the selected fields, or
+// DefaultEnrichFields if none are given. Unknown fields are an error.
+func NewEnricher(r *bot.Robot, fields ...string) (*Enricher, error) {
+	if len(fields) == 0 {
+		fields = DefaultEnrichFields
+	}
+	e := &Enricher{robot: r, fields: make(map[string]bool)}
+	for _, field := range fields {
+		if !enrichFields[field] {
+			return nil, fmt.Errorf("webhook: unknown enrichment field %q", field)
+		}
+		e.fields[field] = true
+	}
+	return e, nil
+}
+
+// Fields returns the selected fields.
+func (e *Enricher) Fields() []string {
+	var fields []string
+	for field := range enrichFields {
+		if e.fields[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Enrich sets payload.Talk and payload.Domain, and the sender of message
+// payloads in payload.Message.User or the user of typed events, such as the
+// author of a note, in payload.User. Each lookup is independent: one that
+// fails is logged and only its information is left out, and the payload can
+// be sent anyway.
+func (e *Enricher) Enrich(ctx context.Context, payload *WebhookPayload) {
+	talkID := EventTalkID(payload.Event)
+	userID := EventUserID(payload.Event)
+	domainID := eventDomainID(payload.Event)
+	if payload.Message != nil {
+		talkID, userID = payload.Message.TalkID, payload.Message.UserID
+	}
+
+	if talkID != "" {
+		if talk, err := e.robot.LookupTalk(ctx, talkID); err != nil {
+			log.Printf("[DEBUG] webhook: looking up talk %s: %v", talkID, err)
+		} else {
+			payload.Talk = e.talkData(talkID, talk)
+			if talk.DomainID != nil {
+				domainID = fmt.Sprint(talk.DomainID)
+			}
+		}
+	}
+
+	if domainID != "" {
+		payload.Domain = &DomainData{ID: domainID}
+		if e.fields[FieldDomainName] {
+			if domain, err := e.robot.LookupDomain(ctx, domainID); err != nil {
+				log.Printf("[DEBUG] webhook: looking up domain %s: %v", domainID, err)
+			} else {
+				payload.Domain.Name = domain.Name
+			}
+		}
+	}
+
+	if userID != "" && e.userFields() {
+		user, err := e.robot.LookupUser(ctx, domainID, userID)
+		if err != nil {
+			log.Printf("[DEBUG] webhook: looking up user %s: %v", userID, err)
+			return
+		}
+		if payload.Message != nil {
+			payload.Message.User = e.userData(userID, user)
+		} else {
+			payload.User = e.userData(userID, user)
+		}
+	}
+}
+
+// talkData returns the selected fields of talk.
+func (e *Enricher) talkData(talkID string, talk *direct.Talk) *TalkData {
+	data := &TalkData{ID: talkID}
+	if e.fields[FieldTalkName] {
+		data.Name = talk.Name
+	}
+	if e.fields[FieldTalkType] {
+		switch direct.RoomType(talk.Type) {
+		case direct.RoomTypePair:
+			data.Type = "pair"
+		case direct.RoomTypeGroup:
+			data.Type = "group"
+		}
+	}
+	if e.fields[FieldTalkMemberCount] {
+		data.MemberCount = len(talk.UserIDs)
+	}
+	return data
+}
+
+// userFields reports whether any user field is selected.
+func (e *Enricher) userFields() bool {
+	return e.fields[FieldUserName] || e.fields[FieldUserDisplayName] || e.fields[FieldUserEmail]
+}
+
+// userData returns the selected fields of user.
+func (e *Enricher) userData(userID string, user *direct.UserInfo) *UserData {
+	data := &UserData{ID: userID}
+	if e.fields[FieldUserName] {
+		data.Name = user.Name
+	}
+	if e.fields[FieldUserDisplayName] {
+		data.DisplayName = user.DisplayName
+	}
+	if e.fields[FieldUserEmail] {
+		data.Email = user.Email
+	}
+	return data
+}
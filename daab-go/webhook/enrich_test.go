@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+func TestEnricher(t *testing.T) {
+	robot, mockServer := runRobot(t)
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{"talk_id": uint64(100), "domain_id": uint64(1), "type": int8(2), "name": "開発", "user_ids": []interface{}{uint64(42), uint64(43)}},
+	})
+	mockServer.OnSimple("get_domains", []interface{}{
+		map[string]interface{}{"id": uint64(1), "name": "Example Inc."},
+	})
+	mockServer.OnSimple("get_users", []interface{}{
+		map[string]interface{}{"id": uint64(42), "display_name": "Alice", "name": "Alice Smith", "email": "alice@example.com"},
+	})
+
+	enricher, err := NewEnricher(robot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := NewPayload("message_created", "testbot", MessageData{ID: "1", TalkID: "100", UserID: "42"})
+	enricher.Enrich(context.Background(), payload)
+
+	if talk := payload.Talk; talk == nil || talk.ID != "100" || talk.Name != "開発" || talk.Type != "group" || talk.MemberCount != 2 {
+		t.Errorf("Unexpected talk %+v", payload.Talk)
+	}
+	if domain := payload.Domain; domain == nil || domain.ID != "1" || domain.Name != "Example Inc." {
+		t.Errorf("Unexpected domain %+v", payload.Domain)
+	}
+	if user := payload.Message.User; user == nil || user.DisplayName != "Alice" || user.Name != "" || user.Email != "" {
+		t.Errorf("Expected only the display name by default, got %+v", user)
+	}
+
+	// Fields that are not selected are left out.
+	enricher, _ = NewEnricher(robot, FieldUserEmail, FieldTalkType)
+	payload = NewEventPayload("testbot", "notify_add_talkers", map[string]interface{}{"talk_id": uint64(100)})
+	enricher.Enrich(context.Background(), payload)
+	data, _ := json.Marshal(payload)
+	if !strings.Contains(string(data), `"talk":{"id":"100","type":"group"}`) || !strings.Contains(string(data), `"domain":{"id":"1"}`) {
+		t.Errorf("Unexpected payload %s", data)
+	}
+
+	payload = NewPayload("message_created", "testbot", MessageData{ID: "2", TalkID: "100", UserID: "42"})
+	enricher.Enrich(context.Background(), payload)
+	if user := payload.Message.User; user == nil || user.Email != "alice@example.com" || user.DisplayName != "" {
+		t.Errorf("Expected only the email, got %+v", user)
+	}
+	if n := mockServer.GetCallCount("get_talks") + mockServer.GetCallCount("get_domains") + mockServer.GetCallCount("get_users"); n != 3 {
+		t.Errorf("Expected lookups to be cached, got %d calls", n)
+	}
+
+	// The user of typed events is resolved too.
+	payload = NewEventPayload("testbot", direct.EventNotifyUpdateMessageReaction, map[string]interface{}{
+		"message_id": uint64(7), "talk_id": uint64(100), "user_id": uint64(42),
+	})
+	enricher.Enrich(context.Background(), payload)
+	if user := payload.User; user == nil || user.ID != "42" || user.Email != "alice@example.com" {
+		t.Errorf("Expected the user who reacted, got %+v", user)
+	}
+	payload = NewEventPayload("testbot", direct.EventNotifyCreateAnnouncement, map[string]interface{}{
+		"announcement_id": uint64(5), "domain_id": uint64(1), "user_id": uint64(42),
+	})
+	enricher.Enrich(context.Background(), payload)
+	if payload.Talk != nil || payload.Domain == nil || payload.Domain.ID != "1" || payload.User == nil {
+		t.Errorf("Expected the domain and user of an announcement, got %+v", payload)
+	}
+
+	// Unknown talks are left out, but the sender is still resolved.
+	payload = NewPayload("message_created", "testbot", MessageData{ID: "3", TalkID: "999", UserID: "42"})
+	enricher.Enrich(context.Background(), payload)
+	if payload.Talk != nil || payload.Domain != nil || payload.Message.User == nil || payload.Message.User.ID != "42" {
+		t.Errorf("Expected only the talk to be left out for an unknown talk, got %+v", payload)
+	}
+
+	// A failed talk lookup keeps the domain of the event.
+	payload = NewEventPayload("testbot", direct.EventNotifyUpdateTalk, map[string]interface{}{
+		"talk_id": uint64(999), "domain_id": uint64(1),
+	})
+	enricher.Enrich(context.Background(), payload)
+	if payload.Talk != nil || payload.Domain == nil || payload.Domain.ID != "1" {
+		t.Errorf("Expected the domain despite the unknown talk, got %+v", payload)
+	}
+
+	if _, err := NewEnricher(robot, "user.password"); err == nil {
+		t.Error("Expected an unknown field to be rejected")
+	}
+}
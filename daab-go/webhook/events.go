@@ -0,0 +1,301 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+// SchemaVersion is the version of the payload schema, sent as
+// WebhookPayload.Version. It changes when a field is renamed or removed;
+// new fields and event types may be added without changing it.
+const SchemaVersion = "1.0"
+
+// Event types of forwarded notifications, sent as WebhookPayload.EventType.
+// Notifications without a type of their own are sent with their name
+// without the "notify_" prefix and no typed event.
+const (
+	EventTalkCreated         = "talk_created"
+	EventTalkUpdated         = "talk_updated"
+	EventTalkersAdded        = "talkers_added"
+	EventTalkerDeleted       = "talker_deleted"
+	EventReadStatusUpdated   = "read_status_updated"
+	EventAnnouncementCreated = "announcement_created"
+	EventAnnouncementDeleted = "announcement_deleted"
+	EventNoteCreated         = "note_created"
+	EventNoteUpdated         = "note_updated"
+	EventNoteDeleted         = "note_deleted"
+	EventReactionUpdated     = "reaction_updated"
+	EventMessageDeleted      = "message_deleted"
+)
+
+// notifyEvents maps notifications to their event type.
+var notifyEvents = map[string]string{
+	direct.EventNotifyCreateGroupTalk:       EventTalkCreated,
+	direct.EventNotifyCreatePairTalk:        EventTalkCreated,
+	direct.EventNotifyUpdateTalk:            EventTalkUpdated,
+	direct.EventNotifyAddTalkers:            EventTalkersAdded,
+	direct.EventNotifyDeleteTalker:          EventTalkerDeleted,
+	direct.EventNotifyUpdateReadStatus:      EventReadStatusUpdated,
+	direct.EventNotifyCreateAnnouncement:    EventAnnouncementCreated,
+	direct.EventNotifyDeleteAnnouncement:    EventAnnouncementDeleted,
+	direct.EventNotifyCreateNote:            EventNoteCreated,
+	direct.EventNotifyUpdateNote:            EventNoteUpdated,
+	direct.EventNotifyDeleteNote:            EventNoteDeleted,
+	direct.EventNotifyUpdateMessageReaction: EventReactionUpdated,
+	direct.EventNotifyDeleteMessage:         EventMessageDeleted,
+}
+
+// TalkEvent is the event of talk_created and talk_updated.
+type TalkEvent struct {
+	TalkID   string   `json:"talkId"`
+	DomainID string   `json:"domainId,omitempty"`
+	Type     int      `json:"type"`
+	Name     string   `json:"name,omitempty"`
+	UserIDs  []string `json:"userIds,omitempty"`
+}
+
+// TalkersEvent is the event of talkers_added and talker_deleted.
+type TalkersEvent struct {
+	TalkID   string   `json:"talkId"`
+	DomainID string   `json:"domainId,omitempty"`
+	UserIDs  []string `json:"userIds"`
+}
+
+// ReadStatusEvent is the event of read_status_updated.
+type ReadStatusEvent struct {
+	TalkID    string `json:"talkId"`
+	UserID    string `json:"userId,omitempty"`
+	MessageID string `json:"messageId,omitempty"`
+}
+
+// AnnouncementEvent is the event of announcement_created and announcement_deleted.
+type AnnouncementEvent struct {
+	ID       string `json:"id"`
+	DomainID string `json:"domainId,omitempty"`
+	UserID   string `json:"userId,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// NoteEvent is the event of note_created, note_updated and note_deleted.
+type NoteEvent struct {
+	ID     string `json:"id"`
+	TalkID string `json:"talkId,omitempty"`
+	UserID string `json:"userId,omitempty"`
+	Title  string `json:"title,omitempty"`
+}
+
+// ReactionEvent is the event of reaction_updated.
+type ReactionEvent struct {
+	MessageID string `json:"messageId"`
+	TalkID    string `json:"talkId,omitempty"`
+	UserID    string `json:"userId,omitempty"`
+	Reaction  string `json:"reaction,omitempty"`
+}
+
+// MessageDeletedEvent is the event of message_deleted.
+type MessageDeletedEvent struct {
+	MessageID string `json:"messageId"`
+	TalkID    string `json:"talkId,omitempty"`
+}
+
+// EventType returns the event type of the named notification.
+func EventType(notify string) string {
+	if eventType, ok := notifyEvents[notify]; ok {
+		return eventType
+	}
+	return strings.TrimPrefix(notify, "notify_")
+}
+
+// ParseEvent returns the event type and the typed event of a notification.
+// The event is nil for notifications without a typed event. Fields are read
+// leniently, so missing fields are left empty.
+func ParseEvent(notify string, data interface{}) (string, interface{}) {
+	eventType := EventType(notify)
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return eventType, nil
+	}
+	// Some notifications wrap the talk they are about.
+	talk := m
+	if t, ok := m["talk"].(map[string]interface{}); ok {
+		talk = t
+	}
+
+	switch eventType {
+	case EventTalkCreated, EventTalkUpdated:
+		return eventType, &TalkEvent{
+			TalkID:   field(talk, "talk_id", "id"),
+			DomainID: field(talk, "domain_id"),
+			Type:     int(intField(talk, "type")),
+			Name:     field(talk, "name"),
+			UserIDs:  ids(talk, "user_ids"),
+		}
+	case EventTalkersAdded, EventTalkerDeleted:
+		userIDs := ids(m, "user_ids", "talker_ids")
+		if userIDs == nil {
+			if id := field(m, "user_id", "talker_id"); id != "" {
+				userIDs = []string{id}
+			}
+		}
+		return eventType, &TalkersEvent{
+			TalkID:   field(talk, "talk_id", "id"),
+			DomainID: field(talk, "domain_id"),
+			UserIDs:  userIDs,
+		}
+	case EventReadStatusUpdated:
+		return eventType, &ReadStatusEvent{
+			TalkID:    field(m, "talk_id"),
+			UserID:    field(m, "user_id"),
+			MessageID: field(m, "message_id", "latest_msg_id"),
+		}
+	case EventAnnouncementCreated, EventAnnouncementDeleted:
+		return eventType, &AnnouncementEvent{
+			ID:       field(m, "announcement_id", "id"),
+			DomainID: field(m, "domain_id"),
+			UserID:   field(m, "user_id"),
+			Title:    field(m, "title"),
+			Text:     field(m, "text"),
+		}
+	case EventNoteCreated, EventNoteUpdated, EventNoteDeleted:
+		return eventType, &NoteEvent{
+			ID:     field(m, "note_id", "id"),
+			TalkID: field(m, "talk_id"),
+			UserID: field(m, "user_id"),
+			Title:  field(m, "title"),
+		}
+	case EventReactionUpdated:
+		return eventType, &ReactionEvent{
+			MessageID: field(m, "message_id"),
+			TalkID:    field(m, "talk_id"),
+			UserID:    field(m, "user_id"),
+			Reaction:  field(m, "reaction", "reaction_id"),
+		}
+	case EventMessageDeleted:
+		return eventType, &MessageDeletedEvent{
+			MessageID: field(m, "message_id", "id"),
+			TalkID:    field(m, "talk_id"),
+		}
+	}
+	return eventType, nil
+}
+
+// EventTalkID returns the talk a typed event is about, or "".
+func EventTalkID(event interface{}) string {
+	switch e := event.(type) {
+	case *TalkEvent:
+		return e.TalkID
+	case *TalkersEvent:
+		return e.TalkID
+	case *ReadStatusEvent:
+		return e.TalkID
+	case *NoteEvent:
+		return e.TalkID
+	case *ReactionEvent:
+		return e.TalkID
+	case *MessageDeletedEvent:
+		return e.TalkID
+	}
+	return ""
+}
+
+// EventUserID returns the user who caused a typed event, such as the author
+// of a note or the user who reacted, or "".
+func EventUserID(event interface{}) string {
+	switch e := event.(type) {
+	case *ReadStatusEvent:
+		return e.UserID
+	case *AnnouncementEvent:
+		return e.UserID
+	case *NoteEvent:
+		return e.UserID
+	case *ReactionEvent:
+		return e.UserID
+	}
+	return ""
+}
+
+// eventDomainID returns the domain of a typed event, if the event has one.
+func eventDomainID(event interface{}) string {
+	switch e := event.(type) {
+	case *TalkEvent:
+		return e.DomainID
+	case *TalkersEvent:
+		return e.DomainID
+	case *AnnouncementEvent:
+		return e.DomainID
+	}
+	return ""
+}
+
+// NewEventPayload creates a payload for a notification with its typed event
+// in Event. The notification data as received is not included, as it may
+// contain personal information; see WithRaw.
+func NewEventPayload(botName, notify string, data interface{}) *WebhookPayload {
+	eventType, event := ParseEvent(notify, data)
+	return &WebhookPayload{
+		Version:   SchemaVersion,
+		EventType: eventType,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Bot: BotInfo{
+			Name: botName,
+		},
+		Event: event,
+	}
+}
+
+// field returns the first of keys present in m, formatted as a string.
+func field(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := m[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+// intField returns m[key] as an integer, or 0.
+func intField(m map[string]interface{}, key string) int64 {
+	switch v := m[key].(type) {
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// ids returns the first of keys present in m as a list of strings.
+func ids(m map[string]interface{}, keys ...string) []string {
+	for _, key := range keys {
+		list, ok := m[key].([]interface{})
+		if !ok {
+			continue
+		}
+		out := make([]string, len(list))
+		for i, v := range list {
+			out[i] = fmt.Sprint(v)
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// Executor performs the actions of webhook responses through a robot.
+type Executor struct {
+	robot *bot.Robot
+}
+
+// NewExecutor returns an executor sending through r.
+func NewExecutor(r *bot.Robot) *Executor {
+	return &Executor{robot: r}
+}
+
+// Execute performs the actions of resp in order and returns one result per
+// action. talkID is the talk of the message the webhook was called for; it
+// is used by "reply", "reply_*" and "close_*" actions without a roomId.
+// An invalid or failing action is reported in its result and the remaining
+// actions still run.
+func (e *Executor) Execute(ctx context.Context, talkID string, resp *WebhookResponse) []ActionResult {
+	actions := resp.List()
+	results := make([]ActionResult, 0, len(actions))
+	for _, action := range actions {
+		results = append(results, e.Do(ctx, talkID, action))
+	}
+	return results
+}
+
+// Do performs a single action; see Execute.
+func (e *Executor) Do(ctx context.Context, talkID string, action WebhookResponse) ActionResult {
+	result := ActionResult{ID: action.ID, Action: action.Action}
+	if code := action.Validate(); code != ErrorCodeOK {
+		result.ErrorCode = code
+		result.Error = "invalid " + action.Action + " action: " + string(code)
+		return result
+	}
+	if err := ctx.Err(); err != nil {
+		result.ErrorCode = ErrorCodeSendFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	roomID := action.RoomID
+	if roomID == "" {
+		roomID = talkID
+	}
+	if roomID == "" && action.Action != "none" {
+		result.ErrorCode = ErrorCodeMissingRoomID
+		result.Error = "no room for " + action.Action + " action"
+		return result
+	}
+
+	var messageID string
+	var err error
+	switch action.Action {
+	case "none":
+	case "reply", "send":
+		messageID, err = e.robot.SendTextAsync(roomID, action.Text).Wait(ctx)
+	case "send_select":
+		messageID, err = e.robot.SendSelectContext(ctx, roomID, action.Question, action.Options)
+	case "send_yesno":
+		messageID, err = e.robot.SendYesNoContext(ctx, roomID, action.Question)
+	case "send_task":
+		messageID, err = e.robot.SendTaskContext(ctx, roomID, action.Title)
+	case "reply_select":
+		messageID, err = e.robot.ReplySelectContext(ctx, roomID, action.InReplyTo, *action.Response)
+	case "reply_yesno":
+		messageID, err = e.robot.ReplyYesNoContext(ctx, roomID, action.InReplyTo, *action.ResponseBool)
+	case "reply_task":
+		messageID, err = e.robot.ReplyTaskContext(ctx, roomID, action.InReplyTo, *action.Done)
+	case "close_select":
+		messageID, err = e.robot.CloseSelectContext(ctx, roomID, action.MessageID)
+	case "close_yesno":
+		messageID, err = e.robot.CloseYesNoContext(ctx, roomID, action.MessageID)
+	}
+	if err != nil {
+		log.Printf("[DEBUG] webhook: %s action failed: %v", action.Action, err)
+		result.ErrorCode = ErrorCodeSendFailed
+		if errors.Is(err, bot.ErrNotConnected) {
+			result.ErrorCode = ErrorCodeNotConnected
+		}
+		result.Error = err.Error()
+		return result
+	}
+
+	result.OK = true
+	result.MessageID = messageID
+	return result
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+// readyPlugin signals that the robot is connected.
+type readyPlugin struct {
+	started chan struct{}
+}
+
+func (p *readyPlugin) Name() string                    { return "ready" }
+func (p *readyPlugin) Register(r *bot.Robot) error     { return nil }
+func (p *readyPlugin) Start(ctx context.Context) error { close(p.started); return nil }
+
+// runRobot runs a robot with plugins connected to a mock server until the
+// test ends.
+func runRobot(t *testing.T, plugins ...bot.Plugin) (*bot.Robot, *testutil.MockServer) {
+	t.Helper()
+
+	mockServer := testutil.NewMockServer()
+	t.Cleanup(mockServer.Close)
+	var n atomic.Int32
+	mockServer.On("create_message", func(params []interface{}) (interface{}, error) {
+		return map[string]interface{}{"message_id": fmt.Sprint("m", n.Add(1))}, nil
+	})
+
+	robot := bot.New(
+		bot.WithToken("token"),
+		bot.WithEndpoint(mockServer.URL()),
+		bot.WithGlobalRateLimit(0, 0),
+		bot.WithTalkRateLimit(0, 0),
+	)
+	ready := &readyPlugin{started: make(chan struct{})}
+	if err := robot.UsePlugins(append(plugins, ready)...); err != nil {
+		t.Fatalf("UsePlugins failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- robot.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	select {
+	case <-ready.started:
+	case err := <-done:
+		t.Fatalf("Run failed: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Robot did not start")
+	}
+	return robot, mockServer
+}
+
+func TestResponseActions(t *testing.T) {
+	var single, list, array WebhookResponse
+	if err := json.Unmarshal([]byte(`{"action": "reply", "text": "hi"}`), &single); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"action": "reply", "text": "hi", "actions": [{"action": "none"}]}`), &list); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(` [{"action": "none"}, {"action": "send"}]`), &array); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := single.List(); len(got) != 1 || got[0].Text != "hi" {
+		t.Errorf("Unexpected single action list %+v", got)
+	}
+	if got := list.List(); len(got) != 2 || got[0].Action != "reply" || got[1].Action != "none" {
+		t.Errorf("Unexpected action list %+v", got)
+	}
+	if got := array.List(); len(got) != 2 || got[1].Action != "send" {
+		t.Errorf("Unexpected array action list %+v", got)
+	}
+
+	if code := list.Validate(); code != ErrorCodeOK {
+		t.Errorf("Expected valid list, got %s", code)
+	}
+	if code := array.Validate(); code != ErrorCodeMissingRoomID {
+		t.Errorf("Expected the invalid action to be reported, got %s", code)
+	}
+}
+
+func TestExecutor(t *testing.T) {
+	robot, mockServer := runRobot(t)
+	executor := NewExecutor(robot)
+
+	index, yes, done := 1, true, false
+	resp := &WebhookResponse{Actions: []WebhookResponse{
+		{ID: "a", Action: "reply", Text: "了解しました"},
+		{ID: "b", Action: "send", RoomID: "200", Text: "通知"},
+		{ID: "c", Action: "send_select", RoomID: "100", Question: "どれ?", Options: []string{"A", "B"}},
+		{ID: "d", Action: "send_yesno", RoomID: "100", Question: "OK?"},
+		{ID: "e", Action: "send_task", RoomID: "100", Title: "レビュー"},
+		{ID: "f", Action: "reply_select", InReplyTo: "41", Response: &index},
+		{ID: "g", Action: "reply_yesno", InReplyTo: "42", ResponseBool: &yes},
+		{ID: "h", Action: "reply_task", InReplyTo: "43", Done: &done},
+		{ID: "i", Action: "close_select", MessageID: "41"},
+		{ID: "j", Action: "close_yesno", MessageID: "42"},
+		{ID: "k", Action: "send_task", RoomID: "100"},
+		{ID: "l", Action: "none"},
+	}}
+
+	results := executor.Execute(context.Background(), "100", resp)
+	if len(results) != 12 {
+		t.Fatalf("Expected 12 results, got %d", len(results))
+	}
+	for i, result := range results {
+		want := string(rune('a' + i))
+		if result.ID != want || result.Action != resp.Actions[i].Action {
+			t.Errorf("Result %d: expected action %s, got %+v", i, want, result)
+		}
+	}
+	for _, result := range results[:10] {
+		if !result.OK || result.MessageID == "" {
+			t.Errorf("Expected %s to succeed, got %+v", result.Action, result)
+		}
+	}
+	if r := results[10]; r.OK || r.ErrorCode != ErrorCodeMissingTitle || r.Error == "" {
+		t.Errorf("Expected the invalid action to fail, got %+v", r)
+	}
+	if r := results[11]; !r.OK || r.MessageID != "" {
+		t.Errorf("Expected none to succeed without a message, got %+v", r)
+	}
+
+	var rooms, types []string
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if msg[2] == "create_message" {
+			params := msg[3].([]interface{})
+			rooms = append(rooms, fmt.Sprint(params[0]))
+			types = append(types, fmt.Sprint(params[1]))
+		}
+	}
+	if fmt.Sprint(rooms) != "[100 200 100 100 100 100 100 100 100 100]" {
+		t.Errorf("Unexpected rooms %v", rooms)
+	}
+	if fmt.Sprint(types) != "[1 1 502 500 504 503 501 505 507 506]" {
+		t.Errorf("Unexpected message types %v", types)
+	}
+}
+
+func TestExecutorNotConnected(t *testing.T) {
+	results := NewExecutor(bot.New()).Execute(context.Background(), "100", &WebhookResponse{Action: "reply", Text: "hi"})
+	if len(results) != 1 || results[0].OK || results[0].ErrorCode != ErrorCodeNotConnected {
+		t.Errorf("Expected not_connected, got %+v", results)
+	}
+
+	payload := NewResultsPayload("testbot", results)
+	data, _ := json.Marshal(payload)
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	if decoded["eventType"] != "action_results" || decoded["message"] != nil {
+		t.Errorf("Unexpected results payload %s", data)
+	}
+}
@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+)
+
+// DefaultEvents are the event types forwarded when none are configured.
+var DefaultEvents = []string{
+	EventTalkCreated,
+	EventTalkUpdated,
+	EventTalkersAdded,
+	EventTalkerDeleted,
+	EventReadStatusUpdated,
+	EventAnnouncementCreated,
+	EventAnnouncementDeleted,
+	EventNoteCreated,
+	EventNoteUpdated,
+	EventNoteDeleted,
+	EventReactionUpdated,
+	EventMessageDeleted,
+}
+
+// Forwarder is a bot.Plugin named "webhook" that forwards server
+// notifications, such as talks being created or talkers added, to a
+// webhook as event payloads (see NewEventPayload).
+type Forwarder struct {
+	client   *Client
+	events   []string
+	actions  bool
+	raw      bool
+	enrich   bool
+	fields   []string
+	enricher *Enricher
+	robot    *bot.Robot
+}
+
+// ForwarderOption configures a Forwarder.
+type ForwarderOption func(*Forwarder)
+
+// WithEvents selects the forwarded events, by event type such as
+// "talkers_added" or by notification name such as "notify_add_talkers".
+// Notification names without an event type of their own can be used to
+// forward any other notification. "*" selects DefaultEvents.
+func WithEvents(events ...string) ForwarderOption {
+	return func(f *Forwarder) {
+		f.events = events
+	}
+}
+
+// WithActions executes the actions returned by the webhook for an event
+// with an Executor. Replies go to the talk the event is about. If the
+// response sets reportResults, the results are posted back as an
+// "action_results" payload (see NewResultsPayload).
+func WithActions() ForwarderOption {
+	return func(f *Forwarder) {
+		f.actions = true
+	}
+}
+
+// WithRaw includes the notification data as received in the payloads'
+// raw field. It carries every field of the notification, including
+// personal information, whatever enrichment fields are selected.
+func WithRaw() ForwarderOption {
+	return func(f *Forwarder) {
+		f.raw = true
+	}
+}
+
+// WithEnrichment adds the talk, domain and sender profile to the payloads
+// with an Enricher, limited to fields (DefaultEnrichFields if none).
+func WithEnrichment(fields ...string) ForwarderOption {
+	return func(f *Forwarder) {
+		f.enrich = true
+		f.fields = fields
+	}
+}
+
+// NewForwarder returns a forwarder posting to client. client may be nil if
+// the "url" setting is configured.
+func NewForwarder(client *Client, opts ...ForwarderOption) *Forwarder {
+	f := &Forwarder{client: client}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Name returns "webhook".
+func (f *Forwarder) Name() string { return "webhook" }
+
+// Description describes the plugin.
+func (f *Forwarder) Description() string {
+	return "トークやノートなどのイベントを Webhook に転送します"
+}
+
+// Configure reads the "url", "events" (comma separated), "actions", "raw"
+// and "enrich" settings. "enrich" is true for DefaultEnrichFields, false, or a
+// comma separated list of fields. Settings override options.
+func (f *Forwarder) Configure(cfg bot.PluginConfig) error {
+	if url, ok := cfg.Lookup("url"); ok {
+		if f.client == nil {
+			f.client = NewClient(url, "")
+		} else {
+			f.client.WebhookURL = url
+		}
+	}
+	f.events = cfg.Strings("events", f.events)
+	f.actions = cfg.Bool("actions", f.actions)
+	f.raw = cfg.Bool("raw", f.raw)
+	if enrich, ok := cfg.Lookup("enrich"); ok {
+		switch enrich {
+		case "true":
+			f.enrich, f.fields = true, nil
+		case "false", "":
+			f.enrich, f.fields = false, nil
+		default:
+			f.enrich, f.fields = true, cfg.Strings("enrich", nil)
+		}
+	}
+	return nil
+}
+
+// Register subscribes to the selected notifications.
+func (f *Forwarder) Register(r *bot.Robot) error {
+	if f.client == nil || f.client.WebhookURL == "" {
+		return errors.New("webhook: no webhook URL")
+	}
+	if f.client.BotName == "" {
+		f.client.BotName = r.Name
+	}
+	notifications, err := resolveEvents(f.events)
+	if err != nil {
+		return err
+	}
+	if f.enrich {
+		if f.enricher, err = NewEnricher(r, f.fields...); err != nil {
+			return err
+		}
+	}
+	f.robot = r
+	for _, notify := range notifications {
+		r.OnNotify(notify, f.forward)
+	}
+	return nil
+}
+
+// Notifications returns the notification names the forwarder subscribes to.
+func (f *Forwarder) Notifications() ([]string, error) {
+	return resolveEvents(f.events)
+}
+
+// forward posts a notification and runs the returned actions.
+func (f *Forwarder) forward(ctx context.Context, notify string, data interface{}) {
+	payload := NewEventPayload(f.client.BotName, notify, data)
+	if f.raw {
+		payload.Raw = data
+	}
+	if f.enricher != nil {
+		f.enricher.Enrich(ctx, payload)
+	}
+	resp, err := f.client.SendContext(ctx, payload)
+	if err != nil {
+		log.Printf("Warning: webhook: forwarding %s: %v", payload.EventType, err)
+		return
+	}
+	if !f.actions {
+		return
+	}
+	results := NewExecutor(f.robot).Execute(ctx, EventTalkID(payload.Event), resp)
+	for _, result := range results {
+		if !result.OK {
+			log.Printf("Warning: webhook: %s action for %s failed: %s (%s)", result.Action, payload.EventType, result.ErrorCode, result.Error)
+		}
+	}
+	if resp.ReportResults {
+		if _, err := f.client.SendContext(ctx, NewResultsPayload(f.client.BotName, results)); err != nil {
+			log.Printf("Warning: webhook: reporting action results for %s: %v", payload.EventType, err)
+		}
+	}
+}
+
+// resolveEvents returns the notification names selected by events.
+func resolveEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	selected := make(map[string]bool)
+	for _, event := range events {
+		switch {
+		case event == "*":
+			for _, e := range DefaultEvents {
+				for notify, eventType := range notifyEvents {
+					if eventType == e {
+						selected[notify] = true
+					}
+				}
+			}
+		case strings.HasPrefix(event, "notify_"):
+			selected[event] = true
+		default:
+			found := false
+			for notify, eventType := range notifyEvents {
+				if eventType == event {
+					selected[notify] = true
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("webhook: unknown event type %q", event)
+			}
+		}
+	}
+	notifications := make([]string, 0, len(selected))
+	for notify := range selected {
+		notifications = append(notifications, notify)
+	}
+	sort.Strings(notifications)
+	return notifications, nil
+}
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/daab-go/bot"
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+)
+
+func TestParseEvent(t *testing.T) {
+	eventType, event := ParseEvent(direct.EventNotifyCreateGroupTalk, map[string]interface{}{
+		"talk_id":   uint64(100),
+		"domain_id": uint64(1),
+		"type":      int8(2),
+		"name":      "開発",
+		"user_ids":  []interface{}{uint64(42), uint64(43)},
+	})
+	talk, ok := event.(*TalkEvent)
+	if eventType != EventTalkCreated || !ok {
+		t.Fatalf("Expected a talk_created event, got %s %T", eventType, event)
+	}
+	if talk.TalkID != "100" || talk.DomainID != "1" || talk.Type != 2 || talk.Name != "開発" || fmt.Sprint(talk.UserIDs) != "[42 43]" {
+		t.Errorf("Unexpected talk event %+v", talk)
+	}
+
+	eventType, event = ParseEvent(direct.EventNotifyAddTalkers, map[string]interface{}{
+		"talk":     map[string]interface{}{"id": uint64(100), "domain_id": uint64(1)},
+		"user_ids": []interface{}{uint64(44)},
+	})
+	if talkers, ok := event.(*TalkersEvent); eventType != EventTalkersAdded || !ok || talkers.TalkID != "100" || fmt.Sprint(talkers.UserIDs) != "[44]" {
+		t.Errorf("Unexpected talkers event %s %+v", eventType, event)
+	}
+	if EventTalkID(event) != "100" {
+		t.Errorf("Expected the event to be about talk 100")
+	}
+
+	eventType, event = ParseEvent(direct.EventNotifyAddFriend, map[string]interface{}{"user_id": uint64(1)})
+	if eventType != "add_friend" || event != nil {
+		t.Errorf("Expected an untyped add_friend event, got %s %+v", eventType, event)
+	}
+
+	payload := NewEventPayload("testbot", direct.EventNotifyDeleteMessage, map[string]interface{}{"message_id": uint64(7)})
+	data, _ := json.Marshal(payload)
+	var decoded struct {
+		Version   string                 `json:"version"`
+		EventType string                 `json:"eventType"`
+		Event     map[string]interface{} `json:"event"`
+		Raw       map[string]interface{} `json:"raw"`
+	}
+	json.Unmarshal(data, &decoded)
+	if decoded.Version != SchemaVersion || decoded.EventType != EventMessageDeleted || decoded.Event["messageId"] != "7" || decoded.Raw != nil {
+		t.Errorf("Unexpected event payload %s", data)
+	}
+}
+
+func TestForwarderEvents(t *testing.T) {
+	got, err := NewForwarder(nil, WithEvents("talkers_added", "notify_add_friend")).Notifications()
+	if err != nil || fmt.Sprint(got) != "[notify_add_friend notify_add_talkers]" {
+		t.Errorf("Notifications() = %v, %v", got, err)
+	}
+	got, _ = NewForwarder(nil, WithEvents("talk_created")).Notifications()
+	if fmt.Sprint(got) != "[notify_create_group_talk notify_create_pair_talk]" {
+		t.Errorf("Expected both talk notifications, got %v", got)
+	}
+	all, _ := NewForwarder(nil).Notifications()
+	if len(all) != 13 {
+		t.Errorf("Expected the default events, got %v", all)
+	}
+	if _, err := NewForwarder(nil, WithEvents("unknown")).Notifications(); err == nil {
+		t.Error("Expected an unknown event type to be rejected")
+	}
+
+	f := NewForwarder(nil)
+	f.Configure(bot.NewPluginConfig("webhook", map[string]interface{}{
+		"url":    "http://localhost/hook",
+		"events": []interface{}{"note_created", "note_deleted"},
+		"enrich": []interface{}{"talk.name", "domain.name"},
+		"raw":    true,
+	}))
+	if !f.enrich || fmt.Sprint(f.fields) != "[talk.name domain.name]" || !f.raw {
+		t.Errorf("Unexpected enrichment %v %v", f.enrich, f.fields)
+	}
+	got, _ = f.Notifications()
+	if f.client == nil || f.client.WebhookURL != "http://localhost/hook" || fmt.Sprint(got) != "[notify_create_note notify_delete_note]" {
+		t.Errorf("Unexpected configuration %v %v", f.client, got)
+	}
+	if err := NewForwarder(nil).Register(bot.New()); err == nil {
+		t.Error("Expected Register to require a URL")
+	}
+}
+
+func TestForwarder(t *testing.T) {
+	payloads := make(chan WebhookPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- payload
+		if payload.EventType == EventTalkersAdded {
+			w.Write([]byte(`{"action": "reply", "text": "ようこそ", "reportResults": true}`))
+		}
+	}))
+	defer server.Close()
+
+	forwarder := NewForwarder(NewClient(server.URL, ""), WithEvents(EventTalkersAdded), WithActions())
+	_, mockServer := runRobot(t, forwarder)
+
+	mockServer.SendNotification(direct.EventNotifyAddFriend, map[string]interface{}{"user_id": uint64(1)})
+	mockServer.SendNotification(direct.EventNotifyAddTalkers, map[string]interface{}{
+		"talk_id":  uint64(100),
+		"user_ids": []interface{}{uint64(44)},
+	})
+
+	select {
+	case payload := <-payloads:
+		if payload.EventType != EventTalkersAdded || payload.Bot.Name != "daabgo" || payload.Event == nil || payload.Raw != nil {
+			t.Errorf("Unexpected payload %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the event to be forwarded")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for mockServer.GetCallCount("create_message") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the reply action to be executed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	var params []interface{}
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if msg[2] == "create_message" {
+			params = msg[3].([]interface{})
+		}
+	}
+	if fmt.Sprint(params[0]) != "100" || params[2] != "ようこそ" {
+		t.Errorf("Expected a reply in talk 100, got %v", params)
+	}
+
+	select {
+	case payload := <-payloads:
+		if payload.EventType != "action_results" || len(payload.Results) != 1 || !payload.Results[0].OK {
+			t.Errorf("Expected the action results to be reported, got %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the action results to be reported")
+	}
+	if len(payloads) != 0 {
+		t.Errorf("Expected unselected events not to be forwarded, got %+v", <-payloads)
+	}
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers carrying the HMAC signature of a request body.
+const (
+	// SignatureHeader holds "sha256=" followed by the hex HMAC-SHA256 of
+	// the timestamp, a ".", and the body.
+	SignatureHeader = "X-Daab-Signature"
+
+	// TimestampHeader holds the Unix time in seconds at which the request
+	// was signed.
+	TimestampHeader = "X-Daab-Timestamp"
+)
+
+// DefaultSignatureTolerance is how far the timestamp of a signed request
+// may be from the current time.
+const DefaultSignatureTolerance = 5 * time.Minute
+
+// Errors returned by Verify.
+var (
+	ErrMissingSignature = errors.New("webhook: missing signature")
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	ErrExpiredSignature = errors.New("webhook: signature timestamp out of tolerance")
+)
+
+// Sign returns the value of SignatureHeader for body signed at timestamp.
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp header values of body.
+// Timestamps further than tolerance from now are rejected to limit replays;
+// a tolerance of 0 means DefaultSignatureTolerance.
+func Verify(secret []byte, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance == 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+	if d := time.Since(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrExpiredSignature
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
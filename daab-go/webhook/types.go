@@ -1,17 +1,44 @@
 // Package webhook provides n8n webhook integration for daab-go.
 package webhook
 
-import "time"
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
 
 // WebhookPayload is the JSON payload sent to n8n webhook.
 type WebhookPayload struct {
-	Version   string       `json:"version"`
-	EventType string       `json:"eventType"`
-	Timestamp string       `json:"timestamp"`
-	Bot       BotInfo      `json:"bot"`
-	Message   *MessageData `json:"message,omitempty"`
-	Event     interface{}  `json:"event,omitempty"`
-	Raw       interface{}  `json:"raw,omitempty"`
+	Version   string         `json:"version"`
+	EventType string         `json:"eventType"`
+	Timestamp string         `json:"timestamp"`
+	Bot       BotInfo        `json:"bot"`
+	Message   *MessageData   `json:"message,omitempty"`
+	User      *UserData      `json:"user,omitempty"` // User of an event; see Enricher
+	Talk      *TalkData      `json:"talk,omitempty"`
+	Domain    *DomainData    `json:"domain,omitempty"`
+	Event     interface{}    `json:"event,omitempty"`
+	Raw       interface{}    `json:"raw,omitempty"`
+	Results   []ActionResult `json:"results,omitempty"`
+}
+
+// IdempotencyKey returns the key sent as IdempotencyKeyHeader. It is
+// derived from the message ID of message payloads and from the content of
+// other payloads, without the timestamp.
+func (p *WebhookPayload) IdempotencyKey() string {
+	if p.Message != nil && p.Message.ID != "" {
+		return p.EventType + ":" + p.Message.ID
+	}
+	cp := *p
+	cp.Timestamp = ""
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return p.EventType + ":" + hex.EncodeToString(sum[:16])
 }
 
 // BotInfo contains bot information.
@@ -27,12 +54,26 @@ type UserData struct {
 	Name        string `json:"name,omitempty"`
 }
 
+// TalkData contains the talk a payload is about; see Enricher.
+type TalkData struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"` // "pair" or "group"
+	MemberCount int    `json:"memberCount,omitempty"`
+}
+
+// DomainData contains the domain (organization) a payload is about; see Enricher.
+type DomainData struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
 // MessageData contains message information.
 type MessageData struct {
 	ID       string      `json:"id"`
 	TalkID   string      `json:"talkId"`
 	UserID   string      `json:"userId"`
-	User     *UserData   `json:"user,omitempty"` // Optional full user info
+	User     *UserData   `json:"user,omitempty"` // Sender profile; see Enricher
 	Type     int         `json:"type"`
 	TypeName string      `json:"typeName"`
 	Text     string      `json:"text"`
@@ -54,6 +95,47 @@ type WebhookResponse struct {
 	Done         *bool    `json:"done,omitempty"`         // For task
 	MessageID    string   `json:"messageId,omitempty"`
 	ErrorCode    string   `json:"errorCode,omitempty"`
+
+	ID            string            `json:"id,omitempty"`            // Echoed in ActionResult
+	Actions       []WebhookResponse `json:"actions,omitempty"`       // Further actions, run in order
+	ReportResults bool              `json:"reportResults,omitempty"` // Post the results back
+}
+
+// UnmarshalJSON decodes a single action object, an object with "actions",
+// or a JSON array of actions, which is stored in Actions.
+func (r *WebhookResponse) UnmarshalJSON(data []byte) error {
+	type plain WebhookResponse
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*r = WebhookResponse{}
+		return json.Unmarshal(trimmed, &r.Actions)
+	}
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// List returns the actions of r in order: r itself if it has an action,
+// followed by r.Actions. Actions nested deeper are ignored.
+func (r *WebhookResponse) List() []WebhookResponse {
+	var list []WebhookResponse
+	if r.Action != "" {
+		head := *r
+		head.Actions = nil
+		list = append(list, head)
+	}
+	for _, action := range r.Actions {
+		action.Actions = nil
+		list = append(list, action)
+	}
+	return list
+}
+
+// ActionResult reports the outcome of one action executed by an Executor.
+type ActionResult struct {
+	ID        string    `json:"id,omitempty"`
+	Action    string    `json:"action"`
+	OK        bool      `json:"ok"`
+	MessageID string    `json:"messageId,omitempty"`
+	ErrorCode ErrorCode `json:"errorCode,omitempty"`
+	Error     string    `json:"error,omitempty"`
 }
 
 // ErrorCode represents structured error codes for "Parse, don't validate" pattern.
@@ -72,12 +154,19 @@ const (
 	ErrorCodeMissingResponse  ErrorCode = "missing_response"
 	ErrorCodeMissingTitle     ErrorCode = "missing_title"
 	ErrorCodeMissingMessageID ErrorCode = "missing_message_id"
+	ErrorCodeMissingStamp     ErrorCode = "missing_stamp"
+	ErrorCodeMissingFile      ErrorCode = "missing_file"
+	ErrorCodeUnauthorized     ErrorCode = "unauthorized"
+	ErrorCodeNotFound         ErrorCode = "not_found"
+	ErrorCodeTooLarge         ErrorCode = "too_large"
+	ErrorCodeNotConnected     ErrorCode = "not_connected"
+	ErrorCodeSendFailed       ErrorCode = "send_failed"
 )
 
 // NewPayload creates a new WebhookPayload for a message event.
 func NewPayload(eventType, botName string, msg MessageData) *WebhookPayload {
 	return &WebhookPayload{
-		Version:   "1.0",
+		Version:   SchemaVersion,
 		EventType: eventType,
 		Timestamp: time.Now().Format(time.RFC3339),
 		Bot: BotInfo{
@@ -87,6 +176,20 @@ func NewPayload(eventType, botName string, msg MessageData) *WebhookPayload {
 	}
 }
 
+// NewResultsPayload creates an "action_results" payload reporting the
+// results of the actions of a response back to the webhook.
+func NewResultsPayload(botName string, results []ActionResult) *WebhookPayload {
+	return &WebhookPayload{
+		Version:   SchemaVersion,
+		EventType: "action_results",
+		Timestamp: time.Now().Format(time.RFC3339),
+		Bot: BotInfo{
+			Name: botName,
+		},
+		Results: results,
+	}
+}
+
 // MessageTypeToName converts message type integer to human-readable name.
 func MessageTypeToName(msgType int) string {
 	switch msgType {
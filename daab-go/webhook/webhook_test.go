@@ -1,10 +1,18 @@
 package webhook
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewPayload(t *testing.T) {
@@ -179,16 +187,156 @@ func TestClientSend(t *testing.T) {
 
 func TestClientSendError(t *testing.T) {
 	// Create server that returns error
+	var attempts atomic.Int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
 		w.WriteHeader(http.StatusInternalServerError)
 	}))
 	defer server.Close()
 
-	client := NewClient(server.URL, "testbot")
+	client := NewClient(server.URL, "testbot", WithRetries(2, time.Millisecond))
 	payload := NewPayload("message_created", "testbot", MessageData{ID: "123"})
 
 	_, err := client.Send(payload)
 	if err == nil {
 		t.Error("Expected error for 500 status, got nil")
 	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected a StatusError, got %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Expected 2 retries, got %d attempts", attempts.Load())
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	var attempts atomic.Int32
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify([]byte("hmac"), r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), body, 0); err != nil {
+			t.Errorf("Expected a signed request, got %v", err)
+		}
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		switch attempts.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			// Close the connection without a response.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		default:
+			w.Write([]byte(`{"action": "reply", "text": "ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "testbot", WithSecret("hmac"), WithRetries(3, time.Millisecond))
+	resp, err := client.Send(NewPayload("message_created", "testbot", MessageData{ID: "123"}))
+	if err != nil || resp.Text != "ok" {
+		t.Fatalf("Expected the request to succeed after retrying, got %+v %v", resp, err)
+	}
+	if attempts.Load() != 3 || keys[0] != "message_created:123" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Errorf("Expected 3 attempts with the same idempotency key, got %d %v", attempts.Load(), keys)
+	}
+
+	// Client errors are not retried.
+	attempts.Store(0)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	if _, err := client.Send(NewPayload("message_created", "testbot", MessageData{ID: "124"})); err == nil || attempts.Load() != 1 {
+		t.Errorf("Expected a single failed attempt, got %d %v", attempts.Load(), err)
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	a := NewEventPayload("testbot", "notify_delete_message", map[string]interface{}{"message_id": uint64(7)})
+	b := NewEventPayload("testbot", "notify_delete_message", map[string]interface{}{"message_id": uint64(7)})
+	c := NewEventPayload("testbot", "notify_delete_message", map[string]interface{}{"message_id": uint64(8)})
+	b.Timestamp = "2000-01-01T00:00:00Z"
+	if a.IdempotencyKey() != b.IdempotencyKey() || a.IdempotencyKey() == c.IdempotencyKey() {
+		t.Errorf("Expected keys to depend on the content only, got %s %s %s", a.IdempotencyKey(), b.IdempotencyKey(), c.IdempotencyKey())
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	up := atomic.Bool{}
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload.Message.ID+" "+r.Header.Get(IdempotencyKeyHeader))
+	}))
+	defer server.Close()
+
+	queue := NewDeadLetterQueue(filepath.Join(t.TempDir(), "dlq"))
+	client := NewClient(server.URL, "testbot", WithRetries(1, time.Millisecond), WithDeadLetterQueue(queue))
+	for _, id := range []string{"1", "2"} {
+		if _, err := client.Send(NewPayload("message_created", "testbot", MessageData{ID: id})); err == nil {
+			t.Fatal("Expected the webhook to be down")
+		}
+	}
+
+	letters, err := queue.List()
+	if err != nil || len(letters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d %v", len(letters), err)
+	}
+	if l := letters[0]; l.URL != server.URL || l.EventType != "message_created" || l.Attempts != 2 || l.IdempotencyKey != "message_created:1" || l.Error == "" {
+		t.Errorf("Unexpected dead letter %+v", l)
+	}
+
+	// A failed replay keeps the letters.
+	if n, err := client.Replay(context.Background(), queue); n != 0 || err == nil {
+		t.Errorf("Expected the replay to fail, got %d %v", n, err)
+	}
+	if letters, _ := queue.List(); len(letters) != 2 || letters[0].Attempts != 4 {
+		t.Errorf("Expected the letters to be kept, got %+v", letters)
+	}
+
+	up.Store(true)
+	if n, err := NewClient("", "testbot").Replay(context.Background(), queue); n != 2 || err != nil {
+		t.Errorf("Expected 2 replayed letters, got %d %v", n, err)
+	}
+	if fmt.Sprint(received) != "[1 message_created:1 2 message_created:2]" {
+		t.Errorf("Expected the letters to be replayed in order, got %v", received)
+	}
+	if letters, _ := queue.List(); len(letters) != 0 {
+		t.Errorf("Expected the queue to be empty, got %+v", letters)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"text":"hi"}`)
+	now := time.Now().Unix()
+	ts := strconv.FormatInt(now, 10)
+	sig := Sign(secret, now, body)
+
+	if err := Verify(secret, sig, ts, body, 0); err != nil {
+		t.Errorf("Expected signature to verify, got %v", err)
+	}
+	if err := Verify(secret, sig, ts, []byte(`{"text":"bye"}`), 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a modified body to be rejected, got %v", err)
+	}
+	if err := Verify([]byte("other"), sig, ts, body, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected another secret to be rejected, got %v", err)
+	}
+	if err := Verify(secret, "", ts, body, 0); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("Expected a missing signature to be rejected, got %v", err)
+	}
+
+	old := now - 600
+	if err := Verify(secret, Sign(secret, old, body), strconv.FormatInt(old, 10), body, 0); !errors.Is(err, ErrExpiredSignature) {
+		t.Errorf("Expected an old timestamp to be rejected, got %v", err)
+	}
+	if err := Verify(secret, Sign(secret, old, body), strconv.FormatInt(old, 10), body, time.Hour); err != nil {
+		t.Errorf("Expected the tolerance to be configurable, got %v", err)
+	}
 }
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/f4ah6o/direct-go-sdk/direct-go/debuglog"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/metrics"
 	"github.com/gorilla/websocket"
 	"github.com/vmihailenco/msgpack/v5"
 )
@@ -25,6 +26,12 @@ func EnableDebugServer(url string) {
 	debuglog.SetServer(url)
 }
 
+// SetDebugLevel sets the debug log level (0 = off, 1 = normal, 2 = verbose),
+// overriding the DIRECT_DEBUG environment variable.
+func SetDebugLevel(level int) {
+	debuglog.SetLevel(level)
+}
+
 // dlog is a helper for debug logging (level 1 = normal)
 func dlog(format string, v ...interface{}) {
 	debuglog.Printf(format, v...)
@@ -62,15 +69,32 @@ type Options struct {
 	// Host is the API host (derived from Endpoint if not set).
 	Host string
 
-	// Name is the bot name (for logging).
+	// Name is the bot name (for logging, and the "bot" label of metrics).
 	Name string
+
+	// Metrics records client metrics, such as RPC calls and latency, in a
+	// registry that may be shared with other clients. Nil disables metrics.
+	Metrics *metrics.Registry
+
+	// Tracer traces RPC calls made with Call and CallContext. Nil disables
+	// tracing.
+	Tracer Tracer
+
+	// CallTimeout is how long Call waits for a response before returning
+	// ErrRPCTimeout. Zero means DefaultCallTimeout.
+	CallTimeout time.Duration
 }
 
+// DefaultCallTimeout is how long Call waits for a response by default.
+const DefaultCallTimeout = 30 * time.Second
+
 // ResponseHandler handles RPC responses.
 type ResponseHandler struct {
 	Method    string
 	OnSuccess func(result interface{})
 	OnError   func(err interface{})
+
+	sent time.Time
 }
 
 // Client is a direct API client.
@@ -83,6 +107,7 @@ type Client struct {
 	msgID            int64
 	closed           bool
 	connected        bool
+	metrics          *clientMetrics
 
 	// talkDomains maps talk_id to domain_id for user lookups
 	talkDomains map[string]string
@@ -113,6 +138,7 @@ func NewClient(opts Options) *Client {
 		handlers:         make(map[string][]EventHandler),
 		responseHandlers: make(map[int64]*ResponseHandler),
 		talkDomains:      make(map[string]string),
+		metrics:          newClientMetrics(opts.Metrics, opts.Name),
 		Messages:         make(chan ReceivedMessage, 100),
 		Done:             make(chan struct{}),
 	}
@@ -154,6 +180,7 @@ func (c *Client) Connect() error {
 
 	c.conn = conn
 	c.closed = false
+	c.metrics.connected()
 
 	// Set up pong handler
 	c.conn.SetPongHandler(func(appData string) error {
@@ -397,16 +424,18 @@ func (c *Client) OnMessage(handler func(ReceivedMessage)) {
 	}()
 }
 
-// call sends an RPC request to the server.
-func (c *Client) call(method string, params []interface{}, onSuccess func(interface{}), onError func(interface{})) {
+// call sends an RPC request and returns its message ID, or 0 if the client
+// is not connected.
+func (c *Client) call(method string, params []interface{}, onSuccess func(interface{}), onError func(interface{})) int64 {
 	c.mu.Lock()
 
 	if c.conn == nil {
 		c.mu.Unlock()
+		c.metrics.failed(method, rpcErrorSend, false)
 		if onError != nil {
 			onError(map[string]string{"message": "not connected"})
 		}
-		return
+		return 0
 	}
 
 	msgID := atomic.AddInt64(&c.msgID, 1)
@@ -416,7 +445,9 @@ func (c *Client) call(method string, params []interface{}, onSuccess func(interf
 		Method:    method,
 		OnSuccess: onSuccess,
 		OnError:   onError,
+		sent:      time.Now(),
 	}
+	c.metrics.called(method)
 
 	c.mu.Unlock()
 
@@ -424,45 +455,85 @@ func (c *Client) call(method string, params []interface{}, onSuccess func(interf
 	request := []interface{}{RpcRequest, msgID, method, params}
 
 	data, err := msgpack.Marshal(request)
-	if err != nil {
-		if onError != nil {
-			onError(map[string]string{"message": err.Error()})
-		}
-		return
+	if err == nil {
+		c.mu.Lock()
+		err = c.conn.WriteMessage(websocket.BinaryMessage, data)
+		c.mu.Unlock()
 	}
 
-	c.mu.Lock()
-	err = c.conn.WriteMessage(websocket.BinaryMessage, data)
-	c.mu.Unlock()
-
 	if err != nil {
+		// No response will come; forget the handler.
+		c.mu.Lock()
+		delete(c.responseHandlers, msgID)
+		c.mu.Unlock()
+		c.metrics.failed(method, rpcErrorSend, true)
 		if onError != nil {
 			onError(map[string]string{"message": err.Error()})
 		}
 	}
+	return msgID
 }
 
 // Call sends a synchronous RPC request to the direct API server.
-// It blocks until a response is received or the 30-second timeout expires.
+// It blocks until a response is received or Options.CallTimeout expires.
 // Method names are defined as constants (e.g., MethodGetTalks, MethodCreateMessage).
 // Returns the result on success, or an error on failure or timeout.
 func (c *Client) Call(method string, params []interface{}) (interface{}, error) {
+	return c.CallContext(context.Background(), method, params)
+}
+
+// CallContext is like Call, but returns ctx.Err() if ctx is done before the
+// response arrives. The call is traced as a child of the span in ctx when
+// Options.Tracer is set.
+func (c *Client) CallContext(ctx context.Context, method string, params []interface{}) (result interface{}, err error) {
+	ctx, span := c.startSpan(ctx, "direct/"+method,
+		Attribute{Key: AttrRPCSystem, Value: "direct"},
+		Attribute{Key: AttrRPCMethod, Value: method})
+	defer func() { span.End(err) }()
+
 	resultCh := make(chan interface{}, 1)
 	errCh := make(chan interface{}, 1)
 
-	c.call(method, params, func(result interface{}) {
+	msgID := c.call(method, params, func(result interface{}) {
 		resultCh <- result
 	}, func(err interface{}) {
 		errCh <- err
 	})
+	if msgID != 0 {
+		span.SetAttributes(Attribute{Key: AttrMsgID, Value: msgID})
+	}
+
+	timeout := c.options.CallTimeout
+	if timeout <= 0 {
+		timeout = DefaultCallTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
 	select {
 	case result := <-resultCh:
 		return result, nil
 	case err := <-errCh:
-		return nil, fmt.Errorf("RPC error: %v", err)
-	case <-time.After(30 * time.Second):
-		return nil, fmt.Errorf("RPC timeout")
+		return nil, newRPCError(method, err)
+	case <-timer.C:
+		c.abandon(msgID, method, rpcErrorTimeout)
+		return nil, ErrRPCTimeout
+	case <-ctx.Done():
+		c.abandon(msgID, method, rpcErrorCanceled)
+		return nil, ctx.Err()
+	}
+}
+
+// abandon forgets the handler of a call that is no longer waited for, so a
+// late response is ignored, and records the failure. Nothing is recorded if
+// the response has already been handled.
+func (c *Client) abandon(msgID int64, method, reason string) {
+	c.mu.Lock()
+	_, pending := c.responseHandlers[msgID]
+	delete(c.responseHandlers, msgID)
+	c.mu.Unlock()
+	if pending {
+		c.metrics.failed(method, reason, true)
 	}
 }
 
@@ -572,6 +643,7 @@ func (c *Client) handleResponse(message []interface{}) {
 
 	errVal := message[2]
 	result := message[3]
+	c.metrics.responded(handler.Method, handler.sent, errVal == nil)
 
 	if errVal != nil {
 		if handler.OnError != nil {
@@ -607,6 +679,7 @@ func (c *Client) handleNotification(message []interface{}) {
 	}
 
 	dlog("[DEBUG] Received notification: %s, params count: %d", method, len(params))
+	c.metrics.notified(method)
 
 	// Emit the notification event
 	c.emit(method, params[0])
@@ -659,6 +732,8 @@ func (c *Client) handleMessageNotification(data interface{}) {
 		case c.Messages <- msg:
 		default:
 			// Channel full, drop message
+			dlog("[DEBUG] Dropping message %s: Messages channel full", msg.ID)
+			c.metrics.droppedMessage()
 		}
 	}
 }
@@ -773,7 +848,7 @@ func (c *Client) emit(event string, data interface{}) {
 // Each Talk contains room metadata including participants, type (pair/group), and settings.
 // This is the preferred method over the legacy GetTalks().
 func (c *Client) GetTalksWithContext(ctx context.Context) ([]Talk, error) {
-	result, err := c.Call(MethodGetTalks, []interface{}{})
+	result, err := c.CallContext(ctx, MethodGetTalks, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -794,7 +869,7 @@ func (c *Client) GetTalksWithContext(ctx context.Context) ([]Talk, error) {
 // GetTalkStatusesWithContext retrieves the status of all talks with context support.
 // Status includes unread count and latest message ID for each talk.
 func (c *Client) GetTalkStatusesWithContext(ctx context.Context) ([]TalkStatus, error) {
-	result, err := c.Call(MethodGetTalkStatuses, []interface{}{})
+	result, err := c.CallContext(ctx, MethodGetTalkStatuses, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -825,7 +900,7 @@ func (c *Client) GetTalkStatusesWithContext(ctx context.Context) ([]TalkStatus,
 // Returns user information including display name, email, status, and other profile details.
 // This is the preferred method over the legacy GetMe().
 func (c *Client) GetMeWithContext(ctx context.Context) (*UserInfo, error) {
-	result, err := c.Call(MethodGetMe, []interface{}{})
+	result, err := c.CallContext(ctx, MethodGetMe, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -842,7 +917,7 @@ func (c *Client) GetMeWithContext(ctx context.Context) (*UserInfo, error) {
 // roomID is the talk/room identifier, and text is the message content.
 // This is the preferred method over the legacy SendText().
 func (c *Client) SendTextWithContext(ctx context.Context, roomID string, text string) error {
-	_, err := c.Call(MethodCreateMessage, []interface{}{roomID, 1, text})
+	_, err := c.CallContext(ctx, MethodCreateMessage, []interface{}{roomID, 1, text})
 	return err
 }
 
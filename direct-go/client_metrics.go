@@ -0,0 +1,103 @@
+package direct
+
+import (
+	"time"
+
+	"github.com/f4ah6o/direct-go-sdk/direct-go/metrics"
+)
+
+// Reasons of failed RPC calls in direct_rpc_errors_total.
+const (
+	rpcErrorServer   = "error"    // the server returned an error
+	rpcErrorSend     = "send"     // the request could not be sent
+	rpcErrorTimeout  = "timeout"  // no response before the timeout
+	rpcErrorCanceled = "canceled" // the context was done before the response
+)
+
+// clientMetrics records the metrics of a client. A nil *clientMetrics
+// records nothing.
+type clientMetrics struct {
+	bot           string
+	calls         *metrics.Counter
+	duration      *metrics.Histogram
+	errors        *metrics.Counter
+	pending       *metrics.Gauge
+	connections   *metrics.Counter
+	reconnects    *metrics.Counter
+	notifications *metrics.Counter
+	dropped       *metrics.Counter
+}
+
+func newClientMetrics(reg *metrics.Registry, bot string) *clientMetrics {
+	if reg == nil {
+		return nil
+	}
+	return &clientMetrics{
+		bot:           bot,
+		calls:         reg.Counter("direct_rpc_calls_total", "RPC calls sent, by method.", "bot", "method"),
+		duration:      reg.Histogram("direct_rpc_duration_seconds", "Time from sending an RPC call to its response, by method.", nil, "bot", "method"),
+		errors:        reg.Counter("direct_rpc_errors_total", "Failed RPC calls, by method and reason (error, send or timeout).", "bot", "method", "reason"),
+		pending:       reg.Gauge("direct_rpc_pending_calls", "RPC calls waiting for their response.", "bot"),
+		connections:   reg.Counter("direct_connections_total", "WebSocket connections established.", "bot"),
+		reconnects:    reg.Counter("direct_reconnects_total", "WebSocket connections established after the first one of the bot.", "bot"),
+		notifications: reg.Counter("direct_notifications_total", "Notifications received from the server, by method.", "bot", "method"),
+		dropped:       reg.Counter("direct_dropped_messages_total", "Received messages dropped because the Messages channel was full.", "bot"),
+	}
+}
+
+func (m *clientMetrics) called(method string) {
+	if m == nil {
+		return
+	}
+	m.calls.Inc(m.bot, method)
+	m.pending.Inc(m.bot)
+}
+
+// responded records the response to a call sent at start. ok is false if
+// the server returned an error.
+func (m *clientMetrics) responded(method string, start time.Time, ok bool) {
+	if m == nil {
+		return
+	}
+	m.pending.Dec(m.bot)
+	m.duration.Observe(time.Since(start).Seconds(), m.bot, method)
+	if !ok {
+		m.errors.Inc(m.bot, method, rpcErrorServer)
+	}
+}
+
+// failed records a call that failed without a response. pending is true if
+// the call was counted as pending.
+func (m *clientMetrics) failed(method, reason string, pending bool) {
+	if m == nil {
+		return
+	}
+	if pending {
+		m.pending.Dec(m.bot)
+	}
+	m.errors.Inc(m.bot, method, reason)
+}
+
+func (m *clientMetrics) connected() {
+	if m == nil {
+		return
+	}
+	if m.connections.Value(m.bot) > 0 {
+		m.reconnects.Inc(m.bot)
+	}
+	m.connections.Inc(m.bot)
+}
+
+func (m *clientMetrics) notified(method string) {
+	if m == nil {
+		return
+	}
+	m.notifications.Inc(m.bot, method)
+}
+
+func (m *clientMetrics) droppedMessage() {
+	if m == nil {
+		return
+	}
+	m.dropped.Inc(m.bot)
+}
@@ -2,9 +2,11 @@ package direct
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/f4ah6o/direct-go-sdk/direct-go/metrics"
 	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
 )
 
@@ -234,3 +236,186 @@ func TestSendTextWithContext(t *testing.T) {
 		t.Error("create_message was not called with expected params")
 	}
 }
+
+func TestClientMetrics(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("get_me", map[string]interface{}{"user_id": uint64(1)})
+	mockServer.OnError("get_talks", "forbidden")
+	release := make(chan struct{})
+	mockServer.On(MethodGetDomains, func(params []interface{}) (interface{}, error) {
+		<-release
+		return []interface{}{}, nil
+	})
+
+	reg := metrics.NewRegistry()
+	connect := func() *Client {
+		client := NewClient(Options{Endpoint: mockServer.URL(), Name: "alice", Metrics: reg})
+		if err := client.Connect(); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		return client
+	}
+	connect().Close()
+	client := connect()
+	defer client.Close()
+
+	if _, err := client.Call(MethodGetMe, []interface{}{}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if _, err := client.Call(MethodGetTalks, []interface{}{}); err == nil {
+		t.Fatal("Expected get_talks to fail")
+	}
+	mockServer.SendNotification("notify_update_talk", map[string]interface{}{"talk_id": "1"})
+
+	calls := reg.Counter("direct_rpc_calls_total", "", "bot", "method")
+	errors := reg.Counter("direct_rpc_errors_total", "", "bot", "method", "reason")
+	duration := reg.Histogram("direct_rpc_duration_seconds", "", nil, "bot", "method")
+	if calls.Value("alice", MethodGetMe) != 1 || duration.Count("alice", MethodGetMe) != 1 {
+		t.Error("Expected get_me to be counted and timed")
+	}
+	if errors.Value("alice", MethodGetTalks, "error") != 1 || errors.Value("alice", MethodGetMe, "error") != 0 {
+		t.Error("Expected only get_talks to be counted as an error")
+	}
+	if pending := reg.Gauge("direct_rpc_pending_calls", "", "bot").Value("alice"); pending != 0 {
+		t.Errorf("Expected no pending calls, got %v", pending)
+	}
+	if reg.Counter("direct_reconnects_total", "", "bot").Value("alice") != 1 {
+		t.Error("Expected the second connection to count as a reconnect")
+	}
+
+	notifications := reg.Counter("direct_notifications_total", "", "bot", "method")
+	deadline := time.Now().Add(time.Second)
+	for notifications.Value("alice", "notify_update_talk") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the notification to be counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A canceled call is counted and its handler forgotten, so the late
+	// response is ignored.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallContext(ctx, MethodGetDomains, []interface{}{}); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+	close(release)
+	if errors.Value("alice", MethodGetDomains, "canceled") != 1 {
+		t.Error("Expected the canceled call to be counted")
+	}
+	if pending := reg.Gauge("direct_rpc_pending_calls", "", "bot").Value("alice"); pending != 0 {
+		t.Errorf("Expected no pending calls after the cancellation, got %v", pending)
+	}
+	client.mu.Lock()
+	handlers := len(client.responseHandlers)
+	client.mu.Unlock()
+	if handlers != 0 {
+		t.Errorf("Expected the response handler to be removed, got %d handlers", handlers)
+	}
+
+	client.Close()
+	if _, err := NewClient(Options{Name: "alice", Metrics: reg}).Call(MethodGetMe, nil); err == nil {
+		t.Fatal("Expected a call without a connection to fail")
+	}
+	if errors.Value("alice", MethodGetMe, "send") != 1 {
+		t.Error("Expected the unsent call to be counted")
+	}
+}
+
+// recordingTracer records the spans it starts.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+	tracer *recordingTracer
+}
+
+type spanKey struct{}
+
+func (tr *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordedSpan{name: name, attrs: map[string]interface{}{}, tracer: tr}
+	span.parent, _ = ctx.Value(spanKey{}).(*recordedSpan)
+	span.SetAttributes(attrs...)
+	tr.mu.Lock()
+	tr.spans = append(tr.spans, span)
+	tr.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+	s.ended = true
+}
+
+func TestClientTracer(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("get_me", map[string]interface{}{"user_id": uint64(1)})
+	mockServer.OnError("get_talks", "forbidden")
+	release := make(chan struct{})
+	mockServer.On("get_domains", func(params []interface{}) (interface{}, error) {
+		<-release
+		return []interface{}{}, nil
+	})
+	defer close(release)
+
+	tracer := &recordingTracer{}
+	client := NewClient(Options{Endpoint: mockServer.URL(), Tracer: tracer})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx, parent := tracer.Start(context.Background(), "handler")
+	if _, err := client.GetMeWithContext(ctx); err != nil {
+		t.Fatalf("GetMeWithContext failed: %v", err)
+	}
+	if _, err := client.Call(MethodGetTalks, []interface{}{}); err == nil {
+		t.Fatal("Expected get_talks to fail")
+	}
+	cancelled, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetDomainsWithContext(cancelled); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the call to stop at the deadline, got %v", err)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(tracer.spans))
+	}
+	getMe, getTalks, getDomains := tracer.spans[1], tracer.spans[2], tracer.spans[3]
+	if getMe.name != "direct/get_me" || getMe.parent != parent || !getMe.ended || getMe.err != nil {
+		t.Errorf("Unexpected get_me span: %+v", getMe)
+	}
+	if getMe.attrs[AttrRPCMethod] != MethodGetMe || getMe.attrs[AttrRPCSystem] != "direct" {
+		t.Errorf("Unexpected get_me attributes: %v", getMe.attrs)
+	}
+	if id, ok := getMe.attrs[AttrMsgID].(int64); !ok || id == 0 {
+		t.Errorf("Expected the message ID attribute, got %v", getMe.attrs[AttrMsgID])
+	}
+	if getTalks.parent != nil || getTalks.err == nil {
+		t.Errorf("Expected a failed root span for get_talks: %+v", getTalks)
+	}
+	if getDomains.parent != parent || getDomains.err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline error on the get_domains span: %+v", getDomains)
+	}
+}
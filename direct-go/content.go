@@ -0,0 +1,239 @@
+package direct
+
+import "fmt"
+
+// Internal converts a wire message type (500-508) to the internal MessageType.
+// Other values are returned unchanged.
+// Incoming action stamp messages carry wire types, so compare against
+// MessageType constants only after calling Internal.
+func (t MessageType) Internal() MessageType {
+	if t >= WireTypeYesNo && t <= WireTypeTaskClosed {
+		return t - WireTypeYesNo + MessageTypeYesNo
+	}
+	return t
+}
+
+// Wire converts an internal action stamp MessageType (13-21) to the wire
+// type (500-508) expected by create_message and schedule_message.
+// Other values are returned unchanged.
+func (t MessageType) Wire() MessageType {
+	if t >= MessageTypeYesNo && t <= MessageTypeTaskClosed {
+		return t - MessageTypeYesNo + WireTypeYesNo
+	}
+	return t
+}
+
+// StampContent is the decoded content of a received stamp message.
+type StampContent struct {
+	StampSet   string
+	StampIndex string
+	Text       string
+}
+
+// FileContent is the decoded content of a received file attachment.
+type FileContent struct {
+	FileID      string
+	Name        string
+	ContentType string
+	ContentSize int64
+	URL         string
+}
+
+// LocationContent is the decoded content of a received location message.
+type LocationContent struct {
+	Place     string
+	Latitude  float64
+	Longitude float64
+}
+
+// SelectReplyContent is the decoded content of an answer to a select action stamp.
+type SelectReplyContent struct {
+	Question  string
+	Options   []string
+	Response  int
+	InReplyTo string
+}
+
+// Answer returns the selected option text, or "" if it is not known.
+func (c SelectReplyContent) Answer() string {
+	if c.Response >= 0 && c.Response < len(c.Options) {
+		return c.Options[c.Response]
+	}
+	return ""
+}
+
+// YesNoReplyContent is the decoded content of an answer to a yes/no action stamp.
+type YesNoReplyContent struct {
+	Question  string
+	Response  bool
+	InReplyTo string
+}
+
+// TaskDoneContent is the decoded content of a task action stamp completion.
+type TaskDoneContent struct {
+	Title     string
+	Done      bool
+	InReplyTo string
+}
+
+// ParseStampContent decodes the content of a stamp message.
+func ParseStampContent(content interface{}) (*StampContent, bool) {
+	m, ok := content.(map[string]interface{})
+	if !ok || m["stamp_set"] == nil {
+		return nil, false
+	}
+	return &StampContent{
+		StampSet:   stringField(m, "stamp_set"),
+		StampIndex: stringField(m, "stamp_index"),
+		Text:       stringField(m, "text"),
+	}, true
+}
+
+// ParseFileContents decodes the files of a file or text-with-files message.
+func ParseFileContents(content interface{}) []FileContent {
+	m, ok := content.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if files, ok := m["files"].([]interface{}); ok {
+		result := make([]FileContent, 0, len(files))
+		for _, f := range files {
+			if fm, ok := f.(map[string]interface{}); ok {
+				result = append(result, parseFileContent(fm))
+			}
+		}
+		return result
+	}
+	if m["file_id"] != nil {
+		return []FileContent{parseFileContent(m)}
+	}
+	return nil
+}
+
+func parseFileContent(m map[string]interface{}) FileContent {
+	file := FileContent{
+		FileID:      stringField(m, "file_id"),
+		Name:        stringField(m, "name"),
+		ContentType: stringField(m, "content_type"),
+		URL:         stringField(m, "url"),
+	}
+	if file.ContentType == "" {
+		file.ContentType = stringField(m, "mime_type")
+	}
+	if v, ok := toInt64(m["content_size"]); ok {
+		file.ContentSize = v
+	}
+	return file
+}
+
+// ParseLocationContent decodes the content of a location message.
+func ParseLocationContent(content interface{}) (*LocationContent, bool) {
+	m, ok := content.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	loc := &LocationContent{
+		Place: stringField(m, "place"),
+	}
+	if loc.Place == "" {
+		loc.Place = stringField(m, "address")
+	}
+	lat, okLat := floatField(m, "lat", "latitude")
+	lng, okLng := floatField(m, "lng", "longitude")
+	if !okLat || !okLng {
+		return nil, false
+	}
+	loc.Latitude, loc.Longitude = lat, lng
+	return loc, true
+}
+
+// ParseSelectReplyContent decodes the content of a select action stamp answer.
+func ParseSelectReplyContent(content interface{}) (*SelectReplyContent, bool) {
+	m, ok := content.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	resp, ok := toInt64(m["response"])
+	if !ok {
+		return nil, false
+	}
+
+	reply := &SelectReplyContent{
+		Question:  stringField(m, "question"),
+		Response:  int(resp),
+		InReplyTo: stringField(m, "in_reply_to"),
+	}
+	if options, ok := m["options"].([]interface{}); ok {
+		for _, o := range options {
+			reply.Options = append(reply.Options, fmt.Sprintf("%v", o))
+		}
+	}
+	return reply, true
+}
+
+// ParseYesNoReplyContent decodes the content of a yes/no action stamp answer.
+func ParseYesNoReplyContent(content interface{}) (*YesNoReplyContent, bool) {
+	m, ok := content.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	resp, ok := m["response"].(bool)
+	if !ok {
+		return nil, false
+	}
+	return &YesNoReplyContent{
+		Question:  stringField(m, "question"),
+		Response:  resp,
+		InReplyTo: stringField(m, "in_reply_to"),
+	}, true
+}
+
+// ParseTaskDoneContent decodes the content of a task action stamp completion.
+func ParseTaskDoneContent(content interface{}) (*TaskDoneContent, bool) {
+	m, ok := content.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	done, ok := m["done"].(bool)
+	if !ok {
+		return nil, false
+	}
+	return &TaskDoneContent{
+		Title:     stringField(m, "title"),
+		Done:      done,
+		InReplyTo: stringField(m, "in_reply_to"),
+	}, true
+}
+
+// stringField returns m[key] formatted as a string, or "" if it is missing.
+func stringField(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// floatField returns the first of keys present in m as a float64.
+func floatField(m map[string]interface{}, keys ...string) (float64, bool) {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case float64:
+			return v, true
+		case float32:
+			return float64(v), true
+		case nil:
+			continue
+		default:
+			if n, ok := toInt64(v); ok {
+				return float64(n), true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package direct
+
+import "testing"
+
+func TestMessageTypeInternal(t *testing.T) {
+	tests := []struct {
+		in   MessageType
+		want MessageType
+	}{
+		{MessageTypeText, MessageTypeText},
+		{MessageType(WireTypeYesNo), MessageTypeYesNo},
+		{MessageType(WireTypeSelectReply), MessageTypeSelectReply},
+		{MessageType(WireTypeTaskDone), MessageTypeTaskDone},
+		{MessageType(WireTypeTaskClosed), MessageTypeTaskClosed},
+		{MessageTypeSelectReply, MessageTypeSelectReply},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Internal(); got != tt.want {
+			t.Errorf("MessageType(%d).Internal() = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMessageTypeWire(t *testing.T) {
+	if got := MessageTypeSelect.Wire(); got != WireTypeSelect {
+		t.Errorf("MessageTypeSelect.Wire() = %d, want %d", got, WireTypeSelect)
+	}
+	if got := MessageTypeText.Wire(); got != MessageTypeText {
+		t.Errorf("MessageTypeText.Wire() = %d, want %d", got, MessageTypeText)
+	}
+	if got := MessageTypeTask.Wire().Internal(); got != MessageTypeTask {
+		t.Errorf("Wire().Internal() = %d, want %d", got, MessageTypeTask)
+	}
+}
+
+func TestParseStampContent(t *testing.T) {
+	stamp, ok := ParseStampContent(map[string]interface{}{
+		"stamp_set":   uint64(3),
+		"stamp_index": uint64(1152921507291204198),
+		"text":        "nice",
+	})
+	if !ok {
+		t.Fatal("Expected stamp to parse")
+	}
+	if stamp.StampSet != "3" || stamp.StampIndex != "1152921507291204198" || stamp.Text != "nice" {
+		t.Errorf("Unexpected stamp: %+v", stamp)
+	}
+
+	if _, ok := ParseStampContent("text"); ok {
+		t.Error("Expected text content not to parse as stamp")
+	}
+}
+
+func TestParseFileContents(t *testing.T) {
+	files := ParseFileContents(map[string]interface{}{
+		"file_id":      uint64(9),
+		"name":         "report.pdf",
+		"content_type": "application/pdf",
+		"content_size": int32(2048),
+		"url":          "https://example.com/report.pdf",
+	})
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	if files[0].FileID != "9" || files[0].Name != "report.pdf" || files[0].ContentSize != 2048 {
+		t.Errorf("Unexpected file: %+v", files[0])
+	}
+
+	files = ParseFileContents(map[string]interface{}{
+		"text": "two files",
+		"files": []interface{}{
+			map[string]interface{}{"file_id": "a", "name": "a.txt"},
+			map[string]interface{}{"file_id": "b", "name": "b.txt"},
+		},
+	})
+	if len(files) != 2 || files[1].Name != "b.txt" {
+		t.Errorf("Unexpected files: %+v", files)
+	}
+}
+
+func TestParseLocationContent(t *testing.T) {
+	loc, ok := ParseLocationContent(map[string]interface{}{
+		"place": "Tokyo Station",
+		"lat":   35.681,
+		"lng":   139.767,
+	})
+	if !ok {
+		t.Fatal("Expected location to parse")
+	}
+	if loc.Place != "Tokyo Station" || loc.Latitude != 35.681 || loc.Longitude != 139.767 {
+		t.Errorf("Unexpected location: %+v", loc)
+	}
+
+	if _, ok := ParseLocationContent(map[string]interface{}{"place": "nowhere"}); ok {
+		t.Error("Expected location without coordinates not to parse")
+	}
+}
+
+func TestParseReplyContents(t *testing.T) {
+	sel, ok := ParseSelectReplyContent(map[string]interface{}{
+		"question":    "Which?",
+		"options":     []interface{}{"A", "B"},
+		"response":    int8(1),
+		"in_reply_to": uint64(77),
+	})
+	if !ok {
+		t.Fatal("Expected select reply to parse")
+	}
+	if sel.Response != 1 || sel.Answer() != "B" || sel.InReplyTo != "77" {
+		t.Errorf("Unexpected select reply: %+v", sel)
+	}
+
+	yn, ok := ParseYesNoReplyContent(map[string]interface{}{
+		"question":    "OK?",
+		"response":    true,
+		"in_reply_to": "78",
+	})
+	if !ok || !yn.Response || yn.InReplyTo != "78" {
+		t.Errorf("Unexpected yes/no reply: %+v", yn)
+	}
+
+	task, ok := ParseTaskDoneContent(map[string]interface{}{
+		"title":       "Deploy",
+		"done":        true,
+		"in_reply_to": "79",
+	})
+	if !ok || !task.Done || task.Title != "Deploy" {
+		t.Errorf("Unexpected task done: %+v", task)
+	}
+
+	if _, ok := ParseYesNoReplyContent(map[string]interface{}{"response": int8(0)}); ok {
+		t.Error("Expected select-style response not to parse as yes/no")
+	}
+}
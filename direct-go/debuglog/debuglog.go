@@ -180,6 +180,14 @@ func SetServer(url string) {
 	enabled = url != ""
 }
 
+// SetLevel sets the log level (0 = off, 1 = normal, 2 = verbose),
+// overriding DIRECT_DEBUG.
+func SetLevel(level int) {
+	mu.Lock()
+	defer mu.Unlock()
+	logLevel = level
+}
+
 // Printf logs a message (level 1 = normal)
 func Printf(format string, v ...interface{}) {
 	logMessage(LevelNormal, "info", format, v...)
@@ -0,0 +1,107 @@
+// Package directotel adapts direct.Tracer to OpenTelemetry, so that the RPC
+// calls of a direct-go client, and the received messages and handlers of a
+// daab-go robot, are exported as OpenTelemetry spans.
+//
+//	tracer := directotel.New(tp)
+//	client := direct.NewClient(direct.Options{Tracer: tracer})
+//	robot := bot.New(bot.WithTracer(tracer))
+//
+// The spans are stored in the context as OpenTelemetry spans, so spans
+// started by the application from a handler's ctx, and RPC calls made with
+// a context from other instrumentation such as otelhttp, join the same
+// trace.
+//
+// directotel is a separate module so that direct-go itself does not depend
+// on OpenTelemetry.
+package directotel
+
+import (
+	"context"
+	"fmt"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans.
+const ScopeName = "github.com/f4ah6o/direct-go-sdk/direct-go/directotel"
+
+// Tracer is a direct.Tracer creating OpenTelemetry spans.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New returns a Tracer creating spans with tp, or with the global tracer
+// provider if tp is nil.
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(ScopeName)}
+}
+
+// Start starts an OpenTelemetry span as a child of the span in ctx. Spans
+// of RPC calls, which have the direct.AttrRPCMethod attribute, are client
+// spans; other spans are internal.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...direct.Attribute) (context.Context, direct.Span) {
+	kind := trace.SpanKindInternal
+	for _, attr := range attrs {
+		if attr.Key == direct.AttrRPCMethod {
+			kind = trace.SpanKindClient
+		}
+	}
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(convert(attrs)...))
+	return ctx, otelSpan{span}
+}
+
+// otelSpan is a direct.Span wrapping an OpenTelemetry span.
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttributes(attrs ...direct.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+// End records err, if not nil, as an exception event with the Error status.
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func convert(attrs []direct.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		kvs = append(kvs, keyValue(attr.Key, attr.Value))
+	}
+	return kvs
+}
+
+func keyValue(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.Stringer(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,116 @@
+package directotel
+
+import (
+	"context"
+	"testing"
+
+	direct "github.com/f4ah6o/direct-go-sdk/direct-go"
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("get_me", map[string]interface{}{"user_id": uint64(1)})
+	mockServer.OnError("get_talks", "forbidden")
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := New(tp)
+
+	client := direct.NewClient(direct.Options{Endpoint: mockServer.URL(), Tracer: tracer})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx, parent := tracer.Start(context.Background(), "daab/handler hear:ping",
+		direct.Attribute{Key: "daab.listener", Value: "hear:ping"})
+	if _, err := client.GetMeWithContext(ctx); err != nil {
+		t.Fatalf("GetMeWithContext failed: %v", err)
+	}
+	if _, err := client.GetTalksWithContext(ctx); err == nil {
+		t.Fatal("Expected get_talks to fail")
+	}
+	parent.End(nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	getMe, getTalks, handler := spans[0], spans[1], spans[2]
+	if handler.Name != "daab/handler hear:ping" || handler.SpanKind != trace.SpanKindInternal {
+		t.Errorf("Unexpected handler span: %s %v", handler.Name, handler.SpanKind)
+	}
+	if !hasAttribute(handler.Attributes, attribute.String("daab.listener", "hear:ping")) {
+		t.Errorf("Unexpected handler attributes: %v", handler.Attributes)
+	}
+
+	for _, span := range []tracetest.SpanStub{getMe, getTalks} {
+		if span.Parent.SpanID() != handler.SpanContext.SpanID() || span.SpanContext.TraceID() != handler.SpanContext.TraceID() {
+			t.Errorf("Expected %s to be a child of the handler span", span.Name)
+		}
+		if span.SpanKind != trace.SpanKindClient {
+			t.Errorf("Expected %s to be a client span, got %v", span.Name, span.SpanKind)
+		}
+	}
+	if getMe.Name != "direct/get_me" || getMe.Status.Code != codes.Unset {
+		t.Errorf("Unexpected get_me span: %s %v", getMe.Name, getMe.Status)
+	}
+	if !hasAttribute(getMe.Attributes, attribute.String(direct.AttrRPCMethod, direct.MethodGetMe)) ||
+		!hasAttribute(getMe.Attributes, attribute.String(direct.AttrRPCSystem, "direct")) {
+		t.Errorf("Unexpected get_me attributes: %v", getMe.Attributes)
+	}
+	if !hasKey(getMe.Attributes, direct.AttrMsgID) {
+		t.Errorf("Expected the message ID attribute: %v", getMe.Attributes)
+	}
+	if getTalks.Status.Code != codes.Error || len(getTalks.Events) != 1 || getTalks.Events[0].Name != "exception" {
+		t.Errorf("Expected get_talks to be recorded as failed: %v %v", getTalks.Status, getTalks.Events)
+	}
+	if !getMe.EndTime.After(getMe.StartTime) {
+		t.Error("Expected the span to cover the call")
+	}
+}
+
+func TestKeyValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  attribute.Value
+	}{
+		{"a", attribute.StringValue("a")},
+		{true, attribute.BoolValue(true)},
+		{1, attribute.IntValue(1)},
+		{int64(2), attribute.Int64Value(2)},
+		{1.5, attribute.Float64Value(1.5)},
+		{[]string{"a", "b"}, attribute.StringSliceValue([]string{"a", "b"})},
+		{uint64(3), attribute.StringValue("3")},
+	}
+	for _, tt := range tests {
+		if got := keyValue("k", tt.value); got.Value != tt.want {
+			t.Errorf("keyValue(%v) = %v, want %v", tt.value, got.Value.Emit(), tt.want.Emit())
+		}
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(attrs []attribute.KeyValue, key string) bool {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return true
+		}
+	}
+	return false
+}
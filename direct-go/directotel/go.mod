@@ -0,0 +1,23 @@
+module github.com/f4ah6o/direct-go-sdk/direct-go/directotel
+
+go 1.22.0
+
+require (
+	github.com/f4ah6o/direct-go-sdk/direct-go v0.0.0-20261018135017-9669f4b13f00
+	go.opentelemetry.io/otel v1.34.0
+	go.opentelemetry.io/otel/sdk v1.34.0
+	go.opentelemetry.io/otel/trace v1.34.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gorilla/websocket v1.5.1 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.34.0 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+)
@@ -0,0 +1,74 @@
+package direct
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRPCTimeout is returned by Call when the server does not answer in time.
+var ErrRPCTimeout = errors.New("RPC timeout")
+
+// RPCError is returned by Call when an RPC request fails.
+type RPCError struct {
+	// Method is the RPC method that failed.
+	Method string
+
+	// Data is the error value reported by the server, or a description of
+	// the local failure when Transport is true.
+	Data interface{}
+
+	// Transport is true if the request failed before reaching the server,
+	// for example because the client was not connected.
+	Transport bool
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error: %v", e.Data)
+}
+
+// Message returns the error message reported for the request, if any.
+func (e *RPCError) Message() string {
+	switch v := e.Data.(type) {
+	case string:
+		return v
+	case map[string]string:
+		return v["message"]
+	case map[string]interface{}:
+		if s, ok := v["message"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// newRPCError wraps an error value passed to a call error callback.
+// call reports failures that never reached the server as map[string]string,
+// while errors decoded from server responses use other types.
+func newRPCError(method string, data interface{}) *RPCError {
+	_, local := data.(map[string]string)
+	return &RPCError{Method: method, Data: data, Transport: local}
+}
+
+// IsRetryable reports whether a failed request may succeed if sent again:
+// timeouts, transport failures and server rate limiting. The server may have
+// handled a request that timed out, so requests that are not idempotent, such
+// as create_message, should only be sent again if IsSafeToResend is true.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRPCTimeout) || IsSafeToResend(err)
+}
+
+// IsSafeToResend reports whether a failed request was certainly not handled
+// by the server, so that it can be sent again without creating a duplicate:
+// it never went out, or the server rejected it for rate limiting.
+func IsSafeToResend(err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.Transport {
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Message())
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many")
+}
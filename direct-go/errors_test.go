@@ -0,0 +1,67 @@
+package direct
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestCallErrors(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnError("create_message", "invalid talk")
+	mockServer.OnError("get_talks", "Rate limit exceeded")
+
+	client := NewClient(Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	_, err := client.Call("create_message", []interface{}{"talk", 1, "hi"})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Expected *RPCError, got %T: %v", err, err)
+	}
+	if rpcErr.Method != "create_message" || rpcErr.Message() != "invalid talk" || rpcErr.Transport {
+		t.Errorf("Unexpected error %+v", rpcErr)
+	}
+	if IsRetryable(err) {
+		t.Error("Expected server error not to be retryable")
+	}
+
+	_, err = client.Call("get_talks", []interface{}{})
+	if !IsRetryable(err) {
+		t.Errorf("Expected rate limit error to be retryable: %v", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	_, err := NewClient(Options{}).Call("create_message", []interface{}{})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || !rpcErr.Transport {
+		t.Fatalf("Expected transport error from an unconnected client, got %v", err)
+	}
+	if !IsRetryable(err) {
+		t.Error("Expected transport error to be retryable")
+	}
+
+	if !IsSafeToResend(err) {
+		t.Error("Expected an unsent request to be safe to resend")
+	}
+
+	if !IsRetryable(fmt.Errorf("send: %w", ErrRPCTimeout)) {
+		t.Error("Expected wrapped timeout to be retryable")
+	}
+	if IsSafeToResend(ErrRPCTimeout) {
+		t.Error("Expected a timed out request not to be safe to resend")
+	}
+	if IsRetryable(errors.New("boom")) {
+		t.Error("Expected unknown error not to be retryable")
+	}
+}
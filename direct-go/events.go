@@ -16,6 +16,9 @@ const (
 	EventNotifyCreateMessage = "notify_create_message"
 	EventNotifyDeleteMessage = "notify_delete_message"
 
+	// Reaction notifications
+	EventNotifyUpdateMessageReaction = "notify_update_message_reaction"
+
 	// Talk/Room notifications
 	EventNotifyCreateGroupTalk = "notify_create_group_talk"
 	EventNotifyCreatePairTalk  = "notify_create_pair_talk"
@@ -11,6 +11,7 @@ type UploadAuth struct {
 	PostURL  string
 	PostForm map[string]string
 	PutURL   string
+	GetURL   string
 }
 
 // Attachment represents a file attachment.
@@ -40,40 +41,19 @@ type FilePreview struct {
 // The useType parameter specifies how the file will be used (e.g., "message", "profile").
 func (c *Client) CreateUploadAuth(ctx context.Context, filename, contentType string, size int64, useType string) (*UploadAuth, error) {
 	params := []interface{}{filename, contentType, size, 0, useType}
-	result, err := c.Call(MethodCreateUploadAuth, params)
+	result, err := c.CallContext(ctx, MethodCreateUploadAuth, params)
 	if err != nil {
 		return nil, err
 	}
 
-	auth := &UploadAuth{}
-	if authData, ok := result.(map[string]interface{}); ok {
-		if v, ok := authData["file_id"]; ok {
-			auth.FileID = v
-		}
-		if v, ok := authData["post_url"].(string); ok {
-			auth.PostURL = v
-		}
-		if v, ok := authData["put_url"].(string); ok {
-			auth.PutURL = v
-		}
-		if v, ok := authData["post_form"].(map[string]interface{}); ok {
-			auth.PostForm = make(map[string]string)
-			for k, val := range v {
-				if str, ok := val.(string); ok {
-					auth.PostForm[k] = str
-				}
-			}
-		}
-	}
-
-	return auth, nil
+	return parseUploadAuth(result), nil
 }
 
 // GetAttachments retrieves file attachments from a talk/conversation.
 // The limit parameter controls how many attachments to return (most recent first).
 func (c *Client) GetAttachments(ctx context.Context, talkID interface{}, limit int) ([]Attachment, error) {
 	params := []interface{}{talkID, limit}
-	result, err := c.Call(MethodGetAttachments, params)
+	result, err := c.CallContext(ctx, MethodGetAttachments, params)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +74,7 @@ func (c *Client) GetAttachments(ctx context.Context, talkID interface{}, limit i
 // DeleteAttachment removes a file attachment from the system.
 func (c *Client) DeleteAttachment(ctx context.Context, attachmentID interface{}) error {
 	params := []interface{}{attachmentID}
-	_, err := c.Call(MethodDeleteAttachment, params)
+	_, err := c.CallContext(ctx, MethodDeleteAttachment, params)
 	return err
 }
 
@@ -102,7 +82,7 @@ func (c *Client) DeleteAttachment(ctx context.Context, attachmentID interface{})
 // Returns matching Attachment objects with file metadata and download URLs.
 func (c *Client) SearchAttachments(ctx context.Context, query string, talkID interface{}) ([]Attachment, error) {
 	params := []interface{}{query, talkID}
-	result, err := c.Call(MethodSearchAttachments, params)
+	result, err := c.CallContext(ctx, MethodSearchAttachments, params)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +104,7 @@ func (c *Client) SearchAttachments(ctx context.Context, query string, talkID int
 // This is useful for displaying image or document previews in the UI.
 func (c *Client) CreateFilePreview(ctx context.Context, fileID interface{}) (*FilePreview, error) {
 	params := []interface{}{fileID}
-	result, err := c.Call(MethodCreateFilePreview, params)
+	result, err := c.CallContext(ctx, MethodCreateFilePreview, params)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +120,7 @@ func (c *Client) CreateFilePreview(ctx context.Context, fileID interface{}) (*Fi
 // Returns FilePreview with the preview URL and status.
 func (c *Client) GetFilePreview(ctx context.Context, fileID interface{}) (*FilePreview, error) {
 	params := []interface{}{fileID}
-	result, err := c.Call(MethodGetFilePreview, params)
+	result, err := c.CallContext(ctx, MethodGetFilePreview, params)
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +168,34 @@ func parseAttachment(data map[string]interface{}) Attachment {
 	return attachment
 }
 
+func parseUploadAuth(result interface{}) *UploadAuth {
+	auth := &UploadAuth{}
+	if authData, ok := result.(map[string]interface{}); ok {
+		if v, ok := authData["file_id"]; ok {
+			auth.FileID = v
+		}
+		if v, ok := authData["post_url"].(string); ok {
+			auth.PostURL = v
+		}
+		if v, ok := authData["put_url"].(string); ok {
+			auth.PutURL = v
+		}
+		if v, ok := authData["get_url"].(string); ok {
+			auth.GetURL = v
+		}
+		if v, ok := authData["post_form"].(map[string]interface{}); ok {
+			auth.PostForm = make(map[string]string)
+			for k, val := range v {
+				if str, ok := val.(string); ok {
+					auth.PostForm[k] = str
+				}
+			}
+		}
+	}
+
+	return auth
+}
+
 func parseFilePreview(data map[string]interface{}) *FilePreview {
 	preview := &FilePreview{}
 
@@ -0,0 +1,109 @@
+package direct
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MentionAll is the user ID used by mentions of everyone in a talk.
+const MentionAll = "ALL"
+
+// Mention is a mention entity embedded in message text.
+//
+// On the wire a mention is written as {@:<userID>,<n>}@<name>, where n is
+// the number of code points in "@<name>".
+type Mention struct {
+	// UserID is the mentioned user's ID, or MentionAll.
+	UserID string
+
+	// Name is the display name shown after the "@".
+	Name string
+
+	// Start and End are the byte offsets of the whole markup in the text.
+	Start int
+	End   int
+}
+
+// IsAll reports whether the mention addresses everyone in the talk.
+func (m Mention) IsAll() bool {
+	return m.UserID == MentionAll
+}
+
+var mentionMarkupPattern = regexp.MustCompile(`\{@:(ALL|\d+),(\d+)\}@`)
+
+// ParseMentions extracts the mention entities from message text.
+// Malformed markup is ignored and treated as plain text.
+func ParseMentions(text string) []Mention {
+	var mentions []Mention
+	last := 0
+	for _, loc := range mentionMarkupPattern.FindAllStringSubmatchIndex(text, -1) {
+		if loc[0] < last {
+			continue
+		}
+		n, err := strconv.Atoi(text[loc[4]:loc[5]])
+		if err != nil || n < 2 {
+			continue
+		}
+
+		// n counts the "@" plus the code points of the name.
+		nameStart := loc[1]
+		nameEnd := nameStart
+		for i := 0; i < n-1; i++ {
+			if nameEnd >= len(text) {
+				nameEnd = -1
+				break
+			}
+			_, size := utf8.DecodeRuneInString(text[nameEnd:])
+			nameEnd += size
+		}
+		if nameEnd < 0 {
+			continue
+		}
+
+		mentions = append(mentions, Mention{
+			UserID: text[loc[2]:loc[3]],
+			Name:   text[nameStart:nameEnd],
+			Start:  loc[0],
+			End:    nameEnd,
+		})
+		last = nameEnd
+	}
+	return mentions
+}
+
+// StripMentions replaces mention markup in text with plain "@<name>",
+// as shown to users by the direct clients.
+func StripMentions(text string) string {
+	mentions := ParseMentions(text)
+	if len(mentions) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range mentions {
+		b.WriteString(text[last:m.Start])
+		b.WriteString("@")
+		b.WriteString(m.Name)
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// MentionMarkup returns the markup that mentions the user with the given ID
+// and display name. Use MentionAll as the user ID to mention everyone.
+func MentionMarkup(userID, name string) string {
+	if userID == MentionAll {
+		return "{@:ALL,4}@ALL"
+	}
+	return fmt.Sprintf("{@:%s,%d}@%s", userID, utf8.RuneCountInString(name)+1, name)
+}
+
+// Mentions returns the mention entities in the message text.
+func (m ReceivedMessage) Mentions() []Mention {
+	return ParseMentions(m.Text)
+}
@@ -0,0 +1,68 @@
+package direct
+
+import "testing"
+
+func TestParseMentions(t *testing.T) {
+	text := "{@:12345,4}@bot ping {@:ALL,4}@ALL and {@:678,4}@山田太"
+
+	mentions := ParseMentions(text)
+	if len(mentions) != 3 {
+		t.Fatalf("Expected 3 mentions, got %d: %+v", len(mentions), mentions)
+	}
+
+	if mentions[0].UserID != "12345" || mentions[0].Name != "bot" {
+		t.Errorf("Unexpected first mention: %+v", mentions[0])
+	}
+	if mentions[0].Start != 0 || text[mentions[0].End:] != " ping {@:ALL,4}@ALL and {@:678,4}@山田太" {
+		t.Errorf("Unexpected first mention bounds: %+v", mentions[0])
+	}
+	if !mentions[1].IsAll() || mentions[1].Name != "ALL" {
+		t.Errorf("Unexpected second mention: %+v", mentions[1])
+	}
+	if mentions[2].UserID != "678" || mentions[2].Name != "山田太" {
+		t.Errorf("Unexpected third mention: %+v", mentions[2])
+	}
+}
+
+func TestParseMentionsMalformed(t *testing.T) {
+	tests := []string{
+		"plain @bot text",
+		"{@:12345,1}@",
+		"{@:12345,10}@short",
+		"{@:abc,4}@bot",
+	}
+	for _, text := range tests {
+		if mentions := ParseMentions(text); len(mentions) != 0 {
+			t.Errorf("ParseMentions(%q) = %+v, want none", text, mentions)
+		}
+	}
+}
+
+func TestStripMentions(t *testing.T) {
+	got := StripMentions("{@:12345,4}@bot hello {@:678,3}@田中さん")
+	want := "@bot hello @田中さん"
+	if got != want {
+		t.Errorf("StripMentions() = %q, want %q", got, want)
+	}
+}
+
+func TestMentionMarkup(t *testing.T) {
+	tests := []struct {
+		userID string
+		name   string
+		want   string
+	}{
+		{"12345", "bot", "{@:12345,4}@bot"},
+		{"678", "山田太郎", "{@:678,5}@山田太郎"},
+		{MentionAll, "", "{@:ALL,4}@ALL"},
+	}
+	for _, tt := range tests {
+		got := MentionMarkup(tt.userID, tt.name)
+		if got != tt.want {
+			t.Errorf("MentionMarkup(%q, %q) = %q, want %q", tt.userID, tt.name, got, tt.want)
+		}
+		if mentions := ParseMentions(got); len(mentions) != 1 || mentions[0].End != len(got) {
+			t.Errorf("MentionMarkup(%q, %q) did not round-trip: %+v", tt.userID, tt.name, mentions)
+		}
+	}
+}
@@ -63,7 +63,7 @@ func (c *Client) GetMessages(ctx context.Context, domainID, talkID interface{},
 	}
 
 	params := []interface{}{domainID, talkID, opts.SinceID, opts.MaxID, int(opts.Order)}
-	result, err := c.Call(MethodGetMessages, params)
+	result, err := c.CallContext(ctx, MethodGetMessages, params)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +91,7 @@ func (c *Client) GetMessages(ctx context.Context, domainID, talkID interface{},
 // Returns error if the deletion fails.
 func (c *Client) DeleteMessage(ctx context.Context, domainID, messageID interface{}) error {
 	params := []interface{}{domainID, messageID}
-	_, err := c.Call(MethodDeleteMessage, params)
+	_, err := c.CallContext(ctx, MethodDeleteMessage, params)
 	return err
 }
 
@@ -107,7 +107,7 @@ func (c *Client) DeleteMessage(ctx context.Context, domainID, messageID interfac
 // Returns search results with pagination information.
 func (c *Client) SearchMessages(ctx context.Context, domainID, talkID interface{}, keyword string, marker interface{}, limit int) (*SearchMessagesResult, error) {
 	params := []interface{}{domainID, talkID, keyword, marker, limit}
-	result, err := c.Call(MethodSearchMessages, params)
+	result, err := c.CallContext(ctx, MethodSearchMessages, params)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +154,7 @@ func (c *Client) SearchMessages(ctx context.Context, domainID, talkID interface{
 
 // GetFavoriteMessages retrieves the user's favorite messages.
 func (c *Client) GetFavoriteMessages(ctx context.Context) ([]ReceivedMessage, error) {
-	result, err := c.Call(MethodGetFavoriteMessages, []interface{}{})
+	result, err := c.CallContext(ctx, MethodGetFavoriteMessages, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -175,14 +175,14 @@ func (c *Client) GetFavoriteMessages(ctx context.Context) ([]ReceivedMessage, er
 // AddFavoriteMessage adds a message to favorites.
 func (c *Client) AddFavoriteMessage(ctx context.Context, messageID interface{}) error {
 	params := []interface{}{messageID}
-	_, err := c.Call(MethodAddFavoriteMessage, params)
+	_, err := c.CallContext(ctx, MethodAddFavoriteMessage, params)
 	return err
 }
 
 // DeleteFavoriteMessage removes a message from favorites.
 func (c *Client) DeleteFavoriteMessage(ctx context.Context, messageID interface{}) error {
 	params := []interface{}{messageID}
-	_, err := c.Call(MethodDeleteFavoriteMessage, params)
+	_, err := c.CallContext(ctx, MethodDeleteFavoriteMessage, params)
 	return err
 }
 
@@ -199,7 +199,7 @@ type ScheduledMessage struct {
 
 // GetScheduledMessages retrieves all scheduled messages.
 func (c *Client) GetScheduledMessages(ctx context.Context) ([]ScheduledMessage, error) {
-	result, err := c.Call(MethodGetScheduledMessages, []interface{}{})
+	result, err := c.CallContext(ctx, MethodGetScheduledMessages, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -208,29 +208,7 @@ func (c *Client) GetScheduledMessages(ctx context.Context) ([]ScheduledMessage,
 	if arr, ok := result.([]interface{}); ok {
 		for _, item := range arr {
 			if msgData, ok := item.(map[string]interface{}); ok {
-				msg := ScheduledMessage{}
-				if v, ok := msgData["id"]; ok {
-					msg.ID = v
-				}
-				if v, ok := msgData["talk_id"]; ok {
-					msg.TalkID = v
-				}
-				if v, ok := msgData["domain_id"]; ok {
-					msg.DomainID = v
-				}
-				if v, ok := msgData["type"].(int); ok {
-					msg.Type = MessageType(v)
-				}
-				if v, ok := msgData["content"]; ok {
-					msg.Content = v
-				}
-				if v, ok := msgData["scheduled_at"].(int64); ok {
-					msg.ScheduledAt = time.Unix(v, 0)
-				}
-				if v, ok := msgData["created_at"].(int64); ok {
-					msg.CreatedAt = time.Unix(v, 0)
-				}
-				messages = append(messages, msg)
+				messages = append(messages, parseScheduledMessage(msgData))
 			}
 		}
 	}
@@ -241,50 +219,138 @@ func (c *Client) GetScheduledMessages(ctx context.Context) ([]ScheduledMessage,
 // ScheduleMessage schedules a message to be sent at a specific time.
 func (c *Client) ScheduleMessage(ctx context.Context, talkID interface{}, msgType MessageType, content interface{}, scheduledAt time.Time) (*ScheduledMessage, error) {
 	params := []interface{}{talkID, int(msgType), content, scheduledAt.Unix()}
-	result, err := c.Call(MethodScheduleMessage, params)
+	result, err := c.CallContext(ctx, MethodScheduleMessage, params)
 	if err != nil {
 		return nil, err
 	}
 
 	msg := &ScheduledMessage{}
 	if msgData, ok := result.(map[string]interface{}); ok {
-		if v, ok := msgData["id"]; ok {
-			msg.ID = v
-		}
-		if v, ok := msgData["talk_id"]; ok {
-			msg.TalkID = v
-		}
-		if v, ok := msgData["domain_id"]; ok {
-			msg.DomainID = v
-		}
-		if v, ok := msgData["type"].(int); ok {
-			msg.Type = MessageType(v)
-		}
-		if v, ok := msgData["content"]; ok {
-			msg.Content = v
-		}
-		if v, ok := msgData["scheduled_at"].(int64); ok {
-			msg.ScheduledAt = time.Unix(v, 0)
-		}
-		if v, ok := msgData["created_at"].(int64); ok {
-			msg.CreatedAt = time.Unix(v, 0)
-		}
+		*msg = parseScheduledMessage(msgData)
 	}
 
 	return msg, nil
 }
 
+// parseScheduledMessage converts a raw scheduled message map.
+// msgpack decodes integers into various Go types, so numbers go through toInt64.
+func parseScheduledMessage(msgData map[string]interface{}) ScheduledMessage {
+	msg := ScheduledMessage{}
+	if v, ok := msgData["id"]; ok {
+		msg.ID = v
+	} else if v, ok := msgData["message_id"]; ok {
+		msg.ID = v
+	}
+	if v, ok := msgData["talk_id"]; ok {
+		msg.TalkID = v
+	}
+	if v, ok := msgData["domain_id"]; ok {
+		msg.DomainID = v
+	}
+	if v, ok := toInt64(msgData["type"]); ok {
+		msg.Type = MessageType(v)
+	}
+	if v, ok := msgData["content"]; ok {
+		msg.Content = v
+	}
+	if v, ok := toInt64(msgData["scheduled_at"]); ok {
+		msg.ScheduledAt = time.Unix(v, 0)
+	}
+	if v, ok := toInt64(msgData["created_at"]); ok {
+		msg.CreatedAt = time.Unix(v, 0)
+	}
+	return msg
+}
+
+// Text returns the text of a scheduled text message, the question of a
+// scheduled action stamp or the title of a scheduled task.
+func (m ScheduledMessage) Text() string {
+	switch v := m.Content.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		for _, key := range []string{"text", "question", "title"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// ScheduleText schedules a text message. The time may be in any location.
+func (c *Client) ScheduleText(ctx context.Context, talkID interface{}, text string, at time.Time) (*ScheduledMessage, error) {
+	return c.ScheduleMessage(ctx, talkID, MessageTypeText, text, at)
+}
+
+// ScheduleStamp schedules a stamp message.
+func (c *Client) ScheduleStamp(ctx context.Context, talkID interface{}, stamp StampMessage, at time.Time) (*ScheduledMessage, error) {
+	content := map[string]interface{}{
+		"stamp_set":   stamp.StampSet,
+		"stamp_index": stamp.StampIndex,
+	}
+	if stamp.Text != "" {
+		content["text"] = stamp.Text
+	}
+	return c.ScheduleMessage(ctx, talkID, MessageTypeStamp, content, at)
+}
+
+// ScheduleYesNo schedules a yes/no action stamp.
+func (c *Client) ScheduleYesNo(ctx context.Context, talkID interface{}, yesno YesNoMessage, at time.Time) (*ScheduledMessage, error) {
+	content := map[string]interface{}{
+		"question": yesno.Question,
+		"listing":  yesno.Listing,
+	}
+	if yesno.CloseYes {
+		content["close_yes"] = true
+	}
+	if yesno.CloseNo {
+		content["close_no"] = true
+	}
+	return c.ScheduleMessage(ctx, talkID, MessageTypeYesNo.Wire(), content, at)
+}
+
+// ScheduleSelect schedules a select action stamp.
+func (c *Client) ScheduleSelect(ctx context.Context, talkID interface{}, sel SelectMessage, at time.Time) (*ScheduledMessage, error) {
+	content := map[string]interface{}{
+		"question": sel.Question,
+		"options":  sel.Options,
+		"listing":  sel.Listing,
+	}
+	if sel.ClosingType != 0 {
+		content["closing_type"] = sel.ClosingType
+	}
+	return c.ScheduleMessage(ctx, talkID, MessageTypeSelect.Wire(), content, at)
+}
+
+// ScheduleTask schedules a task action stamp.
+func (c *Client) ScheduleTask(ctx context.Context, talkID interface{}, task TaskMessage, at time.Time) (*ScheduledMessage, error) {
+	content := map[string]interface{}{
+		"title": task.Title,
+	}
+	if task.ClosingType != 0 {
+		content["closing_type"] = task.ClosingType
+	}
+	if task.ClosingUsers != 0 {
+		content["closing_users"] = task.ClosingUsers
+	}
+	if len(task.TargetUserIDs) > 0 {
+		content["target_user_ids"] = task.TargetUserIDs
+	}
+	return c.ScheduleMessage(ctx, talkID, MessageTypeTask.Wire(), content, at)
+}
+
 // DeleteScheduledMessage deletes a scheduled message.
 func (c *Client) DeleteScheduledMessage(ctx context.Context, messageID interface{}) error {
 	params := []interface{}{messageID}
-	_, err := c.Call(MethodDeleteScheduledMessage, params)
+	_, err := c.CallContext(ctx, MethodDeleteScheduledMessage, params)
 	return err
 }
 
 // RescheduleMessage changes the scheduled time of a message.
 func (c *Client) RescheduleMessage(ctx context.Context, messageID interface{}, newScheduledAt time.Time) error {
 	params := []interface{}{messageID, newScheduledAt.Unix()}
-	_, err := c.Call(MethodRescheduleMessage, params)
+	_, err := c.CallContext(ctx, MethodRescheduleMessage, params)
 	return err
 }
 
@@ -297,7 +363,7 @@ type MessageReaction struct {
 
 // GetAvailableMessageReactions retrieves all available message reactions.
 func (c *Client) GetAvailableMessageReactions(ctx context.Context) ([]MessageReaction, error) {
-	result, err := c.Call(MethodGetAvailableMessageReactions, []interface{}{})
+	result, err := c.CallContext(ctx, MethodGetAvailableMessageReactions, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -327,14 +393,14 @@ func (c *Client) GetAvailableMessageReactions(ctx context.Context) ([]MessageRea
 // SetMessageReaction sets a reaction on a message.
 func (c *Client) SetMessageReaction(ctx context.Context, messageID, reactionID interface{}) error {
 	params := []interface{}{messageID, reactionID}
-	_, err := c.Call(MethodSetMessageReaction, params)
+	_, err := c.CallContext(ctx, MethodSetMessageReaction, params)
 	return err
 }
 
 // ResetMessageReaction removes a reaction from a message.
 func (c *Client) ResetMessageReaction(ctx context.Context, messageID, reactionID interface{}) error {
 	params := []interface{}{messageID, reactionID}
-	_, err := c.Call(MethodResetMessageReaction, params)
+	_, err := c.CallContext(ctx, MethodResetMessageReaction, params)
 	return err
 }
 
@@ -348,7 +414,7 @@ type MessageReactionUser struct {
 // GetMessageReactionUsers retrieves users who reacted to a message.
 func (c *Client) GetMessageReactionUsers(ctx context.Context, messageID interface{}) ([]MessageReactionUser, error) {
 	params := []interface{}{messageID}
-	result, err := c.Call(MethodGetMessageReactionUsers, params)
+	result, err := c.CallContext(ctx, MethodGetMessageReactionUsers, params)
 	if err != nil {
 		return nil, err
 	}
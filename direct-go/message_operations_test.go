@@ -331,11 +331,22 @@ func TestGetScheduledMessages(t *testing.T) {
 		t.Errorf("Expected message ID 'sched1', got %v", messages[0].ID)
 	}
 
-	// Type assertion may fail with msgpack int types, so we just check the ID and content
-	// In real usage, the type would be properly decoded
 	if messages[0].Content != "Scheduled message" {
 		t.Errorf("Expected content 'Scheduled message', got %v", messages[0].Content)
 	}
+
+	if messages[0].Type != MessageTypeText {
+		t.Errorf("Expected type %d, got %d", MessageTypeText, messages[0].Type)
+	}
+	if messages[0].ScheduledAt.Unix() != scheduledTime {
+		t.Errorf("Expected scheduled_at %d, got %d", scheduledTime, messages[0].ScheduledAt.Unix())
+	}
+	if messages[0].CreatedAt.Unix() != createdTime {
+		t.Errorf("Expected created_at %d, got %d", createdTime, messages[0].CreatedAt.Unix())
+	}
+	if messages[0].Text() != "Scheduled message" {
+		t.Errorf("Expected text 'Scheduled message', got %q", messages[0].Text())
+	}
 }
 
 func TestScheduleMessage(t *testing.T) {
@@ -375,6 +386,63 @@ func TestScheduleMessage(t *testing.T) {
 	}
 }
 
+func TestScheduleTypedMessages(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("schedule_message", map[string]interface{}{"id": "sched1"})
+
+	client := NewClient(Options{
+		Endpoint: mockServer.URL(),
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	tokyo := time.FixedZone("JST", 9*60*60)
+	at := time.Date(2030, 1, 2, 9, 0, 0, 0, tokyo)
+	ctx := context.Background()
+
+	if _, err := client.ScheduleText(ctx, "talk123", "hello", at); err != nil {
+		t.Fatalf("ScheduleText failed: %v", err)
+	}
+	if _, err := client.ScheduleStamp(ctx, "talk123", StampMessage{StampSet: "3", StampIndex: "1"}, at); err != nil {
+		t.Fatalf("ScheduleStamp failed: %v", err)
+	}
+	if _, err := client.ScheduleYesNo(ctx, "talk123", YesNoMessage{Question: "ok?"}, at); err != nil {
+		t.Fatalf("ScheduleYesNo failed: %v", err)
+	}
+	if _, err := client.ScheduleSelect(ctx, "talk123", SelectMessage{Question: "which?", Options: []string{"a", "b"}}, at); err != nil {
+		t.Fatalf("ScheduleSelect failed: %v", err)
+	}
+	if _, err := client.ScheduleTask(ctx, "talk123", TaskMessage{Title: "do it"}, at); err != nil {
+		t.Fatalf("ScheduleTask failed: %v", err)
+	}
+
+	wantTypes := []int64{int64(MessageTypeText), int64(MessageTypeStamp), WireTypeYesNo, WireTypeSelect, WireTypeTask}
+	var gotTypes []int64
+	for _, msg := range mockServer.GetReceivedMessages() {
+		if len(msg) < 4 || msg[2] != "schedule_message" {
+			continue
+		}
+		params := msg[3].([]interface{})
+		typ, _ := toInt64(params[1])
+		gotTypes = append(gotTypes, typ)
+		if ts, _ := toInt64(params[3]); ts != at.Unix() {
+			t.Errorf("Expected scheduled time %d, got %v", at.Unix(), params[3])
+		}
+	}
+	if len(gotTypes) != len(wantTypes) {
+		t.Fatalf("Expected %d schedule_message calls, got %d", len(wantTypes), len(gotTypes))
+	}
+	for i := range wantTypes {
+		if gotTypes[i] != wantTypes[i] {
+			t.Errorf("Call %d: expected type %d, got %d", i, wantTypes[i], gotTypes[i])
+		}
+	}
+}
+
 func TestDeleteScheduledMessage(t *testing.T) {
 	mockServer := testutil.NewMockServer()
 	defer mockServer.Close()
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := NewRegistry()
+	calls := reg.Counter("calls_total", "RPC calls.", "method")
+	calls.Inc("get_talks")
+	calls.Add(2, "create_message")
+	if reg.Counter("calls_total", "RPC calls.", "method").Value("create_message") != 2 {
+		t.Error("Expected the same counter to be returned for the same name")
+	}
+
+	reg.Gauge("pending", "Pending calls.").Set(3)
+	reg.Gauge("pending", "Pending calls.").Dec()
+
+	latency := reg.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "method")
+	latency.Observe(0.05, "get_talks")
+	latency.Observe(0.5, "get_talks")
+	latency.Observe(5, "get_talks")
+
+	reg.Counter("escaped_total", "Help with \\ and\nnewline.", "label").Inc("a \"quoted\"\nvalue")
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP calls_total RPC calls.
+# TYPE calls_total counter
+calls_total{method="create_message"} 2
+calls_total{method="get_talks"} 1
+# HELP escaped_total Help with \\ and\nnewline.
+# TYPE escaped_total counter
+escaped_total{label="a \"quoted\"\nvalue"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{method="get_talks",le="0.1"} 1
+latency_seconds_bucket{method="get_talks",le="1"} 2
+latency_seconds_bucket{method="get_talks",le="+Inf"} 3
+latency_seconds_sum{method="get_talks"} 5.55
+latency_seconds_count{method="get_talks"} 3
+# HELP pending Pending calls.
+# TYPE pending gauge
+pending 2
+`
+	if b.String() != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	w := httptest.NewRecorder()
+	reg.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Header().Get("Content-Type") != ContentType || w.Body.String() != want {
+		t.Errorf("Unexpected response %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestRegistryMisuse(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("x_total", "X.", "a")
+	if reg.Counter("x_total", "X.", "a").Value("unused") != 0 {
+		t.Error("Expected missing series to read as 0")
+	}
+
+	expectPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected %s to panic", name)
+			}
+		}()
+		fn()
+	}
+	expectPanic("another kind", func() { reg.Gauge("x_total", "X.", "a") })
+	expectPanic("other labels", func() { reg.Counter("x_total", "X.", "b") })
+	expectPanic("missing label values", func() { reg.Counter("x_total", "X.", "a").Inc() })
+	expectPanic("a negative counter increment", func() { reg.Counter("x_total", "X.", "a").Add(-1, "v") })
+
+	var b strings.Builder
+	reg.WriteText(&b)
+	if strings.Contains(b.String(), "unused") {
+		t.Errorf("Expected reads not to create series, got %q", b.String())
+	}
+}
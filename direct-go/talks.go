@@ -38,7 +38,7 @@ func (c *Client) CreateGroupTalk(ctx context.Context, domainID interface{}, name
 		}
 	}
 
-	result, err := c.Call(MethodCreateGroupTalk, params)
+	result, err := c.CallContext(ctx, MethodCreateGroupTalk, params)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +54,7 @@ func (c *Client) CreateGroupTalk(ctx context.Context, domainID interface{}, name
 // Returns the created Talk with its ID and metadata.
 func (c *Client) CreatePairTalk(ctx context.Context, domainID, userID interface{}) (*Talk, error) {
 	params := []interface{}{domainID, userID}
-	result, err := c.Call(MethodCreatePairTalk, params)
+	result, err := c.CallContext(ctx, MethodCreatePairTalk, params)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +71,7 @@ func (c *Client) CreatePairTalk(ctx context.Context, domainID, userID interface{
 // Returns the updated Talk.
 func (c *Client) UpdateGroupTalk(ctx context.Context, talkID interface{}, updates map[string]interface{}) (*Talk, error) {
 	params := []interface{}{talkID, updates}
-	result, err := c.Call(MethodUpdateGroupTalk, params)
+	result, err := c.CallContext(ctx, MethodUpdateGroupTalk, params)
 	if err != nil {
 		return nil, err
 	}
@@ -87,28 +87,28 @@ func (c *Client) UpdateGroupTalk(ctx context.Context, talkID interface{}, update
 // This is typically used for group conversations.
 func (c *Client) AddTalkers(ctx context.Context, talkID interface{}, userIDs []interface{}) error {
 	params := []interface{}{talkID, userIDs}
-	_, err := c.Call(MethodAddTalkers, params)
+	_, err := c.CallContext(ctx, MethodAddTalkers, params)
 	return err
 }
 
 // DeleteTalker removes a user from a talk/room, ending their participation.
 func (c *Client) DeleteTalker(ctx context.Context, talkID, userID interface{}) error {
 	params := []interface{}{talkID, userID}
-	_, err := c.Call(MethodDeleteTalker, params)
+	_, err := c.CallContext(ctx, MethodDeleteTalker, params)
 	return err
 }
 
 // AddFavoriteTalk adds a talk to the current user's favorites list for quick access.
 func (c *Client) AddFavoriteTalk(ctx context.Context, talkID interface{}) error {
 	params := []interface{}{talkID}
-	_, err := c.Call(MethodAddFavoriteTalk, params)
+	_, err := c.CallContext(ctx, MethodAddFavoriteTalk, params)
 	return err
 }
 
 // DeleteFavoriteTalk removes a talk from the current user's favorites list.
 func (c *Client) DeleteFavoriteTalk(ctx context.Context, talkID interface{}) error {
 	params := []interface{}{talkID}
-	_, err := c.Call(MethodDeleteFavoriteTalk, params)
+	_, err := c.CallContext(ctx, MethodDeleteFavoriteTalk, params)
 	return err
 }
 
@@ -119,15 +119,21 @@ func parseTalk(data map[string]interface{}) *Talk {
 	if v, ok := data["id"]; ok {
 		talk.ID = v
 	}
+	if v, ok := data["talk_id"]; ok {
+		talk.ID = v
+	}
 	if v, ok := data["domain_id"]; ok {
 		talk.DomainID = v
 	}
-	if v, ok := data["type"].(int); ok {
-		talk.Type = v
+	if v, ok := toInt64(data["type"]); ok {
+		talk.Type = int(v)
 	}
 	if v, ok := data["name"].(string); ok {
 		talk.Name = v
 	}
+	if v, ok := data["talk_name"].(string); ok {
+		talk.Name = v
+	}
 	if v, ok := data["user_ids"].([]interface{}); ok {
 		talk.UserIDs = v
 	}
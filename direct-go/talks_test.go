@@ -0,0 +1,53 @@
+package direct
+
+import (
+	"context"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestGetTalksWithContext(t *testing.T) {
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+
+	mockServer.OnSimple("get_talks", []interface{}{
+		map[string]interface{}{
+			"talk_id":   uint64(100),
+			"domain_id": uint64(1),
+			"type":      int8(1),
+			"user_ids":  []interface{}{uint64(10), uint64(11)},
+		},
+		map[string]interface{}{
+			"talk_id":   uint64(200),
+			"domain_id": uint64(1),
+			"type":      int8(2),
+			"talk_name": "General",
+			"user_ids":  []interface{}{uint64(10), uint64(11), uint64(12)},
+		},
+	})
+
+	client := NewClient(Options{Endpoint: mockServer.URL()})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	talks, err := client.GetTalksWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("GetTalksWithContext failed: %v", err)
+	}
+	if len(talks) != 2 {
+		t.Fatalf("Expected 2 talks, got %d", len(talks))
+	}
+
+	if talks[0].ID != uint64(100) || talks[0].Type != int(RoomTypePair) {
+		t.Errorf("Unexpected pair talk: %+v", talks[0])
+	}
+	if talks[1].Type != int(RoomTypeGroup) || talks[1].Name != "General" {
+		t.Errorf("Unexpected group talk: %+v", talks[1])
+	}
+	if len(talks[1].UserIDs) != 3 {
+		t.Errorf("Expected 3 users in group talk, got %d", len(talks[1].UserIDs))
+	}
+}
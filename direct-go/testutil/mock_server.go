@@ -28,6 +28,7 @@ type MockServer struct {
 	mu         sync.RWMutex
 	conn       *websocket.Conn
 	connMu     sync.Mutex
+	writeMu    sync.Mutex      // Serializes writes to conn
 	messages   [][]interface{} // Stores received RPC requests for assertions
 	messagesMu sync.Mutex
 }
@@ -57,6 +58,8 @@ func (ms *MockServer) URL() string {
 // Close stops the mock server.
 func (ms *MockServer) Close() {
 	ms.server.Close()
+	ms.connMu.Lock()
+	defer ms.connMu.Unlock()
 	if ms.conn != nil {
 		ms.conn.Close()
 	}
@@ -189,7 +192,9 @@ func (ms *MockServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		ms.writeMu.Lock()
 		conn.WriteMessage(websocket.BinaryMessage, responseData)
+		ms.writeMu.Unlock()
 	}
 }
 
@@ -211,6 +216,8 @@ func (ms *MockServer) SendNotification(method string, params interface{}) error
 		return err
 	}
 
+	ms.writeMu.Lock()
+	defer ms.writeMu.Unlock()
 	return conn.WriteMessage(websocket.BinaryMessage, data)
 }
 
@@ -0,0 +1,55 @@
+package direct
+
+import "context"
+
+// Attribute keys set on the spans of RPC calls.
+const (
+	AttrRPCSystem = "rpc.system"    // always "direct"
+	AttrRPCMethod = "rpc.method"    // the RPC method, e.g. "create_message"
+	AttrMsgID     = "direct.msg_id" // the MessagePack RPC message ID
+)
+
+// Tracer traces operations such as RPC calls as spans. Each Call and
+// CallContext is run in a span named "direct/<method>"; daab-go also traces
+// received messages and handlers with the same Tracer, so that the RPCs of a
+// handler are children of its span.
+//
+// Tracer is a small hook interface with no dependencies; the directotel
+// module adapts it to OpenTelemetry.
+type Tracer interface {
+	// Start starts a span named name as a child of the span carried by ctx,
+	// if any, and returns a context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation started by a Tracer. Its duration is the time
+// between Start and End.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+
+	// End ends the span. A non-nil err marks the operation as failed.
+	End(err error)
+}
+
+// Attribute is a key-value pair describing a span. Value is a string,
+// bool, int, int64, float64 or any other value, which tracers may format
+// as a string.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// startSpan starts a span with c's tracer. Without a tracer it returns ctx
+// and a span that does nothing.
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if c.options.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.options.Tracer.Start(ctx, name, attrs...)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) End(error)                  {}
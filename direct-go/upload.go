@@ -0,0 +1,137 @@
+package direct
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// uploadUseTypeMessage is the create_upload_auth use type for message attachments.
+const uploadUseTypeMessage = 0
+
+// UploadedFile is a file uploaded with UploadFile, ready to be sent in a message.
+type UploadedFile struct {
+	FileID      interface{}
+	Name        string
+	ContentType string
+	ContentSize int64
+	URL         string
+}
+
+// Content returns the create_message content for a file message.
+func (f *UploadedFile) Content() map[string]interface{} {
+	return map[string]interface{}{
+		"file_id":      f.FileID,
+		"name":         f.Name,
+		"content_type": f.ContentType,
+		"content_size": f.ContentSize,
+		"url":          f.URL,
+	}
+}
+
+// UploadFile uploads data as a message attachment in domainID.
+// The returned file can be sent with SendFile or as MsgTypeFile content.
+func (c *Client) UploadFile(ctx context.Context, domainID interface{}, name, contentType string, data []byte) (*UploadedFile, error) {
+	size := int64(len(data))
+	result, err := c.CallContext(ctx, MethodCreateUploadAuth, []interface{}{name, contentType, size, domainID, uploadUseTypeMessage})
+	if err != nil {
+		return nil, err
+	}
+	auth := parseUploadAuth(result)
+
+	req, err := newUploadRequest(ctx, auth, name, contentType, data)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("upload failed: %d: %s", resp.StatusCode, body)
+	}
+
+	return &UploadedFile{
+		FileID:      auth.FileID,
+		Name:        name,
+		ContentType: contentType,
+		ContentSize: size,
+		URL:         auth.GetURL,
+	}, nil
+}
+
+// SendFile uploads data and posts it to talkID as a file message.
+// domainID must be the domain the talk belongs to.
+func (c *Client) SendFile(ctx context.Context, talkID, domainID interface{}, name, contentType string, data []byte) error {
+	file, err := c.UploadFile(ctx, domainID, name, contentType, data)
+	if err != nil {
+		return err
+	}
+	_, err = c.CallContext(ctx, MethodCreateMessage, []interface{}{talkID, MsgTypeFile, file.Content()})
+	return err
+}
+
+// newUploadRequest builds the HTTP request described by auth: a PUT with the
+// headers from the upload form, or a multipart POST of the form and the file.
+func newUploadRequest(ctx context.Context, auth *UploadAuth, name, contentType string, data []byte) (*http.Request, error) {
+	switch {
+	case auth.PutURL != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, auth.PutURL, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		for _, key := range []string{"Content-Type", "Content-Disposition"} {
+			if v, ok := auth.PostForm[key]; ok {
+				req.Header.Set(key, v)
+			}
+		}
+		req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+		return req, nil
+
+	case auth.PostURL != "":
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for k, v := range auth.PostForm {
+			if err := w.WriteField(k, v); err != nil {
+				return nil, err
+			}
+		}
+		part, err := w.CreateFormFile("file", name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, auth.PostURL, &body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req, nil
+	}
+	return nil, fmt.Errorf("upload auth has no upload URL")
+}
+
+// httpClient returns an HTTP client using the configured proxy, if any.
+func (c *Client) httpClient() *http.Client {
+	if c.options.ProxyURL == "" {
+		return http.DefaultClient
+	}
+	proxyURL, err := url.Parse(c.options.ProxyURL)
+	if err != nil {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+}
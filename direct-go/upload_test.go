@@ -0,0 +1,104 @@
+package direct
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/f4ah6o/direct-go-sdk/direct-go/testutil"
+)
+
+func TestSendFile(t *testing.T) {
+	var uploaded []byte
+	var header http.Header
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT upload, got %s", r.Method)
+		}
+		header = r.Header
+		uploaded, _ = io.ReadAll(r.Body)
+	}))
+	defer storage.Close()
+
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("create_upload_auth", map[string]interface{}{
+		"file_id": uint64(77),
+		"put_url": storage.URL + "/upload",
+		"get_url": storage.URL + "/file",
+		"post_form": map[string]interface{}{
+			"Content-Type":        "text/plain",
+			"Content-Disposition": `attachment; filename="out.txt"`,
+		},
+	})
+	mockServer.OnSimple("create_message", map[string]interface{}{"message_id": uint64(1)})
+
+	client := NewClient(Options{Endpoint: mockServer.URL()})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendFile(context.Background(), uint64(100), uint64(1), "out.txt", "text/plain", []byte("hello")); err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+	if string(uploaded) != "hello" {
+		t.Errorf("Expected file body to be uploaded, got %q", uploaded)
+	}
+	if header.Get("Content-Disposition") != `attachment; filename="out.txt"` {
+		t.Errorf("Expected Content-Disposition from the upload form, got %q", header.Get("Content-Disposition"))
+	}
+
+	var auth, send []interface{}
+	for _, msg := range mockServer.GetReceivedMessages() {
+		switch msg[2] {
+		case "create_upload_auth":
+			auth = msg[3].([]interface{})
+		case "create_message":
+			send = msg[3].([]interface{})
+		}
+	}
+	if len(auth) != 5 || auth[0] != "out.txt" || fmt.Sprint(auth[2], auth[3]) != "5 1" {
+		t.Errorf("Unexpected create_upload_auth params %v", auth)
+	}
+	if len(send) != 3 {
+		t.Fatalf("Unexpected create_message params %v", send)
+	}
+	content := send[2].(map[string]interface{})
+	if content["url"] != storage.URL+"/file" || content["name"] != "out.txt" {
+		t.Errorf("Unexpected file content %v", content)
+	}
+}
+
+func TestUploadFileFailure(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer storage.Close()
+
+	mockServer := testutil.NewMockServer()
+	defer mockServer.Close()
+	mockServer.OnSimple("create_upload_auth", map[string]interface{}{
+		"file_id":  uint64(77),
+		"post_url": storage.URL,
+		"post_form": map[string]interface{}{
+			"key": "abc",
+		},
+	})
+
+	client := NewClient(Options{Endpoint: mockServer.URL()})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.UploadFile(context.Background(), uint64(1), "out.txt", "text/plain", []byte("x")); err == nil {
+		t.Error("Expected upload to fail")
+	}
+	if n := mockServer.GetCallCount("create_message"); n != 0 {
+		t.Errorf("Expected no message after a failed upload, got %d", n)
+	}
+}
@@ -205,3 +205,17 @@ func TestAddAndDeleteFriend(t *testing.T) {
 		t.Errorf("Expected delete_friend to be called once, got %d", mockServer.GetCallCount("delete_friend"))
 	}
 }
+
+func TestParseUserInfoUserID(t *testing.T) {
+	user := parseUserInfo(map[string]interface{}{
+		"user_id":      uint64(42),
+		"display_name": "Alice",
+	})
+
+	if user.ID != uint64(42) {
+		t.Errorf("Expected ID=42 from user_id, got %v", user.ID)
+	}
+	if user.DisplayName != "Alice" {
+		t.Errorf("Expected DisplayName=Alice, got %s", user.DisplayName)
+	}
+}